@@ -1,21 +1,27 @@
 package auditing
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/protocol"
 )
 
 // Record is the struct for storing the auditing records
 type Record struct {
-	Payload string `json:"payload"`
-	Type    int    `json:"type"`
-	OrgID   string `json:"org_id"`
+	// Version is the protocol version, absent in version 1 records
+	Version   int    `json:"version,omitempty"`
+	Payload   string `json:"payload"`
+	Type      int    `json:"type"`
+	OrgID     string `json:"org_id"`
+	Signature string `json:"sig,omitempty"`
 }
 
 // NewRecord creates a new record
 func NewRecord(payload []byte, recordType int, orgID string) *Record {
 	return &Record{
+		Version: protocol.CurrentVersion,
 		Payload: hex.EncodeToString(payload),
 		Type:    recordType,
 		OrgID:   orgID,
@@ -24,13 +30,16 @@ func NewRecord(payload []byte, recordType int, orgID string) *Record {
 
 // KeyVal returns the key and value for the record to be stored on-chain
 func (r *Record) KeyVal() (string, []byte, error) {
+	return r.KeyValWith(hashsuite.Default())
+}
+
+// KeyValWith returns the key-value pair as KeyVal, hashing the key with the suite
+func (r *Record) KeyValWith(suite *hashsuite.Suite) (string, []byte, error) {
 	jsonObj, err := json.Marshal(r)
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Hash := sha256.New()
-	sha256Hash.Write(jsonObj)
-	return hex.EncodeToString(sha256Hash.Sum(nil)), jsonObj, nil
+	return hex.EncodeToString(suite.Sum(hashsuite.Key, jsonObj)), jsonObj, nil
 }
 
 // Reveal reveals the data in the payload in bytes
@@ -0,0 +1,80 @@
+package auditing
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/schnorr"
+)
+
+// signatureDomain separates auditing record signatures from other messages signed with the same key
+const signatureDomain = "auti-core/auditing/signature"
+
+var (
+	// ErrUnsigned is returned when verifying a record that carries no signature
+	ErrUnsigned = errors.New("auditing: unsigned record")
+	// ErrInvalidSignature is returned when the signature does not verify against the public key
+	ErrInvalidSignature = errors.New("auditing: invalid signature")
+)
+
+// KeyResolver looks up the public key of an organization or auditor by its ID, such as an identity.Registry
+type KeyResolver interface {
+	PublicKey(orgID string) (*ed25519.Point, error)
+}
+
+// Sign signs the record with the private key of the organization or auditor
+func (r *Record) Sign(privateKey *ed25519.Scalar) error {
+	return r.SignWith(func(message []byte) ([]byte, error) {
+		return schnorr.Sign(privateKey, message)
+	})
+}
+
+// SignWith signs the record with an external signer of the message covered by the signature,
+// such as the threshold signing of the auditor committee
+func (r *Record) SignWith(sign func(message []byte) ([]byte, error)) error {
+	signature, err := sign(r.signedBytes())
+	if err != nil {
+		return err
+	}
+	r.Signature = hex.EncodeToString(signature)
+	return nil
+}
+
+// Verify verifies the signature of the record against the public key of the organization or auditor
+func (r *Record) Verify(publicKey *ed25519.Point) error {
+	if r.Signature == "" {
+		return ErrUnsigned
+	}
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if err = schnorr.Verify(publicKey, r.signedBytes(), signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyWith verifies the signature of the record against the public key of its organization looked up in keys
+func (r *Record) VerifyWith(keys KeyResolver) error {
+	publicKey, err := keys.PublicKey(r.OrgID)
+	if err != nil {
+		return err
+	}
+	return r.Verify(publicKey)
+}
+
+// signedBytes returns the message covered by the signature, all fields except the signature itself,
+// and the version but in version 1 records
+func (r *Record) signedBytes() []byte {
+	var buf []byte
+	buf = append(buf, protocol.SignatureDomain(signatureDomain, r.Version)...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(r.Payload)))
+	buf = append(buf, r.Payload...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.Type))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(r.OrgID)))
+	return append(buf, r.OrgID...)
+}
@@ -0,0 +1,36 @@
+package auditing
+
+import (
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// DecodeRecord decodes an auditing record of any supported protocol version. The payload is opaque to the
+// protocol, so version 3 decodes as version 2 does.
+func DecodeRecord(data []byte) (*Record, error) {
+	r := new(Record)
+	if _, err := protocol.Decode(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ProtocolVersion returns the protocol version of the record
+func (r *Record) ProtocolVersion() (int, error) {
+	return protocol.Resolve(r.Version)
+}
+
+// Upgrade returns a copy of the record at the current protocol version, as no version changed the payload.
+// When the version changes the signature of the auditor no longer verifies, so it is dropped and the record
+// must be signed again.
+func (r *Record) Upgrade() (*Record, error) {
+	field, changed, err := protocol.Upgrade(r.Version, false)
+	if err != nil {
+		return nil, err
+	}
+	upgraded := *r
+	if changed {
+		upgraded.Version = field
+		upgraded.Signature = ""
+	}
+	return &upgraded, nil
+}
@@ -0,0 +1,67 @@
+package auditing
+
+import (
+	"errors"
+	"io"
+
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/wire"
+)
+
+// Field numbers of the binary encoding of auditing records
+const (
+	fieldVersion = iota + 1
+	fieldPayload
+	fieldType
+	fieldOrgID
+	fieldSignature
+)
+
+// MarshalBinary encodes the record in the binary wire format, with the hex fields as bytes
+func (r *Record) MarshalBinary() ([]byte, error) {
+	buf := wire.AppendInt(nil, fieldVersion, int64(r.Version))
+	buf, err := wire.AppendHex(buf, fieldPayload, r.Payload)
+	if err != nil {
+		return nil, err
+	}
+	buf = wire.AppendInt(buf, fieldType, int64(r.Type))
+	buf = wire.AppendString(buf, fieldOrgID, r.OrgID)
+	return wire.AppendHex(buf, fieldSignature, r.Signature)
+}
+
+// UnmarshalBinary decodes a record of MarshalBinary of a supported protocol version
+func (r *Record) UnmarshalBinary(data []byte) error {
+	*r = Record{}
+	d := wire.NewDecoder(data)
+	for {
+		f, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var v int64
+		switch f.Number {
+		case fieldVersion:
+			v, err = f.Int()
+			r.Version = int(v)
+		case fieldPayload:
+			r.Payload, err = f.Hex()
+		case fieldType:
+			v, err = f.Int()
+			r.Type = int(v)
+		case fieldOrgID:
+			r.OrgID, err = f.Text()
+		case fieldSignature:
+			r.Signature, err = f.Hex()
+		default:
+			err = f.Unknown()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := protocol.Resolve(r.Version)
+	return err
+}
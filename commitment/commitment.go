@@ -1,53 +1,147 @@
 package commitment
 
 import (
-	"crypto/sha256"
 	"encoding/binary"
+	"sync"
+	"sync/atomic"
 
 	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/protocol"
 )
 
-// Commit generates a commitment from amount, timestamp, counter and the public key (ED25519 point)
-// Commitment = r * G * amount_scalar * Hash(timestamp || counter)
+// Params are the protocol parameters of commitments, the generators, the hash suite of the blindings and
+// the protocol version of the amount encoding. A nil suite is the default suite, and version 0 is the current
+// version. The parameters must not be modified or copied once they have been used for a commitment, since they
+// cache the tables of their generators.
+type Params struct {
+	G       *ed25519.Point
+	H       *ed25519.Point
+	Suite   *hashsuite.Suite
+	Version int
+
+	commitments uint32
+	once        sync.Once
+	committer   *Committer
+}
+
+// Committer returns the committer of the parameters, precomputing its tables on the first call
+func (p *Params) Committer() *Committer {
+	p.once.Do(func() {
+		p.committer = NewCommitter(p)
+	})
+	return p.committer
+}
+
+// ProtocolVersion returns the protocol version of the commitments of the parameters
+func (p *Params) ProtocolVersion() int {
+	if p.Version == 0 {
+		return protocol.CurrentVersion
+	}
+	return p.Version
+}
+
+// NewParams creates new parameters of the generators with the default hash suite
+func NewParams(g, h *ed25519.Point) *Params {
+	return &Params{G: g, H: h, Suite: hashsuite.Default()}
+}
+
+// Commit generates a commitment of the current protocol version from amount, timestamp, counter and
+// the public key (ED25519 point)
+// Commitment = amount_scalar * G + Hash(timestamp || counter) * H
+// It commits without precomputation; CommitWith commits faster with parameters used for many commitments.
 func Commit(amount, timestamp int64, counter uint64, g, h *ed25519.Point, negateHash bool) ([]byte, error) {
-	var isAmountNegative bool
-	if amount < 0 {
-		isAmountNegative = true
-		amount = -amount
+	return CommitWith(&Params{G: g, H: h}, amount, timestamp, counter, negateHash)
+}
+
+// CommitWith generates a commitment as Commit, with the generators and the hash suite of the parameters,
+// in constant time. From their second commitment on, the parameters commit with their Committer, whose tables
+// are precomputed once, so that parameters created for a single commitment are not slowed down.
+func CommitWith(params *Params, amount, timestamp int64, counter uint64, negateHash bool) ([]byte, error) {
+	if atomic.LoadUint32(&params.commitments) > 0 || atomic.AddUint32(&params.commitments, 1) > 1 {
+		return params.Committer().Commit(amount, timestamp, counter, negateHash)
+	}
+	amountScalar, err := AmountScalarWith(params.ProtocolVersion(), amount)
+	if err != nil {
+		return nil, err
+	}
+	hashScalar, err := BlindingScalarWith(params.Suite, timestamp, counter)
+	if err != nil {
+		return nil, err
+	}
+	return commitScalars(params.G, params.H, amountScalar, negateIf(hashScalar, negateHash)), nil
+}
+
+// commitScalars returns amount * G + blinding * H, in constant time
+func commitScalars(g, h *ed25519.Point, amount, blinding *ed25519.Scalar) []byte {
+	trace(constantTime)
+	commitment := new(ed25519.Point).ScalarMult(amount, g)
+	return commitment.Add(commitment, new(ed25519.Point).ScalarMult(blinding, h)).Bytes()
+}
+
+// AmountScalar returns the scalar of an amount in a commitment of the current protocol version,
+// the amount modulo the group order. It runs in constant time, without branching on the sign of the amount.
+func AmountScalar(amount int64) (*ed25519.Scalar, error) {
+	return AmountScalarWith(protocol.CurrentVersion, amount)
+}
+
+// AmountScalarWith returns the scalar of an amount in a commitment of the protocol version, in constant time.
+// Before version 3, the scalar is the magnitude of the amount encoded big-endian, negated for a negative amount.
+func AmountScalarWith(version int, amount int64) (*ed25519.Scalar, error) {
+	if _, err := protocol.Resolve(protocol.Field(version)); err != nil {
+		return nil, err
+	}
+	if !protocol.HomomorphicAmounts(version) {
+		return legacyAmountScalar(amount)
 	}
+	// a negative amount is 2^64 + amount as a uint64, and 2^64 * (l - 1) is added to it,
+	// which is -2^64 modulo the group order l, so that the sum is the amount
+	negative := byte(uint64(amount) >> 63)
 	amountBytes := make([]byte, 64)
-	binary.BigEndian.PutUint64(amountBytes, uint64(amount))
+	binary.LittleEndian.PutUint64(amountBytes, uint64(amount))
+	for i, b := range orderMinusOne {
+		amountBytes[8+i] = b & -negative
+	}
 	amountScalar := ed25519.NewScalar()
 	_, err := amountScalar.SetUniformBytes(amountBytes)
 	if err != nil {
 		return nil, err
 	}
-	if isAmountNegative {
-		amountScalar.Negate(amountScalar)
+	return amountScalar, nil
+}
+
+// legacyAmountScalar returns the scalar of an amount in a commitment before version 3, in constant time
+func legacyAmountScalar(amount int64) (*ed25519.Scalar, error) {
+	negative := uint64(amount) >> 63
+	magnitude := (uint64(amount) ^ -negative) + negative
+	amountBytes := make([]byte, 64)
+	binary.BigEndian.PutUint64(amountBytes, magnitude)
+	amountScalar := ed25519.NewScalar()
+	if _, err := amountScalar.SetUniformBytes(amountBytes); err != nil {
+		return nil, err
 	}
+	return condNegate(amountScalar, byte(negative)), nil
+}
+
+// BlindingScalar returns the blinding scalar of a commitment, Hash(timestamp || counter)
+func BlindingScalar(timestamp int64, counter uint64) (*ed25519.Scalar, error) {
+	return BlindingScalarWith(hashsuite.Default(), timestamp, counter)
+}
+
+// BlindingScalarWith returns the blinding scalar of a commitment with the hash of the suite.
+// Hashes longer than 64 bytes are truncated.
+func BlindingScalarWith(suite *hashsuite.Suite, timestamp int64, counter uint64) (*ed25519.Scalar, error) {
 	timestampBytes := make([]byte, 64)
 	binary.BigEndian.PutUint64(timestampBytes, uint64(timestamp))
 	counterBytes := make([]byte, 64)
 	binary.BigEndian.PutUint64(counterBytes, counter)
-	hashFunc := sha256.New()
-	hashFunc.Write(timestampBytes)
-	hashFunc.Write(counterBytes)
-	hashVal := hashFunc.Sum(nil)
+	hashVal := suite.Sum(hashsuite.Blinding, timestampBytes, counterBytes)
 	hashBytes := make([]byte, 64)
 	copy(hashBytes, hashVal)
 	hashScalar := ed25519.NewScalar()
-	_, err = hashScalar.SetUniformBytes(hashBytes)
+	_, err := hashScalar.SetUniformBytes(hashBytes)
 	if err != nil {
 		return nil, err
 	}
-	commitment := new(ed25519.Point).Set(g)
-	commitment.ScalarMult(amountScalar, commitment)
-	tmp := new(ed25519.Point).Set(h)
-	tmp.ScalarMult(hashScalar, tmp)
-	if negateHash {
-		commitment.Subtract(commitment, tmp)
-	} else {
-		commitment.Add(commitment, tmp)
-	}
-	return commitment.Bytes(), nil
+	return hashScalar, nil
 }
@@ -2,6 +2,7 @@ package commitment
 
 import (
 	"crypto/rand"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -79,6 +80,144 @@ func TestCommit(t *testing.T) {
 	}
 }
 
+func TestCommit_Homomorphism(t *testing.T) {
+	g, h := paramSetup()
+	type args struct {
+		a         int64
+		b         int64
+		timestamp int64
+		counter   uint64
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			name: "Test_Homomorphism_Zero",
+			args: args{a: 0, b: 0, timestamp: 0, counter: 0},
+		},
+		{
+			name: "Test_Homomorphism_Carry",
+			args: args{a: 255, b: 1, timestamp: 100, counter: 100},
+		},
+		{
+			name: "Test_Homomorphism_Mixed_Sign",
+			args: args{a: -7, b: 3, timestamp: -1, counter: math.MaxUint64},
+		},
+		{
+			name: "Test_Homomorphism_MinInt64",
+			args: args{a: math.MinInt64, b: math.MaxInt64, timestamp: math.MaxInt64, counter: 1},
+		},
+		{
+			name: "Test_Homomorphism_MinInt64_Zero",
+			args: args{a: math.MinInt64, b: 0, timestamp: math.MinInt64, counter: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !homomorphic(t, tt.args.a, tt.args.b, tt.args.timestamp, tt.args.counter, g, h) {
+				t.Errorf("Commit(%d) + Commit(%d) != Commit(%d) + Commit(0)", tt.args.a, tt.args.b, tt.args.a+tt.args.b)
+			}
+		})
+	}
+}
+
+func TestCommit_MinInt64(t *testing.T) {
+	g, h := paramSetup()
+	got, err := Commit(math.MinInt64, 100, 100, g, h, false)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	// 2^63 * G + r * H, computed independently of the int64 encoding
+	twoPow63 := make([]byte, 32)
+	twoPow63[7] = 0x80
+	scalar, err := ed25519.NewScalar().SetCanonicalBytes(twoPow63)
+	if err != nil {
+		t.Fatalf("cannot set scalar = %v", err)
+	}
+	want := new(ed25519.Point).ScalarMult(scalar, g)
+	want.Negate(want)
+	zero, err := Commit(0, 100, 100, g, h, false)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	zeroPoint, err := new(ed25519.Point).SetBytes(zero)
+	if err != nil {
+		t.Fatalf("cannot convert to point = %v", err)
+	}
+	want.Add(want, zeroPoint)
+	if !reflect.DeepEqual(got, want.Bytes()) {
+		t.Errorf("Commit() got = %v, want %v", got, want.Bytes())
+	}
+}
+
+func FuzzCommit(f *testing.F) {
+	f.Add(int64(0), int64(0), int64(0), uint64(0))
+	f.Add(int64(255), int64(1), int64(100), uint64(100))
+	f.Add(int64(math.MinInt64), int64(math.MaxInt64), int64(math.MaxInt64), uint64(math.MaxUint64))
+	f.Add(int64(-1), int64(math.MinInt64+1), int64(-1), uint64(1))
+	g, h := paramSetup()
+	f.Fuzz(func(t *testing.T, a, b, timestamp int64, counter uint64) {
+		// pair cancellation: Commit(a) + Commit(-a) with negated hash is the identity
+		if a != math.MinInt64 {
+			c1, err := Commit(a, timestamp, counter, g, h, false)
+			if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+			c2, err := Commit(-a, timestamp, counter, g, h, true)
+			if err != nil {
+				t.Fatalf("pair Commit() error = %v", err)
+			}
+			if !sumIsIdentity(t, [][]byte{c1, c2}, nil) {
+				t.Errorf("Commit(%d) pair does not cancel", a)
+			}
+		}
+		// skip sums that overflow int64
+		sum := a + b
+		if (b > 0 && sum < a) || (b < 0 && sum > a) {
+			return
+		}
+		if !homomorphic(t, a, b, timestamp, counter, g, h) {
+			t.Errorf("Commit(%d) + Commit(%d) != Commit(%d) + Commit(0)", a, b, sum)
+		}
+	})
+}
+
+// homomorphic checks Commit(a) + Commit(b) == Commit(a + b) + Commit(0) under the same timestamp and counter
+func homomorphic(t *testing.T, a, b, timestamp int64, counter uint64, g, h *ed25519.Point) bool {
+	t.Helper()
+	var commits [4][]byte
+	for i, amount := range []int64{a, b, a + b, 0} {
+		c, err := Commit(amount, timestamp, counter, g, h, false)
+		if err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		commits[i] = c
+	}
+	return sumIsIdentity(t, commits[:2], commits[2:])
+}
+
+// sumIsIdentity checks that the sum of the added commitments minus the subtracted ones is the identity
+func sumIsIdentity(t *testing.T, added, subtracted [][]byte) bool {
+	t.Helper()
+	sum := ed25519.NewIdentityPoint()
+	for _, c := range added {
+		point, err := new(ed25519.Point).SetBytes(c)
+		if err != nil {
+			t.Fatalf("cannot convert to point = %v", err)
+		}
+		sum.Add(sum, point)
+	}
+	for _, c := range subtracted {
+		point, err := new(ed25519.Point).SetBytes(c)
+		if err != nil {
+			t.Fatalf("cannot convert to point = %v", err)
+		}
+		sum.Subtract(sum, point)
+	}
+	return sum.Equal(ed25519.NewIdentityPoint()) == 1
+}
+
 func paramSetup() (g, h *ed25519.Point) {
 	randBytes := make([]byte, 64)
 	_, err := rand.Read(randBytes)
@@ -0,0 +1,73 @@
+package commitment
+
+import (
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// Committer generates commitments with the parameters, multiplying the generators in constant time
+// with their tables, which are precomputed once. A Committer is safe for concurrent use.
+type Committer struct {
+	params *Params
+	g, h   *Table
+}
+
+// NewCommitter precomputes the tables of the generators of the parameters
+func NewCommitter(params *Params) *Committer {
+	return &Committer{params: params, g: NewTable(params.G), h: NewTable(params.H)}
+}
+
+// Params returns the parameters of the committer
+func (c *Committer) Params() *Params {
+	return c.params
+}
+
+// Commit generates a commitment as CommitWith, in constant time. From protocol version 3, the amount scalar
+// is the amount itself, which is multiplied by its 64 bits only, with 17 of the 64 windows of the table of G.
+func (c *Committer) Commit(amount, timestamp int64, counter uint64, negateHash bool) ([]byte, error) {
+	version := c.params.ProtocolVersion()
+	if _, err := protocol.Resolve(protocol.Field(version)); err != nil {
+		return nil, err
+	}
+	hashScalar, err := BlindingScalarWith(c.params.Suite, timestamp, counter)
+	if err != nil {
+		return nil, err
+	}
+	blinding := negateIf(hashScalar, negateHash)
+	if !protocol.HomomorphicAmounts(version) {
+		amountScalar, err := AmountScalarWith(version, amount)
+		if err != nil {
+			return nil, err
+		}
+		return c.commit(amountScalar, blinding), nil
+	}
+	var v extended
+	v.zero()
+	c.g.addMultInt64(&v, amount)
+	c.h.addMult(&v, blinding)
+	return v.point().Bytes(), nil
+}
+
+// commit returns amount * G + blinding * H, accumulating both multiplications in a single point
+func (c *Committer) commit(amount, blinding *ed25519.Scalar) []byte {
+	var v extended
+	v.zero()
+	c.g.addMult(&v, amount)
+	c.h.addMult(&v, blinding)
+	return v.point().Bytes()
+}
+
+// CommitOpening returns the commitment of the opening, as Opening.Commit, in constant time
+func (c *Committer) CommitOpening(o *Opening) []byte {
+	return c.commit(o.Amount, o.Blinding)
+}
+
+// ScalarMultG returns x * G in constant time
+func (c *Committer) ScalarMultG(x *ed25519.Scalar) *ed25519.Point {
+	return c.g.ScalarMult(x)
+}
+
+// ScalarMultH returns x * H in constant time
+func (c *Committer) ScalarMultH(x *ed25519.Scalar) *ed25519.Point {
+	return c.h.ScalarMult(x)
+}
@@ -0,0 +1,137 @@
+package commitment
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// commitScalarMult generates a commitment with variable-base multiplications of the generators,
+// the reference of the tables
+func commitScalarMult(params *Params, amount, timestamp int64, counter uint64, negateHash bool) []byte {
+	amountScalar, err := AmountScalarWith(params.ProtocolVersion(), amount)
+	handleErr(err)
+	hashScalar, err := BlindingScalarWith(params.Suite, timestamp, counter)
+	handleErr(err)
+	commitment := new(ed25519.Point).ScalarMult(amountScalar, params.G)
+	tmp := new(ed25519.Point).ScalarMult(hashScalar, params.H)
+	if negateHash {
+		return commitment.Subtract(commitment, tmp).Bytes()
+	}
+	return commitment.Add(commitment, tmp).Bytes()
+}
+
+func TestCommitter_Commit(t *testing.T) {
+	g, h := paramSetup()
+	timestamp := time.Now().UnixNano()
+	tests := []struct {
+		name   string
+		params *Params
+	}{
+		{"Test_Default_Suite", NewParams(g, h)},
+		{"Test_Nil_Suite", &Params{G: g, H: h}},
+		{"Test_Tagged_Suite", &Params{G: g, H: h, Suite: hashsuite.SHA512}},
+		{"Test_Identity_Generators", NewParams(ed25519.NewIdentityPoint(), ed25519.NewIdentityPoint())},
+		{"Test_Version_2", &Params{G: g, H: h, Version: protocol.Version2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			committer := NewCommitter(tt.params)
+			for _, amount := range []int64{0, 1, -1, 8, -8, 1500, math.MaxInt64, math.MinInt64} {
+				for _, negateHash := range []bool{false, true} {
+					want := commitScalarMult(tt.params, amount, timestamp, 42, negateHash)
+					got, err := committer.Commit(amount, timestamp, 42, negateHash)
+					if err != nil || !bytes.Equal(got, want) {
+						t.Errorf("Commit(%d, %v) = %x, %v, want %x", amount, negateHash, got, err, want)
+					}
+					if got, err = CommitWith(tt.params, amount, timestamp, 42, negateHash); err != nil ||
+						!bytes.Equal(got, want) {
+						t.Errorf("CommitWith(%d, %v) = %x, %v, want %x", amount, negateHash, got, err, want)
+					}
+					opening, err := Open(amount, timestamp, 42, negateHash)
+					handleErr(err)
+					if tt.params.Suite == hashsuite.Default() && tt.params.Version == 0 && !bytes.Equal(committer.CommitOpening(opening), want) {
+						t.Errorf("CommitOpening(%d, %v) = %x, want %x",
+							amount, negateHash, committer.CommitOpening(opening), want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCommitter_Commit_UnsupportedVersion(t *testing.T) {
+	g, h := paramSetup()
+	params := &Params{G: g, H: h, Version: protocol.CurrentVersion + 1}
+	if _, err := NewCommitter(params).Commit(1500, 0, 0, false); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+		t.Errorf("Commit() error = %v, wantErr %v", err, protocol.ErrUnsupportedVersion)
+	}
+	if _, err := CommitWith(params, 1500, 0, 0, false); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+		t.Errorf("CommitWith() error = %v, wantErr %v", err, protocol.ErrUnsupportedVersion)
+	}
+}
+
+func TestParams_Committer(t *testing.T) {
+	g, h := paramSetup()
+	params := NewParams(g, h)
+	timestamp := time.Now().UnixNano()
+	var commitments [][]byte
+	for i := 0; i < 3; i++ {
+		c, err := CommitWith(params, -1500, timestamp, 42, true)
+		handleErr(err)
+		commitments = append(commitments, c)
+	}
+	if params.committer == nil {
+		t.Fatal("CommitWith() did not precompute the committer of the parameters")
+	}
+	if got := params.Committer(); got != params.committer {
+		t.Errorf("Committer() = %p, want the cached %p", got, params.committer)
+	}
+	want := commitScalarMult(params, -1500, timestamp, 42, true)
+	for i, c := range commitments {
+		if !bytes.Equal(c, want) {
+			t.Errorf("CommitWith() #%d = %x, want %x", i, c, want)
+		}
+	}
+}
+
+func BenchmarkCommit(b *testing.B) {
+	g, h := paramSetup()
+	timestamp := time.Now().UnixNano()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Commit(1500, timestamp, uint64(i), g, h, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCommitWith(b *testing.B) {
+	g, h := paramSetup()
+	params := NewParams(g, h)
+	timestamp := time.Now().UnixNano()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CommitWith(params, 1500, timestamp, uint64(i), false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCommitter_Commit(b *testing.B) {
+	g, h := paramSetup()
+	committer := NewCommitter(NewParams(g, h))
+	timestamp := time.Now().UnixNano()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := committer.Commit(1500, timestamp, uint64(i), false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
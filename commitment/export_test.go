@@ -0,0 +1,10 @@
+package commitment
+
+// setTraceTiming installs f as the hook called with the timing of every scalar multiplication,
+// and returns the function that removes it
+func setTraceTiming(f func(timing)) (restore func()) {
+	traceTiming = f
+	return func() {
+		traceTiming = nil
+	}
+}
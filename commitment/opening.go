@@ -0,0 +1,160 @@
+package commitment
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+// ErrOpeningMismatch is returned when an opening does not open the commitment
+var ErrOpeningMismatch = errors.New("commitment: the opening does not match the commitment")
+
+// Opening is the opening of a commitment, Commitment = Amount * G + Blinding * H.
+// Openings add up as their commitments do, so the opening of an aggregate commitment is the sum of the openings.
+type Opening struct {
+	Amount   *ed25519.Scalar
+	Blinding *ed25519.Scalar
+}
+
+// NewOpening creates the zero opening, the opening of the identity
+func NewOpening() *Opening {
+	return &Opening{Amount: ed25519.NewScalar(), Blinding: ed25519.NewScalar()}
+}
+
+// Open returns the opening of the commitment generated by Commit with the same arguments
+func Open(amount, timestamp int64, counter uint64, negateHash bool) (*Opening, error) {
+	return OpenWith(hashsuite.Default(), amount, timestamp, counter, negateHash)
+}
+
+// OpenWith returns the opening of the commitment generated by CommitWith with the same arguments,
+// for parameters of the hash suite and the current protocol version
+func OpenWith(suite *hashsuite.Suite, amount, timestamp int64, counter uint64, negateHash bool) (*Opening, error) {
+	amountScalar, err := AmountScalar(amount)
+	if err != nil {
+		return nil, err
+	}
+	blinding, err := BlindingScalarWith(suite, timestamp, counter)
+	if err != nil {
+		return nil, err
+	}
+	return &Opening{Amount: amountScalar, Blinding: negateIf(blinding, negateHash)}, nil
+}
+
+// Add sets o = a + b, and returns o
+func (o *Opening) Add(a, b *Opening) *Opening {
+	o.Amount = ed25519.NewScalar().Add(a.Amount, b.Amount)
+	o.Blinding = ed25519.NewScalar().Add(a.Blinding, b.Blinding)
+	return o
+}
+
+// Subtract sets o = a - b, and returns o
+func (o *Opening) Subtract(a, b *Opening) *Opening {
+	o.Amount = ed25519.NewScalar().Subtract(a.Amount, b.Amount)
+	o.Blinding = ed25519.NewScalar().Subtract(a.Blinding, b.Blinding)
+	return o
+}
+
+// Commit returns the commitment of the opening, in constant time
+func (o *Opening) Commit(g, h *ed25519.Point) []byte {
+	return commitScalars(g, h, o.Amount, o.Blinding)
+}
+
+// Verify checks that the opening opens the commitment, in constant time
+func (o *Opening) Verify(commitment []byte, g, h *ed25519.Point) error {
+	expected, _ := new(ed25519.Point).SetBytes(o.Commit(g, h))
+	return verifyOpening(commitment, expected)
+}
+
+// VarTimeCommit returns the commitment of a public opening, in variable time
+func (o *Opening) VarTimeCommit(g, h *ed25519.Point) []byte {
+	trace(variableTime)
+	return new(ed25519.Point).VarTimeMultiScalarMult([]*ed25519.Scalar{o.Amount, o.Blinding},
+		[]*ed25519.Point{g, h}).Bytes()
+}
+
+// VarTimeVerify checks that a public opening, such as an opening revealed in a dispute, opens the commitment,
+// in variable time
+func (o *Opening) VarTimeVerify(commitment []byte, g, h *ed25519.Point) error {
+	expected, _ := new(ed25519.Point).SetBytes(o.VarTimeCommit(g, h))
+	return verifyOpening(commitment, expected)
+}
+
+func verifyOpening(commitment []byte, expected *ed25519.Point) error {
+	point, err := new(ed25519.Point).SetBytes(commitment)
+	if err != nil {
+		return err
+	}
+	if point.Equal(expected) != 1 {
+		return ErrOpeningMismatch
+	}
+	return nil
+}
+
+// AmountInt64 returns the amount of a commitment of the current protocol version as an int64,
+// and false if it is out of the int64 range.
+// It runs in variable time, for the amounts of openings revealed to the caller.
+func (o *Opening) AmountInt64() (int64, bool) {
+	if magnitude, ok := smallScalar(o.Amount); ok && magnitude <= math.MaxInt64 {
+		return int64(magnitude), true
+	}
+	if magnitude, ok := smallScalar(ed25519.NewScalar().Negate(o.Amount)); ok && magnitude <= 1<<63 {
+		return int64(-magnitude), true
+	}
+	return 0, false
+}
+
+// smallScalar returns the value of a scalar less than 2^64
+func smallScalar(s *ed25519.Scalar) (uint64, bool) {
+	b := s.Bytes()
+	for _, v := range b[8:] {
+		if v != 0 {
+			return 0, false
+		}
+	}
+	return binary.LittleEndian.Uint64(b[:8]), true
+}
+
+// openingJSON is the JSON form of an opening
+type openingJSON struct {
+	Amount   string `json:"amount"`
+	Blinding string `json:"blinding"`
+}
+
+// MarshalJSON encodes the opening with hex scalars
+func (o *Opening) MarshalJSON() ([]byte, error) {
+	return json.Marshal(openingJSON{
+		Amount:   hex.EncodeToString(o.Amount.Bytes()),
+		Blinding: hex.EncodeToString(o.Blinding.Bytes()),
+	})
+}
+
+// UnmarshalJSON decodes an opening encoded by MarshalJSON
+func (o *Opening) UnmarshalJSON(data []byte) error {
+	var s openingJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	amount, err := decodeScalar(s.Amount)
+	if err != nil {
+		return err
+	}
+	blinding, err := decodeScalar(s.Blinding)
+	if err != nil {
+		return err
+	}
+	o.Amount, o.Blinding = amount, blinding
+	return nil
+}
+
+func decodeScalar(s string) (*ed25519.Scalar, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.NewScalar().SetCanonicalBytes(b)
+}
@@ -0,0 +1,133 @@
+package commitment
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+func TestOpen(t *testing.T) {
+	g, h := paramSetup()
+	type args struct {
+		amount     int64
+		timestamp  int64
+		counter    uint64
+		negateHash bool
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{"Test_Zero", args{0, 0, 0, false}},
+		{"Test_Positive", args{100, 1672531200000000000, 7, false}},
+		{"Test_Negative", args{-100, 1672531200000000000, 7, true}},
+		{"Test_Max", args{math.MaxInt64, -1, math.MaxUint64, false}},
+		{"Test_Min", args{math.MinInt64, 1, 1, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := Commit(tt.args.amount, tt.args.timestamp, tt.args.counter, g, h, tt.args.negateHash)
+			if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+			got, err := Open(tt.args.amount, tt.args.timestamp, tt.args.counter, tt.args.negateHash)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Commit(g, h), want) {
+				t.Errorf("Commit() got = %x, want %x", got.Commit(g, h), want)
+			}
+			if err = got.Verify(want, g, h); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if amount, ok := got.AmountInt64(); !ok || amount != tt.args.amount {
+				t.Errorf("AmountInt64() got = %d, %v, want %d", amount, ok, tt.args.amount)
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			var decoded Opening
+			if err = json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded.Commit(g, h), want) {
+				t.Errorf("UnmarshalJSON() does not round trip")
+			}
+		})
+	}
+}
+
+func TestOpenWith(t *testing.T) {
+	g, h := paramSetup()
+	tests := []struct {
+		name  string
+		suite *hashsuite.Suite
+	}{
+		{"Test_Default", hashsuite.Default()},
+		{"Test_Sha3256", hashsuite.SHA3_256},
+		{"Test_Blake2b512", hashsuite.BLAKE2b_512},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := CommitWith(&Params{G: g, H: h, Suite: tt.suite}, -100, 1672531200000000000, 7, true)
+			if err != nil {
+				t.Fatalf("CommitWith() error = %v", err)
+			}
+			got, err := OpenWith(tt.suite, -100, 1672531200000000000, 7, true)
+			if err != nil {
+				t.Fatalf("OpenWith() error = %v", err)
+			}
+			if err = got.Verify(want, g, h); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOpening_Add(t *testing.T) {
+	g, h := paramSetup()
+	amounts := []int64{math.MaxInt64, math.MaxInt64, -3, 250}
+	var commitments [][]byte
+	sum := NewOpening()
+	for i, amount := range amounts {
+		c, err := Commit(amount, 1672531200000000000, uint64(i), g, h, i%2 == 1)
+		if err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		commitments = append(commitments, c)
+		o, err := Open(amount, 1672531200000000000, uint64(i), i%2 == 1)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		sum.Add(sum, o)
+	}
+	// the opening of the aggregate opens the sum of the commitments
+	if !sumIsIdentity(t, commitments, [][]byte{sum.Commit(g, h)}) {
+		t.Errorf("Add() does not open the sum of the commitments")
+	}
+	// the sum overflows int64
+	if _, ok := sum.AmountInt64(); ok {
+		t.Errorf("AmountInt64() of an overflowing sum is ok")
+	}
+	for i := 0; i < 2; i++ {
+		o, err := Open(amounts[i], 1672531200000000000, uint64(i), i%2 == 1)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		sum.Subtract(sum, o)
+	}
+	if amount, ok := sum.AmountInt64(); !ok || amount != 247 {
+		t.Errorf("AmountInt64() got = %d, %v, want 247", amount, ok)
+	}
+	if err := sum.Verify(commitments[0], g, h); !errors.Is(err, ErrOpeningMismatch) {
+		t.Errorf("Verify() error = %v, wantErr %v", err, ErrOpeningMismatch)
+	}
+	if err := sum.Verify(make([]byte, 31), g, h); err == nil {
+		t.Errorf("Verify() of a malformed commitment succeeded")
+	}
+}
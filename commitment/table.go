@@ -0,0 +1,214 @@
+package commitment
+
+import (
+	"crypto/subtle"
+
+	ed25519 "filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+const (
+	// tableWindows is the number of 4-bit windows of a scalar
+	tableWindows = 64
+	// int64Windows is the number of 4-bit windows of the absolute value of an int64, and of its carry
+	int64Windows = 17
+)
+
+// d2 is 2*d of the curve, -x^2 + y^2 = 1 + d*x^2*y^2 with d = -121665/121666
+var d2 = func() *field.Element {
+	one := new(field.Element).One()
+	d := new(field.Element).Mult32(one, 121665)
+	d.Negate(d)
+	d.Multiply(d, new(field.Element).Invert(new(field.Element).Mult32(one, 121666)))
+	return d.Add(d, d)
+}()
+
+// affineCached is a point in affine coordinates in the form of its mixed addition, (y+x, y-x, 2*d*x*y)
+type affineCached struct {
+	yPlusX, yMinusX, xy2d field.Element
+}
+
+// window is the multiples 1 to 8 of the base point of a window
+type window [8]affineCached
+
+// Table is the precomputed table of a fixed base point, for constant-time multiplication of the point.
+// It holds j * 16^i * P for every window i of a scalar and 1 <= j <= 8, so that a multiplication
+// takes 64 mixed additions and no doublings. A Table is safe for concurrent use.
+type Table struct {
+	windows [tableWindows]window
+}
+
+// NewTable precomputes the table of the point
+func NewTable(p *ed25519.Point) *Table {
+	t := new(Table)
+	// the multiples in extended coordinates, made affine with a single inversion of all their Z coordinates
+	var X, Y, Z [tableWindows * 8]field.Element
+	base := new(ed25519.Point).Set(p)
+	multiple := new(ed25519.Point)
+	for i := 0; i < tableWindows; i++ {
+		multiple.Set(base)
+		for j := 0; j < 8; j++ {
+			x, y, z, _ := multiple.ExtendedCoordinates()
+			X[i*8+j].Set(x)
+			Y[i*8+j].Set(y)
+			Z[i*8+j].Set(z)
+			multiple.Add(multiple, base)
+		}
+		// base becomes 16^(i+1) * P
+		for k := 0; k < 4; k++ {
+			base.Add(base, base)
+		}
+	}
+	invertAll(Z[:])
+	var x, y field.Element
+	for i := range X {
+		c := &t.windows[i/8][i%8]
+		x.Multiply(&X[i], &Z[i])
+		y.Multiply(&Y[i], &Z[i])
+		c.yPlusX.Add(&y, &x)
+		c.yMinusX.Subtract(&y, &x)
+		c.xy2d.Multiply(&x, &y)
+		c.xy2d.Multiply(&c.xy2d, d2)
+	}
+	return t
+}
+
+// invertAll inverts the non-zero elements in place with a single inversion, by Montgomery's trick
+func invertAll(elements []field.Element) {
+	// products[i] is the product of the elements before i
+	products := make([]field.Element, len(elements))
+	acc := new(field.Element).One()
+	for i := range elements {
+		products[i].Set(acc)
+		acc.Multiply(acc, &elements[i])
+	}
+	acc.Invert(acc)
+	var inv field.Element
+	for i := len(elements) - 1; i >= 0; i-- {
+		inv.Multiply(acc, &products[i])
+		acc.Multiply(acc, &elements[i])
+		elements[i].Set(&inv)
+	}
+}
+
+// ScalarMult returns x * P for the point P of the table, in constant time
+func (t *Table) ScalarMult(x *ed25519.Scalar) *ed25519.Point {
+	var v extended
+	v.zero()
+	t.addMult(&v, x)
+	return v.point()
+}
+
+// addMult adds x * P to v, in constant time
+func (t *Table) addMult(v *extended, x *ed25519.Scalar) {
+	trace(constantTime)
+	digits := signedRadix16(x)
+	var c affineCached
+	for i := range t.windows {
+		t.windows[i].selectInto(&c, digits[i])
+		v.addAffine(&c)
+	}
+}
+
+// addMultInt64 adds a * P to v, in constant time. An integer has the 17 signed digits of its absolute value,
+// all of whose signs are flipped for a negative integer, so that it takes 17 additions instead of 64.
+func (t *Table) addMultInt64(v *extended, a int64) {
+	trace(constantTime)
+	negative := uint64(a) >> 63
+	// the absolute value of math.MinInt64 is 2^63, which a uint64 holds
+	abs := (uint64(a) ^ -negative) + negative
+	var digits [int64Windows]int8
+	for i := 0; i < 16; i++ {
+		digits[i] = int8(abs >> (4 * i) & 15)
+	}
+	for i := 0; i < int64Windows-1; i++ {
+		carry := (digits[i] + 8) >> 4
+		digits[i] -= carry << 4
+		digits[i+1] += carry
+	}
+	sign := -int8(negative)
+	var c affineCached
+	for i, digit := range digits {
+		t.windows[i].selectInto(&c, (digit^sign)-sign)
+		v.addAffine(&c)
+	}
+}
+
+// extended is a point in extended coordinates (X:Y:Z:T), x = X/Z, y = Y/Z and x*y = T/Z
+type extended struct {
+	X, Y, Z, T field.Element
+}
+
+// zero sets v to the identity point
+func (v *extended) zero() {
+	v.X.Zero()
+	v.Y.One()
+	v.Z.One()
+	v.T.Zero()
+}
+
+func (v *extended) point() *ed25519.Point {
+	p, err := new(ed25519.Point).SetExtendedCoordinates(&v.X, &v.Y, &v.Z, &v.T)
+	if err != nil {
+		// the sum of points of the tables is always on the curve
+		panic("commitment: " + err.Error())
+	}
+	return p
+}
+
+// selectInto sets c to digit * Q for the multiples of Q, in constant time
+func (w *window) selectInto(c *affineCached, digit int8) {
+	// the sign and the absolute value of the digit, without branches
+	negative := int(uint8(digit) >> 7)
+	mask := digit >> 7
+	abs := uint8((digit ^ mask) - mask)
+	// the identity point
+	c.yPlusX.One()
+	c.yMinusX.One()
+	c.xy2d.Zero()
+	for j := range w {
+		cond := subtle.ConstantTimeByteEq(abs, uint8(j+1))
+		c.yPlusX.Select(&w[j].yPlusX, &c.yPlusX, cond)
+		c.yMinusX.Select(&w[j].yMinusX, &c.yMinusX, cond)
+		c.xy2d.Select(&w[j].xy2d, &c.xy2d, cond)
+	}
+	// -(x, y) is (-x, y), which swaps y+x and y-x and negates x*y
+	c.yPlusX.Swap(&c.yMinusX, negative)
+	c.xy2d.Select(new(field.Element).Negate(&c.xy2d), &c.xy2d, negative)
+}
+
+// addAffine adds the affine point to v, by the mixed addition of extended coordinates
+func (v *extended) addAffine(c *affineCached) {
+	var a, b, cc, dd, x3, y3, z3, t3 field.Element
+	a.Add(&v.Y, &v.X)
+	a.Multiply(&a, &c.yPlusX)
+	b.Subtract(&v.Y, &v.X)
+	b.Multiply(&b, &c.yMinusX)
+	cc.Multiply(&v.T, &c.xy2d)
+	dd.Add(&v.Z, &v.Z)
+	x3.Subtract(&a, &b)
+	y3.Add(&a, &b)
+	z3.Add(&dd, &cc)
+	t3.Subtract(&dd, &cc)
+	v.X.Multiply(&x3, &t3)
+	v.Y.Multiply(&y3, &z3)
+	v.Z.Multiply(&z3, &t3)
+	v.T.Multiply(&x3, &y3)
+}
+
+// signedRadix16 returns the digits of the scalar in radix 16, each in [-8, 8), with the last in [-8, 8].
+// The scalar is below 2^253, so the last digit absorbs the carry without overflow.
+func signedRadix16(x *ed25519.Scalar) [tableWindows]int8 {
+	b := x.Bytes()
+	var digits [tableWindows]int8
+	for i := 0; i < 32; i++ {
+		digits[2*i] = int8(b[i] & 15)
+		digits[2*i+1] = int8(b[i] >> 4)
+	}
+	for i := 0; i < tableWindows-1; i++ {
+		carry := (digits[i] + 8) >> 4
+		digits[i] -= carry << 4
+		digits[i+1] += carry
+	}
+	return digits
+}
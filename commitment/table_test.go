@@ -0,0 +1,77 @@
+package commitment
+
+import (
+	"crypto/rand"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+func TestTable_ScalarMult(t *testing.T) {
+	g, _ := paramSetup()
+	table := NewTable(g)
+	// l - 1, the largest scalar
+	maxScalar := ed25519.NewScalar().Subtract(ed25519.NewScalar(), mustAmountScalar(1))
+	tests := []struct {
+		name   string
+		scalar *ed25519.Scalar
+	}{
+		{"Test_Zero", ed25519.NewScalar()},
+		{"Test_One", mustAmountScalar(1)},
+		{"Test_Eight", mustAmountScalar(8)},
+		{"Test_Nine", mustAmountScalar(9)},
+		{"Test_Max", maxScalar},
+		{"Test_Negative_Amount", mustAmountScalar(-1500)},
+		{"Test_Random", randomScalar()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := new(ed25519.Point).ScalarMult(tt.scalar, g)
+			if got := table.ScalarMult(tt.scalar); got.Equal(want) != 1 {
+				t.Errorf("ScalarMult() = %x, want %x", got.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
+
+func BenchmarkTable_ScalarMult(b *testing.B) {
+	g, _ := paramSetup()
+	table := NewTable(g)
+	x := randomScalar()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table.ScalarMult(x)
+	}
+}
+
+func BenchmarkPoint_ScalarMult(b *testing.B) {
+	g, _ := paramSetup()
+	x := randomScalar()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		new(ed25519.Point).ScalarMult(x, g)
+	}
+}
+
+func BenchmarkNewTable(b *testing.B) {
+	g, _ := paramSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTable(g)
+	}
+}
+
+func mustAmountScalar(amount int64) *ed25519.Scalar {
+	s, err := AmountScalar(amount)
+	handleErr(err)
+	return s
+}
+
+func randomScalar() *ed25519.Scalar {
+	randBytes := make([]byte, 64)
+	_, err := rand.Read(randBytes)
+	handleErr(err)
+	s, err := ed25519.NewScalar().SetUniformBytes(randBytes)
+	handleErr(err)
+	return s
+}
@@ -0,0 +1,73 @@
+package commitment
+
+import (
+	ed25519 "filippo.io/edwards25519"
+)
+
+// timing is the timing of a scalar multiplication of the package. The amounts, blindings and openings of
+// commitments are secret, so the functions that take them run in constant time: Commit, CommitWith, Open,
+// AmountScalar, the methods of Committer and Table, Opening.Commit and Opening.Verify. The functions prefixed
+// VarTime take public values only, such as an opening revealed in a dispute, and run faster in variable time.
+type timing int
+
+const (
+	constantTime timing = iota + 1
+	variableTime
+)
+
+// traceTiming is called with the timing of every scalar multiplication, so that the tests can assert
+// which one each function takes. Only setTraceTiming of export_test.go sets it, so it is nil outside tests.
+var traceTiming func(timing)
+
+func trace(t timing) {
+	if traceTiming != nil {
+		traceTiming(t)
+	}
+}
+
+var (
+	// scalarOne is the scalar 1
+	scalarOne = mustUniformScalar(1)
+	// scalarMinusTwo is the scalar -2
+	scalarMinusTwo = ed25519.NewScalar().Negate(mustUniformScalar(2))
+	// orderMinusOne is the encoding of l - 1 for the group order l, the largest scalar
+	orderMinusOne = ed25519.NewScalar().Negate(scalarOne).Bytes()
+)
+
+func mustUniformScalar(v byte) *ed25519.Scalar {
+	b := make([]byte, 64)
+	b[0] = v
+	s, err := ed25519.NewScalar().SetUniformBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// negateIf sets s to -s if negate is true, and returns s. The negation is constant-time arithmetic on s,
+// while negate itself is public: it is the negateHash flag that tells the two records of a pair apart.
+func negateIf(s *ed25519.Scalar, negate bool) *ed25519.Scalar {
+	return condNegate(s, boolByte(negate))
+}
+
+// condNegate sets s to -s if negate is 1, and returns s, in constant time in both s and negate
+func condNegate(s *ed25519.Scalar, negate byte) *ed25519.Scalar {
+	condBytes := make([]byte, 64)
+	condBytes[0] = negate
+	cond, err := ed25519.NewScalar().SetUniformBytes(condBytes)
+	if err != nil {
+		panic(err)
+	}
+	// 1 - 2 * cond is -1 if negate is 1 and 1 otherwise
+	factor := ed25519.NewScalar().MultiplyAdd(cond, scalarMinusTwo, scalarOne)
+	return s.Multiply(s, factor)
+}
+
+// boolByte returns 1 for true and 0 for false
+func boolByte(b bool) byte {
+	var v byte
+	if b {
+		v = 1
+	}
+	return v
+}
@@ -0,0 +1,240 @@
+package commitment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// traceTimings returns the timings of the scalar multiplications of f
+func traceTimings(f func()) map[timing]bool {
+	timings := make(map[timing]bool)
+	defer setTraceTiming(func(t timing) {
+		timings[t] = true
+	})()
+	f()
+	return timings
+}
+
+func TestTiming(t *testing.T) {
+	g, h := paramSetup()
+	params := NewParams(g, h)
+	committer := NewCommitter(params)
+	timestamp := time.Now().UnixNano()
+	opening, err := Open(-1500, timestamp, 42, true)
+	handleErr(err)
+	c, err := Commit(-1500, timestamp, 42, g, h, true)
+	handleErr(err)
+	x := randomScalar()
+	tests := []struct {
+		name string
+		f    func() error
+		want timing
+	}{
+		{"Test_Commit", func() error {
+			_, err := Commit(-1500, timestamp, 42, g, h, true)
+			return err
+		}, constantTime},
+		{"Test_CommitWith", func() error {
+			_, err := CommitWith(params, -1500, timestamp, 42, true)
+			return err
+		}, constantTime},
+		{"Test_Committer_Commit", func() error {
+			_, err := committer.Commit(-1500, timestamp, 42, true)
+			return err
+		}, constantTime},
+		{"Test_Committer_CommitOpening", func() error {
+			committer.CommitOpening(opening)
+			return nil
+		}, constantTime},
+		{"Test_Committer_ScalarMultG", func() error {
+			committer.ScalarMultG(x)
+			return nil
+		}, constantTime},
+		{"Test_Committer_ScalarMultH", func() error {
+			committer.ScalarMultH(x)
+			return nil
+		}, constantTime},
+		{"Test_Table_ScalarMult", func() error {
+			NewTable(g).ScalarMult(x)
+			return nil
+		}, constantTime},
+		{"Test_Opening_Commit", func() error {
+			opening.Commit(g, h)
+			return nil
+		}, constantTime},
+		{"Test_Opening_Verify", func() error {
+			return opening.Verify(c, g, h)
+		}, constantTime},
+		{"Test_Opening_VarTimeCommit", func() error {
+			opening.VarTimeCommit(g, h)
+			return nil
+		}, variableTime},
+		{"Test_Opening_VarTimeVerify", func() error {
+			return opening.VarTimeVerify(c, g, h)
+		}, variableTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			got := traceTimings(func() {
+				err = tt.f()
+			})
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if want := map[timing]bool{tt.want: true}; !reflect.DeepEqual(got, want) {
+				t.Errorf("timings = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOpening_VarTimeVerify(t *testing.T) {
+	g, h := paramSetup()
+	timestamp := time.Now().UnixNano()
+	for _, amount := range []int64{0, 1500, -1500, math.MinInt64} {
+		opening, err := Open(amount, timestamp, 42, amount < 0)
+		handleErr(err)
+		if got, want := opening.VarTimeCommit(g, h), opening.Commit(g, h); !bytes.Equal(got, want) {
+			t.Errorf("VarTimeCommit(%d) = %x, want %x", amount, got, want)
+		}
+		c, err := Commit(amount, timestamp, 42, g, h, amount < 0)
+		handleErr(err)
+		if err = opening.VarTimeVerify(c, g, h); err != nil {
+			t.Errorf("VarTimeVerify(%d) error = %v", amount, err)
+		}
+		other, err := Open(amount+1, timestamp, 42, amount < 0)
+		handleErr(err)
+		if err = other.VarTimeVerify(c, g, h); err != ErrOpeningMismatch {
+			t.Errorf("VarTimeVerify(%d) of another opening error = %v, want %v", amount, err, ErrOpeningMismatch)
+		}
+	}
+}
+
+// amountScalarBranching is the scalar of an amount computed by branching on its sign, the reference of AmountScalar
+func amountScalarBranching(amount int64) *ed25519.Scalar {
+	magnitude := uint64(amount)
+	if amount < 0 {
+		magnitude = -magnitude
+	}
+	b := make([]byte, 64)
+	binary.LittleEndian.PutUint64(b, magnitude)
+	s, err := ed25519.NewScalar().SetUniformBytes(b)
+	handleErr(err)
+	if amount < 0 {
+		s.Negate(s)
+	}
+	return s
+}
+
+func TestAmountScalar(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+	}{
+		{"Test_Zero", 0},
+		{"Test_One", 1},
+		{"Test_Minus_One", -1},
+		{"Test_Two", 2},
+		{"Test_Minus_Two", -2},
+		{"Test_Positive", 1500},
+		{"Test_Negative", -1500},
+		{"Test_Large", 1 << 62},
+		{"Test_Large_Negative", -1 << 62},
+		{"Test_Max_Int64", math.MaxInt64},
+		{"Test_Min_Int64", math.MinInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AmountScalar(tt.amount)
+			if err != nil {
+				t.Fatalf("AmountScalar(%d) error = %v", tt.amount, err)
+			}
+			if want := amountScalarBranching(tt.amount); got.Equal(want) != 1 {
+				t.Errorf("AmountScalar(%d) = %x, want %x", tt.amount, got.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
+
+// legacyAmountScalarBranching is the scalar of an amount before protocol version 3, computed as Commit did
+// before the version by branching on the sign, the reference of AmountScalarWith
+func legacyAmountScalarBranching(amount int64) *ed25519.Scalar {
+	var isAmountNegative bool
+	magnitude := uint64(amount)
+	if amount < 0 {
+		isAmountNegative = true
+		magnitude = -magnitude
+	}
+	b := make([]byte, 64)
+	binary.BigEndian.PutUint64(b, magnitude)
+	s, err := ed25519.NewScalar().SetUniformBytes(b)
+	handleErr(err)
+	if isAmountNegative {
+		s.Negate(s)
+	}
+	return s
+}
+
+func TestAmountScalarWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		amount  int64
+		want    *ed25519.Scalar
+	}{
+		{"Test_Version_1_Positive", protocol.Version1, 1500, legacyAmountScalarBranching(1500)},
+		{"Test_Version_2_Zero", protocol.Version2, 0, legacyAmountScalarBranching(0)},
+		{"Test_Version_2_Negative", protocol.Version2, -1500, legacyAmountScalarBranching(-1500)},
+		{"Test_Version_2_Byte_Carry", protocol.Version2, 256, legacyAmountScalarBranching(256)},
+		{"Test_Version_2_Max_Int64", protocol.Version2, math.MaxInt64, legacyAmountScalarBranching(math.MaxInt64)},
+		{"Test_Version_2_Min_Int64", protocol.Version2, math.MinInt64, legacyAmountScalarBranching(math.MinInt64)},
+		{"Test_Version_3_Negative", protocol.Version3, -1500, amountScalarBranching(-1500)},
+		{"Test_Version_3_Byte_Carry", protocol.Version3, 256, amountScalarBranching(256)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AmountScalarWith(tt.version, tt.amount)
+			if err != nil {
+				t.Fatalf("AmountScalarWith(%d, %d) error = %v", tt.version, tt.amount, err)
+			}
+			if got.Equal(tt.want) != 1 {
+				t.Errorf("AmountScalarWith(%d, %d) = %x, want %x", tt.version, tt.amount, got.Bytes(), tt.want.Bytes())
+			}
+		})
+	}
+	if _, err := AmountScalarWith(protocol.CurrentVersion+1, 0); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+		t.Errorf("AmountScalarWith() of a future version error = %v, wantErr %v", err, protocol.ErrUnsupportedVersion)
+	}
+}
+
+func TestNegateIf(t *testing.T) {
+	tests := []struct {
+		name string
+		x    *ed25519.Scalar
+	}{
+		{"Test_Zero", ed25519.NewScalar()},
+		{"Test_One", scalarOne},
+		{"Test_Minus_One", ed25519.NewScalar().Negate(scalarOne)},
+		{"Test_Random", randomScalar()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negateIf(ed25519.NewScalar().Set(tt.x), false); got.Equal(tt.x) != 1 {
+				t.Errorf("negateIf(false) = %x, want %x", got.Bytes(), tt.x.Bytes())
+			}
+			want := ed25519.NewScalar().Negate(tt.x)
+			if got := negateIf(ed25519.NewScalar().Set(tt.x), true); got.Equal(want) != 1 {
+				t.Errorf("negateIf(true) = %x, want %x", got.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
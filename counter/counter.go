@@ -0,0 +1,136 @@
+package counter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrReused is returned when a counter is used more than once on the same chain
+var ErrReused = errors.New("counter: counter reused")
+
+// Key identifies the counter of an organization on the chain with a counterparty
+type Key struct {
+	OrgID   string
+	ChainID string
+}
+
+// Store is the persistence hook of an allocator
+type Store interface {
+	// Load returns the next counter of every key
+	Load() (map[Key]uint64, error)
+	// Save records the next counter of a key, it is called before a counter is handed out
+	Save(key Key, next uint64) error
+}
+
+// Allocator hands out commitment counters, it is safe for concurrent use
+type Allocator struct {
+	mu    sync.Mutex
+	store Store
+	next  map[Key]uint64
+}
+
+// NewAllocator creates a new allocator, restoring the counters from the store if it is not nil
+func NewAllocator(store Store) (*Allocator, error) {
+	a := &Allocator{
+		store: store,
+		next:  make(map[Key]uint64),
+	}
+	if store == nil {
+		return a, nil
+	}
+	next, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range next {
+		a.next[key] = val
+	}
+	return a, nil
+}
+
+// Next allocates the next counter of the key
+func (a *Allocator) Next(key Key) (uint64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	counter := a.next[key]
+	if counter == ^uint64(0) {
+		return 0, fmt.Errorf("counter: counters of %v are exhausted", key)
+	}
+	// persist before handing out the counter, so that it is not handed out again after a crash
+	if err := a.save(key, counter+1); err != nil {
+		return 0, err
+	}
+	a.next[key] = counter + 1
+	return counter, nil
+}
+
+// Peek returns the next counter of the key without allocating it
+func (a *Allocator) Peek(key Key) uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.next[key]
+}
+
+// Replay replays the counters used by an existing ledger of the key.
+// It returns ErrReused if a counter appears more than once, otherwise
+// it advances the key past the largest counter, so that none of them is allocated again.
+func (a *Allocator) Replay(key Key, counters []uint64) error {
+	seen := make(map[uint64]struct{}, len(counters))
+	var (
+		maxCounter uint64
+		observed   bool
+	)
+	for _, counter := range counters {
+		if _, ok := seen[counter]; ok {
+			return fmt.Errorf("%w: counter %d of %v", ErrReused, counter, key)
+		}
+		seen[counter] = struct{}{}
+		if !observed || counter > maxCounter {
+			maxCounter = counter
+			observed = true
+		}
+	}
+	if !observed {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if maxCounter < a.next[key] {
+		return nil
+	}
+	if maxCounter == ^uint64(0) {
+		return fmt.Errorf("counter: counters of %v are exhausted", key)
+	}
+	if err := a.save(key, maxCounter+1); err != nil {
+		return err
+	}
+	a.next[key] = maxCounter + 1
+	return nil
+}
+
+// Source returns the counter source of the key, for drawing counters automatically when hiding transactions
+func (a *Allocator) Source(key Key) *Source {
+	return &Source{
+		allocator: a,
+		key:       key,
+	}
+}
+
+func (a *Allocator) save(key Key, next uint64) error {
+	if a.store == nil {
+		return nil
+	}
+	return a.store.Save(key, next)
+}
+
+// Source is the counter source of a single key
+type Source struct {
+	allocator *Allocator
+	key       Key
+}
+
+// Next allocates the next counter of the key
+func (s *Source) Next() (uint64, error) {
+	return s.allocator.Next(s.key)
+}
@@ -0,0 +1,123 @@
+package counter
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestAllocator_Next(t *testing.T) {
+	a, err := NewAllocator(nil)
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+	key := Key{OrgID: "org1", ChainID: "org2"}
+	const numWorkers, numCounters = 8, 100
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		counted = make(map[uint64]int)
+	)
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numCounters; j++ {
+				counter, err := a.Next(key)
+				if err != nil {
+					t.Errorf("Next() error = %v", err)
+					return
+				}
+				mu.Lock()
+				counted[counter]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(counted) != numWorkers*numCounters {
+		t.Errorf("Next() got %d distinct counters, want %d", len(counted), numWorkers*numCounters)
+	}
+	if got := a.Peek(key); got != numWorkers*numCounters {
+		t.Errorf("Peek() got = %d, want %d", got, numWorkers*numCounters)
+	}
+	if got := a.Peek(Key{OrgID: "org1", ChainID: "org3"}); got != 0 {
+		t.Errorf("Peek() of another chain got = %d, want 0", got)
+	}
+}
+
+func TestAllocator_Replay(t *testing.T) {
+	key := Key{OrgID: "org1", ChainID: "org2"}
+	tests := []struct {
+		name     string
+		counters []uint64
+		wantNext uint64
+		wantErr  error
+	}{
+		{
+			name:     "Test_Empty_Ledger",
+			counters: nil,
+			wantNext: 0,
+		},
+		{
+			name:     "Test_Out_Of_Order",
+			counters: []uint64{3, 0, 2, 1},
+			wantNext: 4,
+		},
+		{
+			name:     "Test_Reused",
+			counters: []uint64{0, 1, 2, 1},
+			wantNext: 0,
+			wantErr:  ErrReused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAllocator(nil)
+			if err != nil {
+				t.Fatalf("NewAllocator() error = %v", err)
+			}
+			err = a.Replay(key, tt.counters)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Replay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := a.Peek(key); got != tt.wantNext {
+				t.Errorf("Peek() got = %d, want %d", got, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counters.json")
+	key1 := Key{OrgID: "org1", ChainID: "org2"}
+	key2 := Key{OrgID: "org1", ChainID: "org3"}
+	a, err := NewAllocator(NewFileStore(path))
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err = a.Next(key1); err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+	}
+	if err = a.Replay(key2, []uint64{0, 9}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	// restart from the same file
+	restarted, err := NewAllocator(NewFileStore(path))
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+	got, err := restarted.Next(key1)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if got != 3 {
+		t.Errorf("Next() after restart got = %d, want 3", got)
+	}
+	if got = restarted.Peek(key2); got != 10 {
+		t.Errorf("Peek() after restart got = %d, want 10", got)
+	}
+}
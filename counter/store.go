@@ -0,0 +1,96 @@
+package counter
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/auti-project/auti-core/internal/atomicfile"
+)
+
+// fileEntry is the JSON form of a counter in a file store
+type fileEntry struct {
+	OrgID   string `json:"org_id"`
+	ChainID string `json:"chain_id"`
+	Next    uint64 `json:"next"`
+}
+
+// FileStore is a Store that keeps the counters in a JSON file, replaced atomically on every save
+type FileStore struct {
+	mu     sync.Mutex
+	path   string
+	next   map[Key]uint64
+	loaded bool
+}
+
+// NewFileStore creates a new file store at the path, the file is created on the first save
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+		next: make(map[Key]uint64),
+	}
+}
+
+// Load returns the next counter of every key in the file
+func (s *FileStore) Load() (map[Key]uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	next := make(map[Key]uint64, len(s.next))
+	for key, val := range s.next {
+		next[key] = val
+	}
+	return next, nil
+}
+
+func (s *FileStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries []fileEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	s.next = make(map[Key]uint64, len(entries))
+	for _, entry := range entries {
+		s.next[Key{OrgID: entry.OrgID, ChainID: entry.ChainID}] = entry.Next
+	}
+	s.loaded = true
+	return nil
+}
+
+// Save records the next counter of a key and rewrites the file
+func (s *FileStore) Save(key Key, next uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// keep the counters of the other keys in the file
+	if !s.loaded {
+		if err := s.load(); err != nil {
+			return err
+		}
+	}
+	s.next[key] = next
+	entries := make([]fileEntry, 0, len(s.next))
+	for k, v := range s.next {
+		entries = append(entries, fileEntry{OrgID: k.OrgID, ChainID: k.ChainID, Next: v})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	// a counter file that comes back after a crash would hand out its counters again
+	return atomicfile.WriteFile(s.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,75 @@
+package crosschain
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/merkle"
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// Bundle is the cross-chain record of many commitments under one Merkle root, with a single multiproof
+type Bundle struct {
+	// Version is the protocol version, bundles start at version 2
+	Version     int      `json:"Version"`
+	Commitments []string `json:"Commits"`
+	MultiProof  string   `json:"Proof"`
+	MerkleRoot  string   `json:"Root"`
+}
+
+// NewBundle creates a new cross-chain bundle of the commitments, in the order of the indices of the multiproof
+func NewBundle(commitments [][]byte, proof *merkle.MultiProof, root []byte) *Bundle {
+	b := &Bundle{
+		Version:     protocol.CurrentVersion,
+		Commitments: make([]string, len(commitments)),
+		MultiProof:  hex.EncodeToString(proof.Bytes()),
+		MerkleRoot:  hex.EncodeToString(root),
+	}
+	for i, c := range commitments {
+		b.Commitments[i] = hex.EncodeToString(c)
+	}
+	return b
+}
+
+// KeyVal generates the key-value pair of the bundle to be stored on-chain
+func (b *Bundle) KeyVal() (string, []byte, error) {
+	return b.KeyValWith(hashsuite.Default())
+}
+
+// KeyValWith returns the key-value pair as KeyVal, hashing the key with the suite
+func (b *Bundle) KeyValWith(suite *hashsuite.Suite) (string, []byte, error) {
+	jsonBytes, err := json.Marshal(b)
+	if err != nil {
+		return "", nil, err
+	}
+	return hex.EncodeToString(suite.Sum(hashsuite.Key, jsonBytes)), jsonBytes, nil
+}
+
+// Reveal reveals the data recorded
+func (b *Bundle) Reveal() (commits [][]byte, proof *merkle.MultiProof, root []byte, err error) {
+	commits = make([][]byte, len(b.Commitments))
+	for i, c := range b.Commitments {
+		if commits[i], err = hex.DecodeString(c); err != nil {
+			return
+		}
+	}
+	proofBytes, err := hex.DecodeString(b.MultiProof)
+	if err != nil {
+		return
+	}
+	if proof, err = merkle.ParseMultiProof(proofBytes); err != nil {
+		return
+	}
+	root, err = hex.DecodeString(b.MerkleRoot)
+	return
+}
+
+// Verify verifies that the commitments of the bundle are in the tree of its root
+func (b *Bundle) Verify() error {
+	commits, proof, root, err := b.Reveal()
+	if err != nil {
+		return err
+	}
+	return merkle.VerifyMulti(root, commits, proof)
+}
@@ -1,21 +1,43 @@
 package crosschain
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/merkle"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/smt"
+)
+
+var (
+	// ErrNotExtended is returned when a record does not show that its tree extends the tree of the previous record
+	ErrNotExtended = errors.New("crosschain: the tree does not extend the previous tree")
+	// ErrNoState is returned when verifying the chain state of a record without a state proof
+	ErrNoState = errors.New("crosschain: the record has no state proof")
 )
 
 // Record is the cross-chain record on chain
 type Record struct {
+	// Version is the protocol version, absent in version 1 records
+	Version     int    `json:"Version,omitempty"`
 	Commitment  string `json:"Commit"`
 	MerkleProof string `json:"Proof"`
 	MerkleRoot  string `json:"Root"`
+	// PrevRoot and Consistency optionally show that the tree of the root only appended to the tree of PrevRoot
+	PrevRoot    string `json:"PrevRoot,omitempty"`
+	Consistency string `json:"Consistency,omitempty"`
+	// StateRoot and StateProof optionally prove the aggregate commitment of a chain, or its absence
+	StateRoot  string `json:"StateRoot,omitempty"`
+	StateProof string `json:"StateProof,omitempty"`
 }
 
 // NewRecord creates a new cross-chain record
 func NewRecord(commitment, proof, root []byte) (*Record, error) {
 	return &Record{
+		Version:     protocol.CurrentVersion,
 		Commitment:  hex.EncodeToString(commitment),
 		MerkleProof: hex.EncodeToString(proof),
 		MerkleRoot:  hex.EncodeToString(root),
@@ -24,13 +46,16 @@ func NewRecord(commitment, proof, root []byte) (*Record, error) {
 
 // KeyVal generates the key-value pair of the record to be stored on-chain
 func (r *Record) KeyVal() (string, []byte, error) {
+	return r.KeyValWith(hashsuite.Default())
+}
+
+// KeyValWith returns the key-value pair as KeyVal, hashing the key with the suite
+func (r *Record) KeyValWith(suite *hashsuite.Suite) (string, []byte, error) {
 	jsonBytes, err := json.Marshal(r)
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Hash := sha256.New()
-	sha256Hash.Write(jsonBytes)
-	return hex.EncodeToString(sha256Hash.Sum(nil)), jsonBytes, nil
+	return hex.EncodeToString(suite.Sum(hashsuite.Key, jsonBytes)), jsonBytes, nil
 }
 
 // Reveal reveals the data recorded
@@ -46,3 +71,66 @@ func (r *Record) Reveal() (commit, proof, root []byte, err error) {
 	root, err = hex.DecodeString(r.MerkleRoot)
 	return
 }
+
+// SetConsistency records the consistency proof between the tree of the previous root and the tree of the record
+func (r *Record) SetConsistency(prevRoot []byte, proof *merkle.ConsistencyProof) {
+	r.PrevRoot = hex.EncodeToString(prevRoot)
+	r.Consistency = hex.EncodeToString(proof.Bytes())
+}
+
+// VerifyExtends verifies that the tree of the record extends the tree of the previous record of the chain
+func (r *Record) VerifyExtends(prev *Record) error {
+	if r.Consistency == "" || r.PrevRoot != prev.MerkleRoot {
+		return ErrNotExtended
+	}
+	prevRoot, err := hex.DecodeString(r.PrevRoot)
+	if err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(r.MerkleRoot)
+	if err != nil {
+		return err
+	}
+	proofBytes, err := hex.DecodeString(r.Consistency)
+	if err != nil {
+		return err
+	}
+	proof, err := merkle.ParseConsistencyProof(proofBytes)
+	if err != nil {
+		return err
+	}
+	if err = merkle.VerifyConsistency(prevRoot, root, proof); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotExtended, err)
+	}
+	return nil
+}
+
+// SetState records the proof of a chain in the sparse Merkle tree of the state root
+func (r *Record) SetState(stateRoot []byte, proof *smt.Proof) {
+	r.StateRoot = hex.EncodeToString(stateRoot)
+	r.StateProof = hex.EncodeToString(proof.Bytes())
+}
+
+// VerifyState verifies that the chain of the key has the aggregate commitment in the state of the record,
+// or that the chain is absent when the aggregate commitment is nil
+func (r *Record) VerifyState(key smt.Key, aggregate []byte) error {
+	if r.StateProof == "" {
+		return ErrNoState
+	}
+	stateRoot, err := hex.DecodeString(r.StateRoot)
+	if err != nil {
+		return err
+	}
+	proofBytes, err := hex.DecodeString(r.StateProof)
+	if err != nil {
+		return err
+	}
+	proof, err := smt.ParseProof(proofBytes)
+	if err != nil {
+		return err
+	}
+	if aggregate == nil {
+		return smt.VerifyNonInclusion(stateRoot, key, proof)
+	}
+	return smt.VerifyInclusion(stateRoot, key, aggregate, proof)
+}
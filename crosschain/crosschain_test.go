@@ -0,0 +1,163 @@
+package crosschain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/auti-project/auti-core/merkle"
+	"github.com/auti-project/auti-core/smt"
+)
+
+func TestRecord_VerifyExtends(t *testing.T) {
+	tree := merkle.NewTree([][]byte{[]byte("tx0"), []byte("tx1"), []byte("tx2")})
+	prevRoot := tree.Root()
+	prev, err := NewRecord([]byte{1}, nil, prevRoot)
+	if err != nil {
+		t.Fatalf("NewRecord() error = %v", err)
+	}
+	tree.Append([]byte("tx3"))
+	tree.Append([]byte("tx4"))
+	proof, err := tree.ProveConsistency(3, 5)
+	if err != nil {
+		t.Fatalf("ProveConsistency() error = %v", err)
+	}
+	newRecord := func(root, prevRoot []byte, proof *merkle.ConsistencyProof) *Record {
+		r, err := NewRecord([]byte{2}, nil, root)
+		if err != nil {
+			t.Fatalf("NewRecord() error = %v", err)
+		}
+		if proof != nil {
+			r.SetConsistency(prevRoot, proof)
+		}
+		return r
+	}
+	rewritten := merkle.NewTree([][]byte{[]byte("tx0"), []byte("forged"), []byte("tx2"), []byte("tx3"), []byte("tx4")})
+	tests := []struct {
+		name    string
+		record  *Record
+		wantErr error
+	}{
+		{"Test_Extended", newRecord(tree.Root(), prevRoot, proof), nil},
+		{"Test_No_Proof", newRecord(tree.Root(), nil, nil), ErrNotExtended},
+		{"Test_Other_Previous_Root", newRecord(tree.Root(), merkle.EmptyRoot(), proof), ErrNotExtended},
+		{"Test_Rewritten", newRecord(rewritten.Root(), prevRoot, proof), ErrNotExtended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.record.VerifyExtends(prev); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyExtends() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecord_VerifyState(t *testing.T) {
+	state := smt.NewTree()
+	key := smt.ChainKey([]byte("org1"), []byte("org2"))
+	absent := smt.ChainKey([]byte("org2"), []byte("org1"))
+	aggregate := bytes.Repeat([]byte{1}, 32)
+	if err := state.Set(key, aggregate); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	newRecord := func(key smt.Key) *Record {
+		r, err := NewRecord([]byte{1}, nil, merkle.EmptyRoot())
+		if err != nil {
+			t.Fatalf("NewRecord() error = %v", err)
+		}
+		r.SetState(state.Root(), state.Prove(key))
+		return r
+	}
+	noState, err := NewRecord([]byte{1}, nil, merkle.EmptyRoot())
+	if err != nil {
+		t.Fatalf("NewRecord() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		record    *Record
+		key       smt.Key
+		aggregate []byte
+		wantErr   error
+	}{
+		{"Test_Included", newRecord(key), key, aggregate, nil},
+		{"Test_Absent", newRecord(absent), absent, nil, nil},
+		{"Test_Wrong_Aggregate", newRecord(key), key, make([]byte, 32), smt.ErrInvalidProof},
+		{"Test_Claimed_Absent", newRecord(key), key, nil, smt.ErrInvalidProof},
+		{"Test_Other_Key", newRecord(absent), key, aggregate, smt.ErrInvalidProof},
+		{"Test_No_State", noState, key, aggregate, ErrNoState},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.record.VerifyState(tt.key, tt.aggregate); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBundle_Verify(t *testing.T) {
+	commits := make([][]byte, 10)
+	for i := range commits {
+		commits[i] = bytes.Repeat([]byte{byte(i)}, 32)
+	}
+	tree := merkle.NewTree(commits)
+	proof, err := tree.ProveMulti([]uint64{2, 5, 6})
+	if err != nil {
+		t.Fatalf("ProveMulti() error = %v", err)
+	}
+	valid := NewBundle([][]byte{commits[2], commits[5], commits[6]}, proof, tree.Root())
+	_, jsonBytes, err := valid.KeyVal()
+	if err != nil {
+		t.Fatalf("KeyVal() error = %v", err)
+	}
+	decoded := new(Bundle)
+	if err = json.Unmarshal(jsonBytes, decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		bundle  *Bundle
+		wantErr error
+	}{
+		{"Test_Valid", valid, nil},
+		{"Test_Decoded", decoded, nil},
+		{"Test_Other_Commitment", NewBundle([][]byte{commits[2], commits[5], commits[7]}, proof, tree.Root()),
+			merkle.ErrInvalidProof},
+		{"Test_Other_Root", NewBundle([][]byte{commits[2], commits[5], commits[6]}, proof, merkle.EmptyRoot()),
+			merkle.ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.bundle.Verify(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecord_MarshalBinary(t *testing.T) {
+	tree := merkle.NewTree([][]byte{[]byte("tx0"), []byte("tx1")})
+	proof, err := tree.ProveConsistency(1, 2)
+	if err != nil {
+		t.Fatalf("ProveConsistency() error = %v", err)
+	}
+	state := smt.NewTree()
+	record, err := NewRecord([]byte{1}, []byte{2}, tree.Root())
+	if err != nil {
+		t.Fatalf("NewRecord() error = %v", err)
+	}
+	record.SetConsistency(merkle.LeafHash([]byte("tx0")), proof)
+	record.SetState(state.Root(), state.Prove(smt.Key{}))
+	data, err := record.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	got := new(Record)
+	if err = got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if *got != *record {
+		t.Errorf("UnmarshalBinary() got = %v, want %v", got, record)
+	}
+}
@@ -0,0 +1,51 @@
+package crosschain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// DecodeRecord decodes a cross-chain record of any supported protocol version. Its commitment is a hex string in
+// every version, whichever amount encoding the version gives it.
+func DecodeRecord(data []byte) (*Record, error) {
+	r := new(Record)
+	if _, err := protocol.Decode(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ProtocolVersion returns the protocol version of the record
+func (r *Record) ProtocolVersion() (int, error) {
+	return protocol.Resolve(r.Version)
+}
+
+// Upgrade returns a copy of the record at the version it upgrades to, version 2 for a record whose commitment
+// predates the amount encoding of version 3. The record is not signed, so only its version changes.
+func (r *Record) Upgrade() (*Record, error) {
+	field, _, err := protocol.Upgrade(r.Version, true)
+	if err != nil {
+		return nil, err
+	}
+	upgraded := *r
+	upgraded.Version = field
+	return &upgraded, nil
+}
+
+// DecodeBundle decodes a cross-chain bundle, which exists since protocol version 2
+func DecodeBundle(data []byte) (*Bundle, error) {
+	version, err := protocol.PeekVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version < protocol.Version2 {
+		return nil, fmt.Errorf("%w %d of a bundle", protocol.ErrUnsupportedVersion, version)
+	}
+	b := new(Bundle)
+	if err = json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,83 @@
+package crosschain
+
+import (
+	"errors"
+	"io"
+
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/wire"
+)
+
+// Field numbers of the binary encoding of cross-chain records
+const (
+	fieldVersion = iota + 1
+	fieldCommitment
+	fieldMerkleProof
+	fieldMerkleRoot
+	fieldPrevRoot
+	fieldConsistency
+	fieldStateRoot
+	fieldStateProof
+)
+
+// hexFields returns the hex fields of the record by their field numbers
+func (r *Record) hexFields() []struct {
+	number int
+	value  *string
+} {
+	return []struct {
+		number int
+		value  *string
+	}{
+		{fieldCommitment, &r.Commitment},
+		{fieldMerkleProof, &r.MerkleProof},
+		{fieldMerkleRoot, &r.MerkleRoot},
+		{fieldPrevRoot, &r.PrevRoot},
+		{fieldConsistency, &r.Consistency},
+		{fieldStateRoot, &r.StateRoot},
+		{fieldStateProof, &r.StateProof},
+	}
+}
+
+// MarshalBinary encodes the record in the binary wire format, with the hex fields as bytes
+func (r *Record) MarshalBinary() ([]byte, error) {
+	buf := wire.AppendInt(nil, fieldVersion, int64(r.Version))
+	var err error
+	for _, field := range r.hexFields() {
+		if buf, err = wire.AppendHex(buf, field.number, *field.value); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a record of MarshalBinary of a supported protocol version
+func (r *Record) UnmarshalBinary(data []byte) error {
+	*r = Record{}
+	fields := r.hexFields()
+	d := wire.NewDecoder(data)
+	for {
+		f, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case f.Number == fieldVersion:
+			var v int64
+			v, err = f.Int()
+			r.Version = int(v)
+		case f.Number >= fieldCommitment && f.Number <= fieldStateProof:
+			*fields[f.Number-fieldCommitment].value, err = f.Hex()
+		default:
+			err = f.Unknown()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := protocol.Resolve(r.Version)
+	return err
+}
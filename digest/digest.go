@@ -1,34 +1,43 @@
 package digest
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/protocol"
 )
 
 // Digest is the struct for digest of a batch of transactions
 type Digest struct {
-	Data  string `json:"data"`
-	OrgID string `json:"org_id"`
+	// Version is the protocol version, absent in version 1 digests
+	Version   int    `json:"version,omitempty"`
+	Data      string `json:"data"`
+	OrgID     string `json:"org_id"`
+	Signature string `json:"sig,omitempty"`
 }
 
 // NewDigest creates a new digest for the input data and organization ID
 func NewDigest(data []byte, orgID string) *Digest {
 	return &Digest{
-		Data:  hex.EncodeToString(data),
-		OrgID: orgID,
+		Version: protocol.CurrentVersion,
+		Data:    hex.EncodeToString(data),
+		OrgID:   orgID,
 	}
 }
 
 // KeyVal returns the key-value pair of the digest to be recorded on-chain
 func (d *Digest) KeyVal() (string, []byte, error) {
+	return d.KeyValWith(hashsuite.Default())
+}
+
+// KeyValWith returns the key-value pair as KeyVal, hashing the key with the suite
+func (d *Digest) KeyValWith(suite *hashsuite.Suite) (string, []byte, error) {
 	digestJSON, err := json.Marshal(d)
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Hash := sha256.New()
-	sha256Hash.Write(digestJSON)
-	return hex.EncodeToString(sha256Hash.Sum(nil)), digestJSON, nil
+	return hex.EncodeToString(suite.Sum(hashsuite.Key, digestJSON)), digestJSON, nil
 }
 
 // Reveal reveals the byte data of a digest
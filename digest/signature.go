@@ -0,0 +1,72 @@
+package digest
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/schnorr"
+)
+
+// signatureDomain separates digest signatures from other messages signed with the same key
+const signatureDomain = "auti-core/digest/signature"
+
+var (
+	// ErrUnsigned is returned when verifying a digest that carries no signature
+	ErrUnsigned = errors.New("digest: unsigned digest")
+	// ErrInvalidSignature is returned when the signature does not verify against the public key
+	ErrInvalidSignature = errors.New("digest: invalid signature")
+)
+
+// KeyResolver looks up the public key of an organization by its ID, such as an identity.Registry
+type KeyResolver interface {
+	PublicKey(orgID string) (*ed25519.Point, error)
+}
+
+// Sign signs the digest with the private key of the organization
+func (d *Digest) Sign(privateKey *ed25519.Scalar) error {
+	signature, err := schnorr.Sign(privateKey, d.signedBytes())
+	if err != nil {
+		return err
+	}
+	d.Signature = hex.EncodeToString(signature)
+	return nil
+}
+
+// Verify verifies the signature of the digest against the public key of the organization
+func (d *Digest) Verify(publicKey *ed25519.Point) error {
+	if d.Signature == "" {
+		return ErrUnsigned
+	}
+	signature, err := hex.DecodeString(d.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if err = schnorr.Verify(publicKey, d.signedBytes(), signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyWith verifies the signature of the digest against the public key of its organization looked up in keys
+func (d *Digest) VerifyWith(keys KeyResolver) error {
+	publicKey, err := keys.PublicKey(d.OrgID)
+	if err != nil {
+		return err
+	}
+	return d.Verify(publicKey)
+}
+
+// signedBytes returns the message covered by the signature, all fields except the signature itself,
+// and the version but in version 1 digests
+func (d *Digest) signedBytes() []byte {
+	var buf []byte
+	buf = append(buf, protocol.SignatureDomain(signatureDomain, d.Version)...)
+	for _, field := range []string{d.Data, d.OrgID} {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+	return buf
+}
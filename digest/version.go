@@ -0,0 +1,35 @@
+package digest
+
+import (
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// DecodeDigest decodes a digest of any supported protocol version. A digest holds a Merkle root and no commitment,
+// so its layout has not changed since version 2 added the version field.
+func DecodeDigest(data []byte) (*Digest, error) {
+	d := new(Digest)
+	if _, err := protocol.Decode(data, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ProtocolVersion returns the protocol version of the digest
+func (d *Digest) ProtocolVersion() (int, error) {
+	return protocol.Resolve(d.Version)
+}
+
+// Upgrade returns a copy of the digest at the current protocol version, which any digest upgrades to.
+// An older digest loses its signature, which does not cover the new version, and the organization signs it again.
+func (d *Digest) Upgrade() (*Digest, error) {
+	field, changed, err := protocol.Upgrade(d.Version, false)
+	if err != nil {
+		return nil, err
+	}
+	upgraded := *d
+	if changed {
+		upgraded.Version = field
+		upgraded.Signature = ""
+	}
+	return &upgraded, nil
+}
@@ -0,0 +1,62 @@
+package digest
+
+import (
+	"errors"
+	"io"
+
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/wire"
+)
+
+// Field numbers of the binary encoding of digests
+const (
+	fieldVersion = iota + 1
+	fieldData
+	fieldOrgID
+	fieldSignature
+)
+
+// MarshalBinary encodes the digest in the binary wire format, with the hex fields as bytes
+func (d *Digest) MarshalBinary() ([]byte, error) {
+	buf := wire.AppendInt(nil, fieldVersion, int64(d.Version))
+	buf, err := wire.AppendHex(buf, fieldData, d.Data)
+	if err != nil {
+		return nil, err
+	}
+	buf = wire.AppendString(buf, fieldOrgID, d.OrgID)
+	return wire.AppendHex(buf, fieldSignature, d.Signature)
+}
+
+// UnmarshalBinary decodes a digest of MarshalBinary of a supported protocol version
+func (d *Digest) UnmarshalBinary(data []byte) error {
+	*d = Digest{}
+	decoder := wire.NewDecoder(data)
+	for {
+		f, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch f.Number {
+		case fieldVersion:
+			var v int64
+			v, err = f.Int()
+			d.Version = int(v)
+		case fieldData:
+			d.Data, err = f.Hex()
+		case fieldOrgID:
+			d.OrgID, err = f.Text()
+		case fieldSignature:
+			d.Signature, err = f.Hex()
+		default:
+			err = f.Unknown()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := protocol.Resolve(d.Version)
+	return err
+}
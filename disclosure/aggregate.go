@@ -0,0 +1,111 @@
+package disclosure
+
+import (
+	"errors"
+	"fmt"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/digest"
+	"github.com/auti-project/auti-core/merkle"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// ErrAggregateMismatch is returned when the selected commitments do not open to the claimed total
+var ErrAggregateMismatch = errors.New("disclosure: the commitments do not open to the total")
+
+// AggregateProof proves that the sum of the commitments of selected transactions in a Merkle tree opens to a total.
+// It reveals the commitments, which are on chain anyway, and the aggregate blinding, but no amount of a transaction.
+// It does not prove that the selection is complete.
+type AggregateProof struct {
+	// Commitments are the leaf data of the selected transactions, as serialized by transaction.Hidden
+	Commitments [][]byte
+	Inclusions  []*merkle.InclusionProof
+	Total       int64
+	Blinding    *ed25519.Scalar
+}
+
+// ProveAggregate proves the total of the transactions at the indices of the tree, with their openings
+func ProveAggregate(tree *merkle.Tree, txs []*transaction.Hidden, indices []uint64,
+	openings []*commitment.Opening) (*AggregateProof, error) {
+	if len(txs) == 0 || len(txs) != len(indices) || len(txs) != len(openings) {
+		return nil, fmt.Errorf("disclosure: got %d transactions, %d indices and %d openings",
+			len(txs), len(indices), len(openings))
+	}
+	root := tree.Root()
+	proof := &AggregateProof{
+		Commitments: make([][]byte, len(txs)),
+		Inclusions:  make([]*merkle.InclusionProof, len(txs)),
+	}
+	sum := commitment.NewOpening()
+	for i, tx := range txs {
+		data, err := tx.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		inclusion, err := tree.ProveInclusion(indices[i])
+		if err != nil {
+			return nil, err
+		}
+		if err = merkle.VerifyInclusion(root, data, inclusion); err != nil {
+			return nil, fmt.Errorf("disclosure: transaction %d is not leaf %d: %w", i, indices[i], err)
+		}
+		proof.Commitments[i], proof.Inclusions[i] = data, inclusion
+		sum.Add(sum, openings[i])
+	}
+	total, ok := sum.AmountInt64()
+	if !ok {
+		return nil, errors.New("disclosure: the total overflows int64")
+	}
+	proof.Total, proof.Blinding = total, sum.Blinding
+	return proof, nil
+}
+
+// Verify verifies that the commitments are distinct leaves of the tree of the root,
+// and that their sum is Total * G + Blinding * H
+func (p *AggregateProof) Verify(root []byte, g, h *ed25519.Point) error {
+	if len(p.Commitments) == 0 || len(p.Commitments) != len(p.Inclusions) {
+		return fmt.Errorf("disclosure: got %d commitments and %d inclusion proofs",
+			len(p.Commitments), len(p.Inclusions))
+	}
+	// a leaf counted twice would inflate the total
+	seen := make(map[uint64]struct{}, len(p.Inclusions))
+	sum := ed25519.NewIdentityPoint()
+	for i, c := range p.Commitments {
+		inclusion := p.Inclusions[i]
+		if inclusion.Size != p.Inclusions[0].Size {
+			return fmt.Errorf("disclosure: inclusion proofs of trees of %d and %d leaves",
+				p.Inclusions[0].Size, inclusion.Size)
+		}
+		if _, ok := seen[inclusion.Index]; ok {
+			return fmt.Errorf("disclosure: leaf %d selected twice", inclusion.Index)
+		}
+		seen[inclusion.Index] = struct{}{}
+		if err := merkle.VerifyInclusion(root, c, inclusion); err != nil {
+			return fmt.Errorf("disclosure: leaf %d: %w", inclusion.Index, err)
+		}
+		point, err := new(ed25519.Point).SetBytes(c)
+		if err != nil {
+			return err
+		}
+		sum.Add(sum, point)
+	}
+	amount, err := commitment.AmountScalar(p.Total)
+	if err != nil {
+		return err
+	}
+	expected, _ := new(ed25519.Point).SetBytes((&commitment.Opening{Amount: amount, Blinding: p.Blinding}).Commit(g, h))
+	if sum.Equal(expected) != 1 {
+		return ErrAggregateMismatch
+	}
+	return nil
+}
+
+// VerifyDigest verifies the proof against a digest whose data is the Merkle root of the transactions
+func (p *AggregateProof) VerifyDigest(d *digest.Digest, g, h *ed25519.Point) error {
+	root, err := d.Reveal()
+	if err != nil {
+		return err
+	}
+	return p.Verify(root, g, h)
+}
@@ -0,0 +1,92 @@
+package disclosure
+
+import (
+	"errors"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/digest"
+	"github.com/auti-project/auti-core/merkle"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+func TestAggregateProof_Verify(t *testing.T) {
+	g, h := generators(t)
+	amounts := []int64{120, 35, -40, 500, 7, 64}
+	txs := make([]*transaction.Hidden, len(amounts))
+	openings := make([]*commitment.Opening, len(amounts))
+	var err error
+	for i, amount := range amounts {
+		p := transaction.NewPlain("org1", "org2", amount)
+		p.Timestamp = testTimestamp + int64(i)
+		if txs[i], err = p.Hide(uint64(i), g, h, false); err != nil {
+			t.Fatalf("Hide() error = %v", err)
+		}
+		if openings[i], err = commitment.Open(amount, p.Timestamp, uint64(i), false); err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+	}
+	tree, err := merkle.NewTreeFromBlocks(txs)
+	if err != nil {
+		t.Fatalf("NewTreeFromBlocks() error = %v", err)
+	}
+	d := digest.NewDigest(tree.Root(), "org1")
+	proof, err := ProveAggregate(tree, []*transaction.Hidden{txs[1], txs[3], txs[4]}, []uint64{1, 3, 4},
+		[]*commitment.Opening{openings[1], openings[3], openings[4]})
+	if err != nil {
+		t.Fatalf("ProveAggregate() error = %v", err)
+	}
+	if proof.Total != 542 {
+		t.Errorf("ProveAggregate() total = %d, want 542", proof.Total)
+	}
+	if _, err = ProveAggregate(tree, txs[:1], []uint64{2}, openings[:1]); !errors.Is(err, merkle.ErrInvalidProof) {
+		t.Errorf("ProveAggregate() of a transaction at another index error = %v, want %v", err, merkle.ErrInvalidProof)
+	}
+
+	mutate := func(f func(p *AggregateProof)) *AggregateProof {
+		c := *proof
+		c.Commitments = append([][]byte{}, proof.Commitments...)
+		c.Inclusions = append([]*merkle.InclusionProof{}, proof.Inclusions...)
+		f(&c)
+		return &c
+	}
+	otherTree := merkle.NewTree([][]byte{proof.Commitments[0]})
+	tests := []struct {
+		name      string
+		proof     *AggregateProof
+		digest    *digest.Digest
+		wantErr   bool
+		wantErrIs error
+	}{
+		{"Test_Valid", proof, d, false, nil},
+		{"Test_Other_Total", mutate(func(p *AggregateProof) { p.Total++ }), d, true, ErrAggregateMismatch},
+		{"Test_Other_Blinding", mutate(func(p *AggregateProof) { p.Blinding = openings[0].Blinding }), d,
+			true, ErrAggregateMismatch},
+		{"Test_Dropped_Commitment", mutate(func(p *AggregateProof) {
+			p.Commitments, p.Inclusions = p.Commitments[1:], p.Inclusions[1:]
+		}), d, true, ErrAggregateMismatch},
+		{"Test_Other_Digest", proof, digest.NewDigest(otherTree.Root(), "org1"), true, merkle.ErrInvalidProof},
+		{"Test_Commitment_Not_In_The_Tree", mutate(func(p *AggregateProof) {
+			p.Commitments[0] = ed25519.NewGeneratorPoint().Bytes()
+		}), d, true, merkle.ErrInvalidProof},
+		{"Test_Double_Counted", mutate(func(p *AggregateProof) {
+			p.Commitments = append(p.Commitments, p.Commitments[2])
+			p.Inclusions = append(p.Inclusions, p.Inclusions[2])
+			p.Total += amounts[4]
+			p.Blinding = ed25519.NewScalar().Add(p.Blinding, openings[4].Blinding)
+		}), d, true, nil},
+		{"Test_Mismatched_Inclusions", mutate(func(p *AggregateProof) { p.Inclusions = p.Inclusions[1:] }), d, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.proof.VerifyDigest(tt.digest, g, h)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("VerifyDigest() error = %v, wantErr %v", err, tt.wantErrIs)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package disclosure
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// associatedDomain separates the associated data of disclosures from other messages
+const associatedDomain = "auti-core/disclosure"
+
+var (
+	// ErrKeyMismatch is returned when a disclosure is verified against another on-chain transaction
+	ErrKeyMismatch = errors.New("disclosure: the disclosure is bound to another transaction")
+	// ErrCommitmentMismatch is returned when the disclosed opening does not open the on-chain commitment
+	ErrCommitmentMismatch = errors.New("disclosure: the opening does not match the commitment")
+	// ErrTimestampMismatch is returned when the disclosed timestamp is not the on-chain timestamp
+	ErrTimestampMismatch = errors.New("disclosure: the timestamp does not match the transaction")
+)
+
+// Opening is the secret of a transaction commitment disclosed to an auditor.
+// The blinding is either derived from the timestamp and the counter, as in commitment.Commit, or given directly.
+type Opening struct {
+	Amount     int64
+	Timestamp  int64
+	Counter    uint64
+	NegateHash bool
+	// Blinding replaces the counter when it is set
+	Blinding *ed25519.Scalar
+}
+
+// openingJSON is the plaintext form of an opening
+type openingJSON struct {
+	Amount     int64  `json:"amount"`
+	Timestamp  int64  `json:"timestamp"`
+	Counter    uint64 `json:"counter,omitempty"`
+	NegateHash bool   `json:"negate_hash,omitempty"`
+	Blinding   string `json:"blinding,omitempty"`
+}
+
+// Commit recomputes the commitment of the opening, with the default hash suite
+func (o *Opening) Commit(g, h *ed25519.Point) ([]byte, error) {
+	return o.CommitWith(commitment.NewParams(g, h))
+}
+
+// CommitWith recomputes the commitment of the opening, with the generators and the hash suite of the parameters
+func (o *Opening) CommitWith(params *commitment.Params) ([]byte, error) {
+	if o.Blinding == nil {
+		return commitment.CommitWith(params, o.Amount, o.Timestamp, o.Counter, o.NegateHash)
+	}
+	amount, err := commitment.AmountScalar(o.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return (&commitment.Opening{Amount: amount, Blinding: o.Blinding}).Commit(params.G, params.H), nil
+}
+
+func (o *Opening) marshal() ([]byte, error) {
+	s := openingJSON{
+		Amount:     o.Amount,
+		Timestamp:  o.Timestamp,
+		Counter:    o.Counter,
+		NegateHash: o.NegateHash,
+	}
+	if o.Blinding != nil {
+		s.Blinding = hex.EncodeToString(o.Blinding.Bytes())
+	}
+	return json.Marshal(s)
+}
+
+func unmarshalOpening(data []byte) (*Opening, error) {
+	var s openingJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	o := &Opening{
+		Amount:     s.Amount,
+		Timestamp:  s.Timestamp,
+		Counter:    s.Counter,
+		NegateHash: s.NegateHash,
+	}
+	if s.Blinding != "" {
+		blindingBytes, err := hex.DecodeString(s.Blinding)
+		if err != nil {
+			return nil, err
+		}
+		if o.Blinding, err = ed25519.NewScalar().SetCanonicalBytes(blindingBytes); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Disclosure is an opening encrypted to an auditor, bound to the KeyVal key of the on-chain transaction
+type Disclosure struct {
+	Key        string `json:"key"`
+	Ephemeral  string `json:"ephemeral"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Disclose encrypts the opening of the on-chain transaction to the public key of the auditor,
+// binding it to the key of the transaction with the default hash suite
+func Disclose(tx *transaction.OnChain, opening *Opening, auditorPublicKey *ed25519.Point) (*Disclosure, error) {
+	return DiscloseWith(hashsuite.Default(), tx, opening, auditorPublicKey)
+}
+
+// DiscloseWith encrypts the opening as Disclose, binding it to the key of the transaction with the hash suite
+func DiscloseWith(suite *hashsuite.Suite, tx *transaction.OnChain, opening *Opening,
+	auditorPublicKey *ed25519.Point) (*Disclosure, error) {
+	key, _, err := tx.KeyValWith(suite)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := opening.marshal()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, ciphertext, err := encrypt(auditorPublicKey, plaintext, associatedData(key))
+	if err != nil {
+		return nil, err
+	}
+	return &Disclosure{
+		Key:        key,
+		Ephemeral:  hex.EncodeToString(ephemeral),
+		Ciphertext: hex.EncodeToString(ciphertext),
+	}, nil
+}
+
+// Decrypt decrypts the opening with the private key of the auditor, without checking it against the transaction
+func (d *Disclosure) Decrypt(privateKey *ed25519.Scalar) (*Opening, error) {
+	ephemeral, err := hex.DecodeString(d.Ephemeral)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	ciphertext, err := hex.DecodeString(d.Ciphertext)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	plaintext, err := decrypt(privateKey, ephemeral, ciphertext, associatedData(d.Key))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOpening(plaintext)
+}
+
+// Verify decrypts the opening with the private key of the auditor, and checks it against the on-chain transaction
+// by recomputing its commitment, with the default hash suite
+func (d *Disclosure) Verify(tx *transaction.OnChain, privateKey *ed25519.Scalar, g, h *ed25519.Point) (*Opening, error) {
+	return d.VerifyWith(commitment.NewParams(g, h), tx, privateKey)
+}
+
+// VerifyWith verifies the disclosure as Verify, with the generators and the hash suite of the parameters
+func (d *Disclosure) VerifyWith(params *commitment.Params, tx *transaction.OnChain,
+	privateKey *ed25519.Scalar) (*Opening, error) {
+	key, _, err := tx.KeyValWith(params.Suite)
+	if err != nil {
+		return nil, err
+	}
+	if key != d.Key {
+		return nil, ErrKeyMismatch
+	}
+	opening, err := d.Decrypt(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := strconv.ParseInt(tx.Timestamp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if opening.Timestamp != timestamp {
+		return nil, ErrTimestampMismatch
+	}
+	got, err := hex.DecodeString(tx.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	want, err := opening.CommitWith(params)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(got, want) {
+		return nil, ErrCommitmentMismatch
+	}
+	return opening, nil
+}
+
+func associatedData(key string) []byte {
+	return append([]byte(associatedDomain), key...)
+}
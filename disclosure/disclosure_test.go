@@ -0,0 +1,168 @@
+package disclosure
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+const testTimestamp = 1672531200000000000
+
+// generators returns the generators of the commitments, of which the discrete log of h with respect to g is unknown
+func generators(t *testing.T) (g, h *ed25519.Point) {
+	t.Helper()
+	g, err := autied25519.HashToPoint([]byte("auti-core disclosure test g"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	h, err = autied25519.HashToPoint([]byte("auti-core disclosure test h"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	return g, h
+}
+
+func TestDisclosure_Verify(t *testing.T) {
+	g, h := generators(t)
+	auditorPublicKey, auditorPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	_, otherPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	p := transaction.NewPlain("org1", "org2", 1500)
+	p.Timestamp = testTimestamp
+	h1, h2, err := p.HidePair(7, g, h)
+	if err != nil {
+		t.Fatalf("HidePair() error = %v", err)
+	}
+	tx, otherTX := h1.ToOnChain(), h2.ToOnChain()
+	blinding, err := commitment.BlindingScalar(testTimestamp, 7)
+	if err != nil {
+		t.Fatalf("BlindingScalar() error = %v", err)
+	}
+
+	disclose := func(tx *transaction.OnChain, opening *Opening) *Disclosure {
+		d, err := Disclose(tx, opening, auditorPublicKey)
+		if err != nil {
+			t.Fatalf("Disclose() error = %v", err)
+		}
+		return d
+	}
+	byCounter := &Opening{Amount: 1500, Timestamp: testTimestamp, Counter: 7}
+	byBlinding := &Opening{Amount: 1500, Timestamp: testTimestamp, Blinding: blinding}
+	pairByCounter := &Opening{Amount: -1500, Timestamp: testTimestamp, Counter: 7, NegateHash: true}
+	valid := disclose(tx, byCounter)
+	rebound := *disclose(otherTX, pairByCounter)
+	rebound.Key = valid.Key
+	tampered := *valid
+	ciphertext, err := hex.DecodeString(tampered.Ciphertext)
+	if err != nil {
+		t.Fatalf("hex.DecodeString() error = %v", err)
+	}
+	ciphertext[0] ^= 1
+	tampered.Ciphertext = hex.EncodeToString(ciphertext)
+	tests := []struct {
+		name       string
+		disclosure *Disclosure
+		tx         *transaction.OnChain
+		privateKey *ed25519.Scalar
+		want       *Opening
+		wantErr    error
+	}{
+		{"Test_Counter", valid, tx, auditorPrivateKey, byCounter, nil},
+		{"Test_Blinding", disclose(tx, byBlinding), tx, auditorPrivateKey, byBlinding, nil},
+		{"Test_Pair", disclose(otherTX, pairByCounter), otherTX, auditorPrivateKey, pairByCounter, nil},
+		{"Test_Other_Auditor", valid, tx, otherPrivateKey, nil, ErrDecryption},
+		{"Test_Other_Transaction", valid, otherTX, auditorPrivateKey, nil, ErrKeyMismatch},
+		{"Test_Rebound", &rebound, tx, auditorPrivateKey, nil, ErrDecryption},
+		{"Test_Tampered", &tampered, tx, auditorPrivateKey, nil, ErrDecryption},
+		{"Test_Wrong_Amount", disclose(tx, &Opening{Amount: 1499, Timestamp: testTimestamp, Counter: 7}), tx,
+			auditorPrivateKey, nil, ErrCommitmentMismatch},
+		{"Test_Wrong_Counter", disclose(tx, &Opening{Amount: 1500, Timestamp: testTimestamp, Counter: 8}), tx,
+			auditorPrivateKey, nil, ErrCommitmentMismatch},
+		{"Test_Wrong_Timestamp", disclose(tx, &Opening{Amount: 1500, Timestamp: testTimestamp + 1, Counter: 7}), tx,
+			auditorPrivateKey, nil, ErrTimestampMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.disclosure.Verify(tt.tx, tt.privateKey, g, h)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verify() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisclosure_VerifyWith(t *testing.T) {
+	g, h := generators(t)
+	params := &commitment.Params{G: g, H: h, Suite: hashsuite.BLAKE2b_256}
+	auditorPublicKey, auditorPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	p := transaction.NewPlain("org1", "org2", 1500)
+	p.Timestamp = testTimestamp
+	hidden, err := p.HideWith(7, params, false)
+	if err != nil {
+		t.Fatalf("HideWith() error = %v", err)
+	}
+	tx := hidden.ToOnChain()
+	opening := &Opening{Amount: 1500, Timestamp: testTimestamp, Counter: 7}
+	d, err := DiscloseWith(params.Suite, tx, opening, auditorPublicKey)
+	if err != nil {
+		t.Fatalf("DiscloseWith() error = %v", err)
+	}
+	if got, err := d.VerifyWith(params, tx, auditorPrivateKey); err != nil || !reflect.DeepEqual(got, opening) {
+		t.Errorf("VerifyWith() = %v, %v, want %v", got, err, opening)
+	}
+	// the key of the default suite is another key
+	if _, err = d.Verify(tx, auditorPrivateKey, g, h); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Verify() error = %v, wantErr %v", err, ErrKeyMismatch)
+	}
+}
+
+func TestDisclose(t *testing.T) {
+	tx := transaction.NewOnChain("00", "01", "02", "", "0")
+	opening := &Opening{Amount: 1}
+	auditorPublicKey, _, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	// a point of order 2, whose shared secrets are predictable
+	torsionBytes := bytes.Repeat([]byte{0xff}, 32)
+	torsionBytes[0], torsionBytes[31] = 0xec, 0x7f
+	torsion, err := new(ed25519.Point).SetBytes(torsionBytes)
+	if err != nil {
+		t.Fatalf("SetBytes() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		publicKey *ed25519.Point
+		wantErr   error
+	}{
+		{"Test_Valid", auditorPublicKey, nil},
+		{"Test_Identity", ed25519.NewIdentityPoint(), ErrInvalidPublicKey},
+		{"Test_Small_Order", torsion, ErrInvalidPublicKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Disclose(tx, opening, tt.publicKey); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Disclose() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
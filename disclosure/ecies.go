@@ -0,0 +1,80 @@
+package disclosure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/vss"
+)
+
+// keyDomain separates the encryption keys of disclosures from other hashes
+const keyDomain = "auti-core/disclosure/key"
+
+var (
+	// ErrInvalidPublicKey is returned when encrypting to a public key of small order, which has no secret
+	ErrInvalidPublicKey = errors.New("disclosure: invalid public key")
+	// ErrDecryption is returned when a ciphertext does not decrypt under the private key and the associated data
+	ErrDecryption = errors.New("disclosure: decryption failed")
+)
+
+// encrypt encrypts the plaintext to the public key with ECIES over Edwards25519 and AES-256-GCM,
+// and returns the ephemeral public key and the ciphertext
+func encrypt(publicKey *ed25519.Point, plaintext, associatedData []byte) ([]byte, []byte, error) {
+	if isSmallOrder(publicKey) {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	ephemeralKey, err := vss.RandomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	ephemeral := new(ed25519.Point).ScalarBaseMult(ephemeralKey)
+	shared := new(ed25519.Point).ScalarMult(ephemeralKey, publicKey)
+	aead, err := newAEAD(ephemeral, publicKey, shared)
+	if err != nil {
+		return nil, nil, err
+	}
+	// every key encrypts a single message, so the nonce is fixed
+	nonce := make([]byte, aead.NonceSize())
+	return ephemeral.Bytes(), aead.Seal(nil, nonce, plaintext, associatedData), nil
+}
+
+// decrypt decrypts a ciphertext of encrypt with the private key
+func decrypt(privateKey *ed25519.Scalar, ephemeralBytes, ciphertext, associatedData []byte) ([]byte, error) {
+	ephemeral, err := new(ed25519.Point).SetBytes(ephemeralBytes)
+	if err != nil || isSmallOrder(ephemeral) {
+		return nil, ErrDecryption
+	}
+	publicKey := new(ed25519.Point).ScalarBaseMult(privateKey)
+	shared := new(ed25519.Point).ScalarMult(privateKey, ephemeral)
+	aead, err := newAEAD(ephemeral, publicKey, shared)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return plaintext, nil
+}
+
+// newAEAD derives the AES-256-GCM key of the shared secret, bound to both public keys
+func newAEAD(ephemeral, publicKey, shared *ed25519.Point) (cipher.AEAD, error) {
+	hashFunc := sha256.New()
+	hashFunc.Write([]byte(keyDomain))
+	hashFunc.Write(ephemeral.Bytes())
+	hashFunc.Write(publicKey.Bytes())
+	hashFunc.Write(shared.Bytes())
+	block, err := aes.NewCipher(hashFunc.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func isSmallOrder(p *ed25519.Point) bool {
+	return new(ed25519.Point).MultByCofactor(p).Equal(ed25519.NewIdentityPoint()) == 1
+}
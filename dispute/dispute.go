@@ -0,0 +1,383 @@
+package dispute
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/auditing"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/identity"
+)
+
+// Types of the auditing records of a dispute
+const (
+	RecordTypeChallenge = 16
+	RecordTypeResponse  = 17
+	RecordTypeVerdict   = 18
+)
+
+var (
+	// ErrWrongState is returned when a dispute does not accept the step in its current state
+	ErrWrongState = errors.New("dispute: wrong state")
+	// ErrDeadlinePassed is returned when responding after the deadline of the challenge
+	ErrDeadlinePassed = errors.New("dispute: deadline passed")
+	// ErrPending is returned when adjudicating an unanswered challenge before its deadline
+	ErrPending = errors.New("dispute: response pending")
+	// ErrMismatch is returned when a response or record does not belong to the dispute
+	ErrMismatch = errors.New("dispute: mismatched record")
+	// ErrNotAuditor is returned when a challenge or verdict is recorded by an entity without the auditor role
+	ErrNotAuditor = errors.New("dispute: signer is not an auditor")
+)
+
+// Challenge is the challenge of an auditor to an organization, to open or prove the balance
+// of the residual commitment of a chain in an epoch whose sum-check failed
+type Challenge struct {
+	AuditorID string
+	OrgID     string
+	ChainID   string
+	Epoch     uint64
+	// Commitment is the challenged commitment, the Residual of the chain
+	Commitment []byte
+	// Deadline is the last timestamp, in the unit of the transaction timestamps, to respond at
+	Deadline int64
+	// Suite is the hash suite of the deployment, which hashes the ID of the challenge.
+	// A nil suite is the default suite.
+	Suite *hashsuite.Suite
+}
+
+// challengeJSON is the payload of a challenge record
+type challengeJSON struct {
+	AuditorID  string `json:"auditor_id"`
+	OrgID      string `json:"org_id"`
+	ChainID    string `json:"chain_id"`
+	Epoch      uint64 `json:"epoch"`
+	Commitment string `json:"commit"`
+	Deadline   int64  `json:"deadline"`
+}
+
+func (c *Challenge) payload() ([]byte, error) {
+	return json.Marshal(challengeJSON{
+		AuditorID:  c.AuditorID,
+		OrgID:      c.OrgID,
+		ChainID:    c.ChainID,
+		Epoch:      c.Epoch,
+		Commitment: hex.EncodeToString(c.Commitment),
+		Deadline:   c.Deadline,
+	})
+}
+
+// ID returns the ID of the challenge, the hex key hash of its payload with the suite of the challenge,
+// the SHA-256 hash of the payload with the default suite
+func (c *Challenge) ID() (string, error) {
+	payload, err := c.payload()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(c.Suite.Sum(hashsuite.Key, payload)), nil
+}
+
+// Record returns the challenge as an auditing record signed by the auditor
+func (c *Challenge) Record(privateKey *ed25519.Scalar) (*auditing.Record, error) {
+	payload, err := c.payload()
+	if err != nil {
+		return nil, err
+	}
+	return signedRecord(payload, RecordTypeChallenge, c.AuditorID, privateKey)
+}
+
+// Registry resolves the keys and the roles of the organizations and auditors, such as an identity.Registry
+type Registry interface {
+	auditing.KeyResolver
+	Lookup(id string) (*identity.Entity, bool)
+}
+
+// ChallengeFromRecord verifies a challenge record against the key of its auditor and returns the challenge,
+// of the default hash suite. The signer must have the auditor role.
+func ChallengeFromRecord(r *auditing.Record, registry Registry) (*Challenge, error) {
+	return ChallengeFromRecordWith(hashsuite.Default(), r, registry)
+}
+
+// ChallengeFromRecordWith returns the challenge of a record as ChallengeFromRecord, of the hash suite
+func ChallengeFromRecordWith(suite *hashsuite.Suite, r *auditing.Record, registry Registry) (*Challenge, error) {
+	var s challengeJSON
+	if err := parseRecord(r, RecordTypeChallenge, registry, &s); err != nil {
+		return nil, err
+	}
+	if s.AuditorID != r.OrgID {
+		return nil, fmt.Errorf("%w: challenge of auditor %q recorded by %q", ErrMismatch, s.AuditorID, r.OrgID)
+	}
+	if err := checkAuditor(r, registry); err != nil {
+		return nil, err
+	}
+	commitmentBytes, err := hex.DecodeString(s.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	return &Challenge{
+		AuditorID:  s.AuditorID,
+		OrgID:      s.OrgID,
+		ChainID:    s.ChainID,
+		Epoch:      s.Epoch,
+		Commitment: commitmentBytes,
+		Deadline:   s.Deadline,
+		Suite:      suite,
+	}, nil
+}
+
+// Response is the response of the organization to a challenge,
+// with either the opening of the challenged commitment or a zero-balance proof of it
+type Response struct {
+	ChallengeID string
+	OrgID       string
+	Opening     *commitment.Opening
+	Proof       []byte
+}
+
+// responseJSON is the payload of a response record
+type responseJSON struct {
+	ChallengeID string              `json:"challenge_id"`
+	OrgID       string              `json:"org_id"`
+	Opening     *commitment.Opening `json:"opening,omitempty"`
+	Proof       string              `json:"proof,omitempty"`
+}
+
+// NewOpeningResponse creates the response opening the challenged commitment
+func NewOpeningResponse(c *Challenge, opening *commitment.Opening) (*Response, error) {
+	id, err := c.ID()
+	if err != nil {
+		return nil, err
+	}
+	return &Response{ChallengeID: id, OrgID: c.OrgID, Opening: opening}, nil
+}
+
+// NewProofResponse creates the response proving that the challenged commitment, blinding * H, has a zero balance
+func NewProofResponse(c *Challenge, blinding *ed25519.Scalar, h *ed25519.Point) (*Response, error) {
+	id, err := c.ID()
+	if err != nil {
+		return nil, err
+	}
+	proof, err := ProveZeroBalance(c, blinding, h)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{ChallengeID: id, OrgID: c.OrgID, Proof: proof}, nil
+}
+
+// Record returns the response as an auditing record signed by the organization
+func (r *Response) Record(privateKey *ed25519.Scalar) (*auditing.Record, error) {
+	payload, err := json.Marshal(responseJSON{
+		ChallengeID: r.ChallengeID,
+		OrgID:       r.OrgID,
+		Opening:     r.Opening,
+		Proof:       hex.EncodeToString(r.Proof),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signedRecord(payload, RecordTypeResponse, r.OrgID, privateKey)
+}
+
+// ResponseFromRecord verifies a response record against the key of its organization and returns the response
+func ResponseFromRecord(r *auditing.Record, keys auditing.KeyResolver) (*Response, error) {
+	var s responseJSON
+	if err := parseRecord(r, RecordTypeResponse, keys, &s); err != nil {
+		return nil, err
+	}
+	if s.OrgID != r.OrgID {
+		return nil, fmt.Errorf("%w: response of %q recorded by %q", ErrMismatch, s.OrgID, r.OrgID)
+	}
+	proof, err := hex.DecodeString(s.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if len(proof) == 0 {
+		proof = nil
+	}
+	return &Response{ChallengeID: s.ChallengeID, OrgID: s.OrgID, Opening: s.Opening, Proof: proof}, nil
+}
+
+// Outcome is the outcome of a dispute
+type Outcome int
+
+const (
+	// OutcomeInnocent is the outcome when the organization shows that the challenged chain is balanced
+	OutcomeInnocent Outcome = iota + 1
+	// OutcomeGuilty is the outcome when the organization does not show it
+	OutcomeGuilty
+)
+
+// Reasons of the verdicts
+const (
+	ReasonBalanced       = "balanced"
+	ReasonNoResponse     = "no response before the deadline"
+	ReasonInvalidOpening = "the opening does not open the challenged commitment"
+	ReasonUnbalanced     = "the opened amount is not zero"
+	ReasonInvalidProof   = "invalid zero-balance proof"
+)
+
+// Verdict is the adjudicated outcome of a dispute
+type Verdict struct {
+	ChallengeID string  `json:"challenge_id"`
+	Outcome     Outcome `json:"outcome"`
+	Reason      string  `json:"reason"`
+}
+
+// Record returns the verdict as an auditing record signed by the auditor
+func (v *Verdict) Record(auditorID string, privateKey *ed25519.Scalar) (*auditing.Record, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return signedRecord(payload, RecordTypeVerdict, auditorID, privateKey)
+}
+
+// VerdictFromRecord verifies a verdict record against the key of its auditor and returns the verdict.
+// The signer must have the auditor role.
+func VerdictFromRecord(r *auditing.Record, registry Registry) (*Verdict, error) {
+	v := new(Verdict)
+	if err := parseRecord(r, RecordTypeVerdict, registry, v); err != nil {
+		return nil, err
+	}
+	if err := checkAuditor(r, registry); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// State is the state of a dispute
+type State int
+
+const (
+	// StateOpen is the state of a challenge awaiting its response
+	StateOpen State = iota
+	// StateResponded is the state of a responded challenge awaiting adjudication
+	StateResponded
+	// StateResolved is the state of an adjudicated dispute
+	StateResolved
+)
+
+// Dispute is the state machine of a challenge, from its response to its verdict.
+// The current time is passed to every step, so that the deadline is checked without a clock.
+type Dispute struct {
+	challenge *Challenge
+	id        string
+	g, h      *ed25519.Point
+	state     State
+	response  *Response
+	verdict   *Verdict
+}
+
+// New creates a new open dispute of the challenge, with the generators of the commitments,
+// of which the discrete log of h with respect to g must be unknown, see ProveZeroBalance
+func New(challenge *Challenge, g, h *ed25519.Point) (*Dispute, error) {
+	id, err := challenge.ID()
+	if err != nil {
+		return nil, err
+	}
+	return &Dispute{challenge: challenge, id: id, g: g, h: h}, nil
+}
+
+// Challenge returns the challenge of the dispute
+func (d *Dispute) Challenge() *Challenge {
+	return d.challenge
+}
+
+// State returns the state of the dispute
+func (d *Dispute) State() State {
+	return d.state
+}
+
+// Respond accepts the response of the organization before the deadline, it is checked on adjudication
+func (d *Dispute) Respond(response *Response, now int64) error {
+	if d.state != StateOpen {
+		return ErrWrongState
+	}
+	if now > d.challenge.Deadline {
+		return ErrDeadlinePassed
+	}
+	if response.ChallengeID != d.id || response.OrgID != d.challenge.OrgID {
+		return fmt.Errorf("%w: response to %s by %q", ErrMismatch, response.ChallengeID, response.OrgID)
+	}
+	if (response.Opening == nil) == (response.Proof == nil) {
+		return errors.New("dispute: a response has either an opening or a proof")
+	}
+	d.response = response
+	d.state = StateResponded
+	return nil
+}
+
+// Adjudicate decides the dispute. A challenge without a response is decided after its deadline,
+// and a decided dispute returns its verdict again.
+func (d *Dispute) Adjudicate(now int64) (*Verdict, error) {
+	switch d.state {
+	case StateResolved:
+		return d.verdict, nil
+	case StateOpen:
+		if now <= d.challenge.Deadline {
+			return nil, ErrPending
+		}
+		d.resolve(OutcomeGuilty, ReasonNoResponse)
+	case StateResponded:
+		d.resolve(d.judge())
+	}
+	return d.verdict, nil
+}
+
+// judge checks the response against the challenged commitment
+func (d *Dispute) judge() (Outcome, string) {
+	if d.response.Proof != nil {
+		if err := VerifyZeroBalance(d.challenge, d.response.Proof, d.h); err != nil {
+			return OutcomeGuilty, ReasonInvalidProof
+		}
+		return OutcomeInnocent, ReasonBalanced
+	}
+	// the opening is revealed in the response, so it is public
+	if err := d.response.Opening.VarTimeVerify(d.challenge.Commitment, d.g, d.h); err != nil {
+		return OutcomeGuilty, ReasonInvalidOpening
+	}
+	if d.response.Opening.Amount.Equal(ed25519.NewScalar()) != 1 {
+		return OutcomeGuilty, ReasonUnbalanced
+	}
+	return OutcomeInnocent, ReasonBalanced
+}
+
+func (d *Dispute) resolve(outcome Outcome, reason string) {
+	d.verdict = &Verdict{ChallengeID: d.id, Outcome: outcome, Reason: reason}
+	d.state = StateResolved
+}
+
+// signedRecord creates an auditing record of the payload signed by the organization or auditor
+func signedRecord(payload []byte, recordType int, id string, privateKey *ed25519.Scalar) (*auditing.Record, error) {
+	r := auditing.NewRecord(payload, recordType, id)
+	if err := r.Sign(privateKey); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// checkAuditor checks that the signer of a record has the auditor role
+func checkAuditor(r *auditing.Record, registry Registry) error {
+	if e, ok := registry.Lookup(r.OrgID); !ok || e.Role != identity.RoleAuditor {
+		return fmt.Errorf("%w: %q", ErrNotAuditor, r.OrgID)
+	}
+	return nil
+}
+
+// parseRecord verifies a record of the type against the key of its signer and decodes its payload into v
+func parseRecord(r *auditing.Record, recordType int, keys auditing.KeyResolver, v any) error {
+	if r.Type != recordType {
+		return fmt.Errorf("%w: record type %d, want %d", ErrMismatch, r.Type, recordType)
+	}
+	if err := r.VerifyWith(keys); err != nil {
+		return err
+	}
+	payload, err := r.Reveal()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
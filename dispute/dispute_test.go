@@ -0,0 +1,426 @@
+package dispute
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/auditing"
+	"github.com/auti-project/auti-core/commitment"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/identity"
+)
+
+const (
+	testTimestamp = 1672531200000000000
+	testDeadline  = testTimestamp + 1000
+)
+
+// generators returns the generators of the commitments, of which the discrete log of h with respect to g is unknown
+func generators(t *testing.T) (g, h *ed25519.Point) {
+	t.Helper()
+	g, err := autied25519.HashToPoint([]byte("auti-core dispute test g"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	h, err = autied25519.HashToPoint([]byte("auti-core dispute test h"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	return g, h
+}
+
+// chainOpenings returns the openings of the last commitment and the transactions of a chain
+func chainOpenings(t *testing.T) (*commitment.Opening, []*commitment.Opening) {
+	t.Helper()
+	last, err := commitment.Open(1000, testTimestamp-1, 0, false)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	var txs []*commitment.Opening
+	for i, amount := range []int64{250, -75, 30} {
+		tx, err := commitment.Open(amount, testTimestamp, uint64(i+1), amount < 0)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	return last, txs
+}
+
+// challengeOf builds the challenge of the residual of the chain with the current opening, and its opening
+func challengeOf(t *testing.T, g, h *ed25519.Point, current *commitment.Opening,
+	epoch uint64) (*Challenge, *commitment.Opening) {
+	t.Helper()
+	last, txs := chainOpenings(t)
+	added := make([][]byte, len(txs))
+	for i, tx := range txs {
+		added[i] = tx.Commit(g, h)
+	}
+	residual, err := Residual(last.Commit(g, h), current.Commit(g, h), added...)
+	if err != nil {
+		t.Fatalf("Residual() error = %v", err)
+	}
+	c := &Challenge{
+		AuditorID:  "auditor1",
+		OrgID:      "org1",
+		ChainID:    "chain1",
+		Epoch:      epoch,
+		Commitment: residual,
+		Deadline:   testDeadline,
+		Suite:      hashsuite.Default(),
+	}
+	return c, ResidualOpening(last, current, txs...)
+}
+
+func TestChallenge_ID(t *testing.T) {
+	g, h := generators(t)
+	c, _ := challengeOf(t, g, h, commitment.NewOpening(), 1)
+	payload, err := c.payload()
+	if err != nil {
+		t.Fatalf("payload() error = %v", err)
+	}
+	defaultHash := sha256.Sum256(payload)
+	tests := []struct {
+		name  string
+		suite *hashsuite.Suite
+		want  string
+	}{
+		{"Test_Nil_Suite", nil, hex.EncodeToString(defaultHash[:])},
+		{"Test_Default_Suite", hashsuite.Default(), hex.EncodeToString(defaultHash[:])},
+		{"Test_Sha3256", hashsuite.SHA3_256, hex.EncodeToString(hashsuite.SHA3_256.Sum(hashsuite.Key, payload))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suiteChallenge := *c
+			suiteChallenge.Suite = tt.suite
+			if got, err := suiteChallenge.ID(); err != nil || got != tt.want {
+				t.Errorf("ID() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispute_Adjudicate(t *testing.T) {
+	g, h := generators(t)
+	last, txs := chainOpenings(t)
+	// the current commitment of an honest organization, last + sum(txs)
+	honest := ResidualOpening(last, commitment.NewOpening(), txs...)
+	// the current commitment with a wrong blinding, as left by a reused counter
+	blinding, err := commitment.Open(0, testTimestamp, 42, false)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	reblinded := commitment.NewOpening().Add(honest, blinding)
+	// the current commitment inflating the balance
+	inflated, err := commitment.Open(1000, testTimestamp, 99, false)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	inflated.Add(inflated, honest)
+
+	type respond func(c *Challenge, opening *commitment.Opening) (*Response, error)
+	byOpening := func(c *Challenge, opening *commitment.Opening) (*Response, error) {
+		return NewOpeningResponse(c, opening)
+	}
+	byProof := func(c *Challenge, opening *commitment.Opening) (*Response, error) {
+		return NewProofResponse(c, opening.Blinding, h)
+	}
+	byWrongOpening := func(c *Challenge, opening *commitment.Opening) (*Response, error) {
+		return NewOpeningResponse(c, commitment.NewOpening())
+	}
+	tests := []struct {
+		name        string
+		current     *commitment.Opening
+		respond     respond
+		wantOutcome Outcome
+		wantReason  string
+	}{
+		{"Test_Honest_Opening", honest, byOpening, OutcomeInnocent, ReasonBalanced},
+		{"Test_Honest_Proof", honest, byProof, OutcomeInnocent, ReasonBalanced},
+		{"Test_Reblinded_Opening", reblinded, byOpening, OutcomeInnocent, ReasonBalanced},
+		{"Test_Reblinded_Proof", reblinded, byProof, OutcomeInnocent, ReasonBalanced},
+		{"Test_Inflated_Opening", inflated, byOpening, OutcomeGuilty, ReasonUnbalanced},
+		{"Test_Inflated_Proof", inflated, byProof, OutcomeGuilty, ReasonInvalidProof},
+		{"Test_Wrong_Opening", reblinded, byWrongOpening, OutcomeGuilty, ReasonInvalidOpening},
+		{"Test_No_Response", honest, nil, OutcomeGuilty, ReasonNoResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, opening := challengeOf(t, g, h, tt.current, 1)
+			d, err := New(c, g, h)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if tt.respond != nil {
+				response, err := tt.respond(c, opening)
+				if err != nil {
+					t.Fatalf("respond() error = %v", err)
+				}
+				if err = d.Respond(response, testTimestamp); err != nil {
+					t.Fatalf("Respond() error = %v", err)
+				}
+			} else if _, err = d.Adjudicate(testDeadline); !errors.Is(err, ErrPending) {
+				t.Fatalf("Adjudicate() error = %v, wantErr %v", err, ErrPending)
+			}
+			got, err := d.Adjudicate(testDeadline + 1)
+			if err != nil {
+				t.Fatalf("Adjudicate() error = %v", err)
+			}
+			if got.Outcome != tt.wantOutcome || got.Reason != tt.wantReason {
+				t.Errorf("Adjudicate() got = %v, want %v %q", got, tt.wantOutcome, tt.wantReason)
+			}
+			if d.State() != StateResolved {
+				t.Errorf("State() = %v, want %v", d.State(), StateResolved)
+			}
+			if again, _ := d.Adjudicate(testDeadline + 2); again != got {
+				t.Errorf("Adjudicate() of a resolved dispute changed the verdict")
+			}
+		})
+	}
+}
+
+func TestDispute_Respond(t *testing.T) {
+	g, h := generators(t)
+	last, txs := chainOpenings(t)
+	// a reblinded current commitment, so that the residual is not the identity
+	blinding, err := commitment.Open(0, testTimestamp, 42, false)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	current := ResidualOpening(last, commitment.NewOpening(), txs...)
+	current.Add(current, blinding)
+	c, opening := challengeOf(t, g, h, current, 1)
+	other, _ := challengeOf(t, g, h, current, 2)
+	valid, err := NewOpeningResponse(c, opening)
+	if err != nil {
+		t.Fatalf("NewOpeningResponse() error = %v", err)
+	}
+	replayed, err := NewProofResponse(other, opening.Blinding, h)
+	if err != nil {
+		t.Fatalf("NewProofResponse() error = %v", err)
+	}
+	replayed.ChallengeID = valid.ChallengeID
+	otherOrg := *valid
+	otherOrg.OrgID = "org2"
+	both := *replayed
+	both.Opening = opening
+	tests := []struct {
+		name        string
+		response    *Response
+		now         int64
+		wantErr     bool
+		wantErrIs   error
+		wantOutcome Outcome
+	}{
+		{"Test_Valid", valid, testDeadline, false, nil, OutcomeInnocent},
+		{"Test_Late", valid, testDeadline + 1, true, ErrDeadlinePassed, 0},
+		{"Test_Other_Challenge", &Response{ChallengeID: "00", OrgID: "org1", Opening: opening}, testTimestamp,
+			true, ErrMismatch, 0},
+		{"Test_Other_Organization", &otherOrg, testTimestamp, true, ErrMismatch, 0},
+		{"Test_Opening_And_Proof", &both, testTimestamp, true, nil, 0},
+		{"Test_Neither", &Response{ChallengeID: valid.ChallengeID, OrgID: "org1"}, testTimestamp, true, nil, 0},
+		// the proof is bound to the other challenge, which commits to the same residual
+		{"Test_Replayed_Proof", replayed, testTimestamp, false, nil, OutcomeGuilty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(c, g, h)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			err = d.Respond(tt.response, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Respond() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Respond() error = %v, wantErr %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err = d.Respond(tt.response, tt.now); !errors.Is(err, ErrWrongState) {
+				t.Errorf("Respond() twice error = %v, wantErr %v", err, ErrWrongState)
+			}
+			got, err := d.Adjudicate(tt.now)
+			if err != nil {
+				t.Fatalf("Adjudicate() error = %v", err)
+			}
+			if got.Outcome != tt.wantOutcome {
+				t.Errorf("Adjudicate() got = %v, want %v", got.Outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestRecords(t *testing.T) {
+	g, h := generators(t)
+	auditorPublicKey, auditorPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	orgPublicKey, orgPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	registry := identity.NewRegistry()
+	if err = registry.Add(identity.NewEntity("auditor1", "Auditor", auditorPublicKey, identity.RoleAuditor)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err = registry.Add(identity.NewEntity("org1", "Org", orgPublicKey, identity.RoleOrganization)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	last, txs := chainOpenings(t)
+	current := ResidualOpening(last, commitment.NewOpening(), txs...)
+	challenge, opening := challengeOf(t, g, h, current, 1)
+	challengeRecord, err := challenge.Record(auditorPrivateKey)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	gotChallenge, err := ChallengeFromRecord(challengeRecord, registry)
+	if err != nil {
+		t.Fatalf("ChallengeFromRecord() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotChallenge, challenge) {
+		t.Errorf("ChallengeFromRecord() got = %v, want %v", gotChallenge, challenge)
+	}
+
+	d, err := New(gotChallenge, g, h)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i, response := range []func() (*Response, error){
+		func() (*Response, error) { return NewOpeningResponse(challenge, opening) },
+		func() (*Response, error) { return NewProofResponse(challenge, opening.Blinding, h) },
+	} {
+		r, err := response()
+		if err != nil {
+			t.Fatalf("response() error = %v", err)
+		}
+		responseRecord, err := r.Record(orgPrivateKey)
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		got, err := ResponseFromRecord(responseRecord, registry)
+		if err != nil {
+			t.Fatalf("ResponseFromRecord() error = %v", err)
+		}
+		if i == 0 {
+			if got.Proof != nil || got.Opening.Verify(challenge.Commitment, g, h) != nil {
+				t.Errorf("ResponseFromRecord() does not round trip the opening")
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, r) {
+			t.Errorf("ResponseFromRecord() got = %v, want %v", got, r)
+		}
+		if err = d.Respond(got, testTimestamp); err != nil {
+			t.Fatalf("Respond() error = %v", err)
+		}
+	}
+	verdict, err := d.Adjudicate(testTimestamp)
+	if err != nil {
+		t.Fatalf("Adjudicate() error = %v", err)
+	}
+	verdictRecord, err := verdict.Record(challenge.AuditorID, auditorPrivateKey)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	gotVerdict, err := VerdictFromRecord(verdictRecord, registry)
+	if err != nil {
+		t.Fatalf("VerdictFromRecord() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotVerdict, verdict) || verdict.Outcome != OutcomeInnocent {
+		t.Errorf("VerdictFromRecord() got = %v, want innocent %v", gotVerdict, verdict)
+	}
+
+	forged, err := challenge.Record(orgPrivateKey)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	orgChallenge := *challenge
+	orgChallenge.AuditorID = "org1"
+	byOrganization, err := orgChallenge.Record(orgPrivateKey)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	impersonated := *challengeRecord
+	impersonated.OrgID = "org1"
+	if err = impersonated.Sign(orgPrivateKey); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		record  *auditing.Record
+		wantErr error
+	}{
+		{"Test_Forged", forged, auditing.ErrInvalidSignature},
+		{"Test_Impersonated", &impersonated, ErrMismatch},
+		{"Test_Wrong_Type", verdictRecord, ErrMismatch},
+		{"Test_Unknown_Signer", auditing.NewRecord(nil, RecordTypeChallenge, "auditor2"), identity.ErrUnknownEntity},
+		{"Test_Organization_Signer", byOrganization, ErrNotAuditor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ChallengeFromRecord(tt.record, registry); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChallengeFromRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	organizationVerdict, err := verdict.Record("org1", orgPrivateKey)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if _, err = VerdictFromRecord(organizationVerdict, registry); !errors.Is(err, ErrNotAuditor) {
+		t.Errorf("VerdictFromRecord() of an organization error = %v, wantErr %v", err, ErrNotAuditor)
+	}
+}
+
+func TestVerifyZeroBalance_KnownLog(t *testing.T) {
+	g, h := generators(t)
+	digest := sha512.Sum512([]byte("known log"))
+	k, err := ed25519.NewScalar().SetUniformBytes(digest[:])
+	if err != nil {
+		t.Fatalf("SetUniformBytes() error = %v", err)
+	}
+	inflated, err := commitment.Open(1000, testTimestamp, 99, false)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		h       *ed25519.Point
+		wantErr error
+	}{
+		// a prover knowing H = k * G proves that a * G + r * H = (a/k + r) * H has a zero balance
+		{"Test_Known_Log", new(ed25519.Point).ScalarMult(k, g), nil},
+		{"Test_Hash_To_Curve", h, ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Challenge{
+				AuditorID:  "auditor1",
+				OrgID:      "org1",
+				ChainID:    "chain1",
+				Epoch:      1,
+				Commitment: inflated.Commit(g, tt.h),
+				Deadline:   testDeadline,
+			}
+			forged := ed25519.NewScalar().Invert(k)
+			forged.MultiplyAdd(inflated.Amount, forged, inflated.Blinding)
+			proof, err := ProveZeroBalance(c, forged, tt.h)
+			if err != nil {
+				t.Fatalf("ProveZeroBalance() error = %v", err)
+			}
+			if err = VerifyZeroBalance(c, proof, tt.h); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyZeroBalance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
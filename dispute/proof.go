@@ -0,0 +1,126 @@
+package dispute
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+)
+
+// proofDomain separates the zero-balance proofs from other hashes
+const proofDomain = "auti-core/dispute/zero-balance"
+
+// ProofSize is the size of a zero-balance proof, R || s
+const ProofSize = 64
+
+// ErrInvalidProof is returned when a zero-balance proof does not verify
+var ErrInvalidProof = errors.New("dispute: invalid zero-balance proof")
+
+// Residual computes the commitment checked by the sum-check of a chain, last + sum(added) - current,
+// where added are the commitments of the transactions or of the epoch. It commits to a zero amount
+// when the chain is balanced, and is the identity when the sum-check of the chain passes.
+func Residual(last, current []byte, added ...[]byte) ([]byte, error) {
+	residual, err := new(ed25519.Point).SetBytes(last)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range added {
+		point, err := new(ed25519.Point).SetBytes(c)
+		if err != nil {
+			return nil, err
+		}
+		residual.Add(residual, point)
+	}
+	currentPoint, err := new(ed25519.Point).SetBytes(current)
+	if err != nil {
+		return nil, err
+	}
+	return residual.Subtract(residual, currentPoint).Bytes(), nil
+}
+
+// ResidualOpening computes the opening of the residual from the openings of its commitments
+func ResidualOpening(last, current *commitment.Opening, added ...*commitment.Opening) *commitment.Opening {
+	residual := commitment.NewOpening().Add(commitment.NewOpening(), last)
+	for _, o := range added {
+		residual.Add(residual, o)
+	}
+	return residual.Subtract(residual, current)
+}
+
+// ProveZeroBalance proves that the challenged commitment is blinding * H, so that it commits to a zero amount,
+// without revealing the blinding. The proof is a Schnorr proof of knowledge of the blinding with respect to H.
+// It runs in constant time in the blinding. The proof is sound only if nobody knows the discrete log of H
+// with respect to G: with H = k * G, any commitment a * G + r * H is (a/k + r) * H, which proves a zero
+// balance whatever the amount. The generators of a deployment must be derived by hash-to-curve, as with
+// the ed25519 package HashToPoint, never as the multiple of a known scalar such as a key pair.
+func ProveZeroBalance(c *Challenge, blinding *ed25519.Scalar, h *ed25519.Point) ([]byte, error) {
+	randBytes := make([]byte, 32)
+	if _, err := rand.Read(randBytes); err != nil {
+		return nil, err
+	}
+	// the nonce is hedged with the blinding, so that a weak random source alone does not leak it
+	hashFunc := sha512.New()
+	hashFunc.Write(randBytes)
+	hashFunc.Write(blinding.Bytes())
+	nonce, err := ed25519.NewScalar().SetUniformBytes(hashFunc.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	r := new(ed25519.Point).ScalarMult(nonce, h)
+	e, err := proofChallenge(c, h, r)
+	if err != nil {
+		return nil, err
+	}
+	s := ed25519.NewScalar().MultiplyAdd(e, blinding, nonce)
+	proof := make([]byte, 0, ProofSize)
+	proof = append(proof, r.Bytes()...)
+	return append(proof, s.Bytes()...), nil
+}
+
+// VerifyZeroBalance verifies a zero-balance proof of the challenged commitment.
+// Its inputs are all public, so it runs in variable time.
+func VerifyZeroBalance(c *Challenge, proof []byte, h *ed25519.Point) error {
+	if len(proof) != ProofSize {
+		return ErrInvalidProof
+	}
+	r, err := new(ed25519.Point).SetBytes(proof[:32])
+	if err != nil {
+		return ErrInvalidProof
+	}
+	s, err := ed25519.NewScalar().SetCanonicalBytes(proof[32:])
+	if err != nil {
+		return ErrInvalidProof
+	}
+	challenged, err := new(ed25519.Point).SetBytes(c.Commitment)
+	if err != nil {
+		return ErrInvalidProof
+	}
+	e, err := proofChallenge(c, h, r)
+	if err != nil {
+		return err
+	}
+	// s * H - e * C == R
+	minusE := ed25519.NewScalar().Negate(e)
+	check := new(ed25519.Point).VarTimeMultiScalarMult([]*ed25519.Scalar{s, minusE}, []*ed25519.Point{h, challenged})
+	if check.Equal(r) != 1 {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// proofChallenge binds the proof to the challenge, so that it cannot be replayed for another dispute
+func proofChallenge(c *Challenge, h, r *ed25519.Point) (*ed25519.Scalar, error) {
+	id, err := c.ID()
+	if err != nil {
+		return nil, err
+	}
+	hashFunc := sha512.New()
+	hashFunc.Write([]byte(proofDomain))
+	hashFunc.Write(h.Bytes())
+	hashFunc.Write(c.Commitment)
+	hashFunc.Write(r.Bytes())
+	hashFunc.Write([]byte(id))
+	return ed25519.NewScalar().SetUniformBytes(hashFunc.Sum(nil))
+}
@@ -0,0 +1,225 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+const (
+	// HardenedOffset is the first index of hardened derivation, written as i' in a path
+	HardenedOffset uint32 = 1 << 31
+	// ChainCodeSize is the size of the chain code of an extended key
+	ChainCodeSize = 32
+	// ExtendedKeySize is the size of a serialized extended key, the key followed by the chain code
+	ExtendedKeySize = 32 + ChainCodeSize
+	// MinSeedSize is the minimum size of a master seed
+	MinSeedSize = 16
+)
+
+// masterKeyDomain is the HMAC key of the master key derivation from a seed
+const masterKeyDomain = "auti-core ed25519 seed"
+
+// tags separating the derived tweak from the derived chain code
+const (
+	tagTweak     = 0x01
+	tagChainCode = 0x02
+)
+
+var (
+	// ErrHardenedPublic is returned when deriving a hardened child from a public key
+	ErrHardenedPublic = errors.New("ed25519: hardened derivation requires the private key")
+	// ErrInvalidPath is returned when parsing a malformed derivation path
+	ErrInvalidPath = errors.New("ed25519: invalid derivation path")
+	// ErrInvalidExtendedKey is returned when parsing a malformed extended key
+	ErrInvalidExtendedKey = errors.New("ed25519: invalid extended key")
+)
+
+// ExtendedPrivateKey is a private key with the chain code to derive its children
+type ExtendedPrivateKey struct {
+	PrivateKey *ed25519.Scalar
+	ChainCode  []byte
+}
+
+// ExtendedPublicKey is a public key with the chain code to derive its non-hardened children
+type ExtendedPublicKey struct {
+	PublicKey *ed25519.Point
+	ChainCode []byte
+}
+
+// NewMasterKey derives the master key of a seed, the root m of all derivation paths
+func NewMasterKey(seed []byte) (*ExtendedPrivateKey, error) {
+	if len(seed) < MinSeedSize {
+		return nil, fmt.Errorf("ed25519: seed of %d bytes, want at least %d", len(seed), MinSeedSize)
+	}
+	tweak, chainCode, err := derive([]byte(masterKeyDomain), seed)
+	if err != nil {
+		return nil, err
+	}
+	return &ExtendedPrivateKey{PrivateKey: tweak, ChainCode: chainCode}, nil
+}
+
+// Public returns the extended public key, which derives the same non-hardened public keys
+func (k *ExtendedPrivateKey) Public() *ExtendedPublicKey {
+	return &ExtendedPublicKey{
+		PublicKey: ed25519.NewGeneratorPoint().ScalarBaseMult(k.PrivateKey),
+		ChainCode: append([]byte{}, k.ChainCode...),
+	}
+}
+
+// Child derives the child key of the index, hardened if the index is at least HardenedOffset.
+// A non-hardened child is the parent key plus a tweak of the parent public key, so its public key
+// is derived from the parent public key as well, and the parent private key is the child private key
+// minus the tweak: a non-hardened child private key must never be shared with holders of the parent
+// extended public key. A hardened child depends on the parent private key.
+func (k *ExtendedPrivateKey) Child(index uint32) (*ExtendedPrivateKey, error) {
+	var data []byte
+	if index >= HardenedOffset {
+		data = append([]byte{0x00}, k.PrivateKey.Bytes()...)
+	} else {
+		data = ed25519.NewGeneratorPoint().ScalarBaseMult(k.PrivateKey).Bytes()
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	tweak, chainCode, err := derive(k.ChainCode, data)
+	if err != nil {
+		return nil, err
+	}
+	privateKey := tweak.Add(tweak, k.PrivateKey)
+	if privateKey.Equal(ed25519.NewScalar()) == 1 {
+		return nil, fmt.Errorf("ed25519: invalid child key of index %d", index)
+	}
+	return &ExtendedPrivateKey{PrivateKey: privateKey, ChainCode: chainCode}, nil
+}
+
+// DerivePath derives the key of a path relative to the key, such as "m/0'/1"
+func (k *ExtendedPrivateKey) DerivePath(path string) (*ExtendedPrivateKey, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	child := k
+	for _, index := range indices {
+		if child, err = child.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return child, nil
+}
+
+// Bytes returns the serialized key, the private key followed by the chain code
+func (k *ExtendedPrivateKey) Bytes() []byte {
+	return append(k.PrivateKey.Bytes(), k.ChainCode...)
+}
+
+// ParseExtendedPrivateKey parses a serialized extended private key
+func ParseExtendedPrivateKey(data []byte) (*ExtendedPrivateKey, error) {
+	if len(data) != ExtendedKeySize {
+		return nil, ErrInvalidExtendedKey
+	}
+	privateKey, err := ed25519.NewScalar().SetCanonicalBytes(data[:32])
+	if err != nil {
+		return nil, ErrInvalidExtendedKey
+	}
+	return &ExtendedPrivateKey{PrivateKey: privateKey, ChainCode: append([]byte{}, data[32:]...)}, nil
+}
+
+// Child derives the public key of the non-hardened child of the index
+func (k *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	if index >= HardenedOffset {
+		return nil, ErrHardenedPublic
+	}
+	data := binary.BigEndian.AppendUint32(k.PublicKey.Bytes(), index)
+	tweak, chainCode, err := derive(k.ChainCode, data)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := ed25519.NewGeneratorPoint().ScalarBaseMult(tweak)
+	publicKey.Add(publicKey, k.PublicKey)
+	if publicKey.Equal(ed25519.NewIdentityPoint()) == 1 {
+		return nil, fmt.Errorf("ed25519: invalid child key of index %d", index)
+	}
+	return &ExtendedPublicKey{PublicKey: publicKey, ChainCode: chainCode}, nil
+}
+
+// DerivePath derives the public key of a non-hardened path relative to the key, such as "m/0/1"
+func (k *ExtendedPublicKey) DerivePath(path string) (*ExtendedPublicKey, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	child := k
+	for _, index := range indices {
+		if child, err = child.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return child, nil
+}
+
+// Bytes returns the serialized key, the public key followed by the chain code
+func (k *ExtendedPublicKey) Bytes() []byte {
+	return append(k.PublicKey.Bytes(), k.ChainCode...)
+}
+
+// ParseExtendedPublicKey parses a serialized extended public key
+func ParseExtendedPublicKey(data []byte) (*ExtendedPublicKey, error) {
+	if len(data) != ExtendedKeySize {
+		return nil, ErrInvalidExtendedKey
+	}
+	publicKey, err := new(ed25519.Point).SetBytes(data[:32])
+	// a non-canonical encoding would serialize to another key
+	if err != nil || !bytes.Equal(publicKey.Bytes(), data[:32]) {
+		return nil, ErrInvalidExtendedKey
+	}
+	return &ExtendedPublicKey{PublicKey: publicKey, ChainCode: append([]byte{}, data[32:]...)}, nil
+}
+
+// ParsePath parses a derivation path such as "m/0'/1" into its indices,
+// a hardened index is marked with ' or h and the leading m is optional
+func ParsePath(path string) ([]uint32, error) {
+	if path == "" || path == "m" {
+		return nil, nil
+	}
+	elements := strings.Split(strings.TrimPrefix(path, "m/"), "/")
+	indices := make([]uint32, len(elements))
+	for i, element := range elements {
+		hardened := strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h")
+		if hardened {
+			element = element[:len(element)-1]
+		}
+		if element == "" || element[0] == '+' || element[0] == '-' {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+		index, err := strconv.ParseUint(element, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+		indices[i] = uint32(index)
+		if hardened {
+			indices[i] += HardenedOffset
+		}
+	}
+	return indices, nil
+}
+
+// derive computes the tweak and the chain code of the data under a chain code
+func derive(chainCode, data []byte) (*ed25519.Scalar, []byte, error) {
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write([]byte{tagTweak})
+	mac.Write(data)
+	tweak, err := ed25519.NewScalar().SetUniformBytes(mac.Sum(nil))
+	if err != nil {
+		return nil, nil, err
+	}
+	mac.Reset()
+	mac.Write([]byte{tagChainCode})
+	mac.Write(data)
+	return tweak, mac.Sum(nil)[:ChainCodeSize], nil
+}
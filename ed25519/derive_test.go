@@ -0,0 +1,201 @@
+package ed25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testSeed = []byte("auti-core derivation test seed 0")
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []uint32
+		wantErr bool
+	}{
+		{"Test_Master", "m", nil, false},
+		{"Test_Empty", "", nil, false},
+		{"Test_Non_Hardened", "m/0/1", []uint32{0, 1}, false},
+		{"Test_Hardened", "m/0'/1h", []uint32{HardenedOffset, HardenedOffset + 1}, false},
+		{"Test_Without_M", "3/4'", []uint32{3, HardenedOffset + 4}, false},
+		{"Test_Largest_Index", "m/2147483647'", []uint32{^uint32(0)}, false},
+		{"Test_Index_Too_Large", "m/2147483648", nil, true},
+		{"Test_Empty_Element", "m/0//1", nil, true},
+		{"Test_Trailing_Slash", "m/0/", nil, true},
+		{"Test_Signed", "m/+1", nil, true},
+		{"Test_Not_A_Number", "m/x", nil, true},
+		{"Test_Only_Mark", "m/'", nil, true},
+		{"Test_Master_Without_Slash", "m0", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("ParsePath() error = %v, want %v", err, ErrInvalidPath)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePath() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedPublicKey_DerivePath(t *testing.T) {
+	master, err := NewMasterKey(testSeed)
+	if err != nil {
+		t.Fatalf("NewMasterKey() error = %v", err)
+	}
+	account, err := master.DerivePath("m/7'")
+	if err != nil {
+		t.Fatalf("DerivePath() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		key     *ExtendedPrivateKey
+		path    string
+		wantErr error
+	}{
+		{"Test_Master", master, "m", nil},
+		{"Test_Chain", master, "m/1", nil},
+		{"Test_Chain_And_Epoch", master, "m/1/42", nil},
+		{"Test_Below_Hardened", account, "m/2/3", nil},
+		{"Test_Hardened", master, "m/1'", ErrHardenedPublic},
+		{"Test_Hardened_Last", master, "m/1/2'", ErrHardenedPublic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.key.Public().DerivePath(tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DerivePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want, err := tt.key.DerivePath(tt.path)
+			if err != nil {
+				t.Fatalf("DerivePath() error = %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Public().Bytes()) {
+				t.Errorf("DerivePath() got = %x, want %x", got.Bytes(), want.Public().Bytes())
+			}
+		})
+	}
+}
+
+func TestExtendedPrivateKey_DerivePath(t *testing.T) {
+	master, err := NewMasterKey(testSeed)
+	if err != nil {
+		t.Fatalf("NewMasterKey() error = %v", err)
+	}
+	// pins the derivation, so that keys derived from a backed up seed never change
+	want := map[string]string{
+		"m":        "219e9f07f7d6a56a1272b80c41c09b1beea6779feb8c00fb99d310222a07b90586c3e9d2e88848452085f291a0f1b2c079bca233a5be107dffc11bf03ce991cd",
+		"m/0":      "65dc0433db3b300016ea84f5d70f1b6c7dc34c33b36cf025e8540179c157830bd479241b134953e971cb92cf5b16adbae86abc2d8cd7f80d3ae1cbd4f69ce814",
+		"m/0'/1":   "129d6c2fa418cfdc0d91f341c1fd2f530529b5ae3909baa62faaeab57b8d1a05c2e8648e86194c43c39a19b341dfda36554982f118a4096452181061527b6ab9",
+		"m/1'/2/3": "13b990b25fe03c3428ee5bae3f994e3c29146c2020b00d25165824671db7ab02f06be770ad642c52070c9863011de0d2aa86851a689e0f07c11de00bf2b18912",
+	}
+	seen := make(map[string]string, len(want))
+	for path, wantKey := range want {
+		t.Run(path, func(t *testing.T) {
+			got, err := master.DerivePath(path)
+			if err != nil {
+				t.Fatalf("DerivePath() error = %v", err)
+			}
+			again, err := master.DerivePath(path)
+			if err != nil {
+				t.Fatalf("DerivePath() error = %v", err)
+			}
+			gotKey := hex.EncodeToString(got.Bytes())
+			if gotKey != hex.EncodeToString(again.Bytes()) {
+				t.Errorf("DerivePath() is not deterministic")
+			}
+			if gotKey != wantKey {
+				t.Errorf("DerivePath() got = %s, want %s", gotKey, wantKey)
+			}
+			seen[gotKey] = path
+		})
+	}
+	if len(seen) != len(want) {
+		t.Errorf("DerivePath() derived the same key for different paths")
+	}
+}
+
+func TestNewMasterKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    []byte
+		wantErr bool
+	}{
+		{"Test_Minimum_Seed", make([]byte, MinSeedSize), false},
+		{"Test_64_Byte_Seed", make([]byte, 64), false},
+		{"Test_Short_Seed", make([]byte, MinSeedSize-1), true},
+		{"Test_No_Seed", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMasterKey(tt.seed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewMasterKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseExtendedKey(t *testing.T) {
+	master, err := NewMasterKey(testSeed)
+	if err != nil {
+		t.Fatalf("NewMasterKey() error = %v", err)
+	}
+	privateBytes, publicBytes := master.Bytes(), master.Public().Bytes()
+	nonCanonical := append(bytes.Repeat([]byte{0xff}, 32), master.ChainCode...)
+	invalidPoint := append(make([]byte, 32), master.ChainCode...)
+	invalidPoint[0] = 2
+	tests := []struct {
+		name    string
+		data    []byte
+		public  bool
+		wantErr bool
+	}{
+		{"Test_Private_Key", privateBytes, false, false},
+		{"Test_Public_Key", publicBytes, true, false},
+		{"Test_Short_Private_Key", privateBytes[:ExtendedKeySize-1], false, true},
+		{"Test_Short_Public_Key", publicBytes[:ExtendedKeySize-1], true, true},
+		{"Test_Long_Private_Key", append(privateBytes, 0), false, true},
+		{"Test_Long_Public_Key", append(publicBytes, 0), true, true},
+		{"Test_Non_Canonical_Scalar", nonCanonical, false, true},
+		{"Test_Non_Canonical_Point", nonCanonical, true, true},
+		{"Test_Invalid_Point", invalidPoint, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			if tt.public {
+				key, err := ParseExtendedPublicKey(tt.data)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ParseExtendedPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil {
+					got = key.Bytes()
+				}
+			} else {
+				key, err := ParseExtendedPrivateKey(tt.data)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ParseExtendedPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil {
+					got = key.Bytes()
+				}
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.data) {
+				t.Errorf("Bytes() got = %x, want %x", got, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package ed25519
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+// hashToPointDomain separates the hashes of HashToPoint from the other hashes of the protocol
+const hashToPointDomain = "auti-core hash to point"
+
+// ErrNoPoint is returned when no point of the prime-order subgroup is found for a label
+var ErrNoPoint = errors.New("ed25519: no point found for the label")
+
+// HashToPoint maps a public label to a point of the prime-order subgroup, by try-and-increment
+// on SHA-512 of the label and a counter byte, clearing the cofactor. Unlike a multiple of the base point,
+// no one knows the discrete log of the point with respect to another, so it may serve as an independent
+// generator of commitments. It runs in variable time.
+func HashToPoint(label []byte) (*ed25519.Point, error) {
+	for counter := 0; counter < 256; counter++ {
+		h := sha512.New()
+		h.Write([]byte(hashToPointDomain))
+		h.Write(label)
+		h.Write([]byte{byte(counter)})
+		point, err := new(ed25519.Point).SetBytes(h.Sum(nil)[:32])
+		if err != nil {
+			continue
+		}
+		point.MultByCofactor(point)
+		if point.Equal(ed25519.NewIdentityPoint()) != 1 {
+			return point, nil
+		}
+	}
+	return nil, ErrNoPoint
+}
@@ -0,0 +1,46 @@
+package ed25519
+
+import (
+	"encoding/hex"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+func TestHashToPoint(t *testing.T) {
+	tests := []struct {
+		name  string
+		label string
+		want  string
+	}{
+		{"Test_Empty_Label", "", "3508f31afa3b9fd7df2d9865e28e547e9ea523a98e23eb9917a9adaa1e0a333d"},
+		{"Test_Label", "auti-core g", "d7569ec5e719bdae2e7f5036ac09d555706c8a0636f1fc67a63525c6de144117"},
+	}
+	minusOne := ed25519.NewScalar().Subtract(ed25519.NewScalar(), scalarOne())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HashToPoint([]byte(tt.label))
+			if err != nil {
+				t.Fatalf("HashToPoint(%q) error = %v", tt.label, err)
+			}
+			if hex.EncodeToString(got.Bytes()) != tt.want {
+				t.Errorf("HashToPoint(%q) = %x, want %s", tt.label, got.Bytes(), tt.want)
+			}
+			// (l - 1) * P + P is the identity only for a point of the prime-order subgroup
+			sum := new(ed25519.Point).ScalarMult(minusOne, got)
+			if sum.Add(sum, got).Equal(ed25519.NewIdentityPoint()) != 1 {
+				t.Errorf("HashToPoint(%q) is not in the prime-order subgroup", tt.label)
+			}
+		})
+	}
+}
+
+func scalarOne() *ed25519.Scalar {
+	b := make([]byte, 32)
+	b[0] = 1
+	s, err := ed25519.NewScalar().SetCanonicalBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,197 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+var (
+	// ErrUnknownChain is returned for a chain that was not added to the manager
+	ErrUnknownChain = errors.New("epoch: unknown chain")
+	// ErrDuplicateChain is returned when adding a chain twice
+	ErrDuplicateChain = errors.New("epoch: duplicate chain")
+)
+
+// chain is the running state of a local chain
+type chain struct {
+	// last is the aggregate commitment at the end of the last epoch
+	last *ed25519.Point
+	// sum is the sum of the commitments of the transactions of the current epoch
+	sum          *ed25519.Point
+	transactions []*transaction.Hidden
+}
+
+// current returns the running aggregate commitment, last + sum
+func (c *chain) current() *ed25519.Point {
+	return new(ed25519.Point).Add(c.last, c.sum)
+}
+
+// Manager keeps the running aggregate commitment of every local chain of an organization,
+// and seals them into epochs for sumcheck
+type Manager struct {
+	mu     sync.Mutex
+	suite  *hashsuite.Suite
+	epoch  uint64
+	chains map[string]*chain
+}
+
+// NewManager creates a new manager at epoch 0 without chains, of transactions of the default hash suite
+func NewManager() *Manager {
+	return NewManagerWith(hashsuite.Default())
+}
+
+// NewManagerWith creates a new manager as NewManager, of transactions of the hash suite
+func NewManagerWith(suite *hashsuite.Suite) *Manager {
+	return &Manager{suite: suite, chains: make(map[string]*chain)}
+}
+
+// AddChain adds a local chain with the aggregate commitment of its last epoch,
+// a nil commitment starts a new chain at the identity
+func (m *Manager) AddChain(chainID string, last []byte) error {
+	lastPoint := ed25519.NewIdentityPoint()
+	if last != nil {
+		var err error
+		if lastPoint, err = new(ed25519.Point).SetBytes(last); err != nil {
+			return fmt.Errorf("epoch: last commitment of chain %q: %w", chainID, err)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.chains[chainID]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateChain, chainID)
+	}
+	m.chains[chainID] = &chain{last: lastPoint, sum: ed25519.NewIdentityPoint()}
+	return nil
+}
+
+// Apply validates a hidden transaction of a chain and adds its commitment to the running aggregate
+func (m *Manager) Apply(chainID string, tx *transaction.Hidden) error {
+	if err := tx.ValidateWith(m.suite); err != nil {
+		return err
+	}
+	// validated above
+	commitment, _ := new(ed25519.Point).SetBytes(tx.Commitment)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.chains[chainID]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownChain, chainID)
+	}
+	c.sum.Add(c.sum, commitment)
+	c.transactions = append(c.transactions, tx)
+	return nil
+}
+
+// Epoch returns the number of the current epoch
+func (m *Manager) Epoch() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.epoch
+}
+
+// Chains returns the IDs of the chains, sorted
+func (m *Manager) Chains() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.chainIDs()
+}
+
+func (m *Manager) chainIDs() []string {
+	ids := make([]string, 0, len(m.chains))
+	for id := range m.chains {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Current returns the running aggregate commitment of a chain
+func (m *Manager) Current(chainID string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.chains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownChain, chainID)
+	}
+	return c.current().Bytes(), nil
+}
+
+// Seal ends the current epoch and returns it, the current aggregate of every chain becomes its last one
+func (m *Manager) Seal() *Sealed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sealed := &Sealed{Epoch: m.epoch, Chains: make([]*ChainEpoch, 0, len(m.chains))}
+	for _, id := range m.chainIDs() {
+		c := m.chains[id]
+		current := c.current()
+		sealed.Chains = append(sealed.Chains, &ChainEpoch{
+			ChainID:      id,
+			Last:         c.last.Bytes(),
+			Current:      current.Bytes(),
+			Sum:          c.sum.Bytes(),
+			Transactions: c.transactions,
+		})
+		c.last = current
+		c.sum = ed25519.NewIdentityPoint()
+		c.transactions = nil
+	}
+	m.epoch++
+	return sealed
+}
+
+// ChainEpoch is a sealed epoch of a chain, Current = Last + Sum, and Sum is the sum of the transaction commitments
+type ChainEpoch struct {
+	ChainID      string
+	Last         []byte
+	Current      []byte
+	Sum          []byte
+	Transactions []*transaction.Hidden
+}
+
+// Sealed is a sealed epoch of all chains of an organization, sorted by chain ID
+type Sealed struct {
+	Epoch  uint64
+	Chains []*ChainEpoch
+}
+
+// LastCommits returns the last commitments of the chains, as taken by sumcheck
+func (s *Sealed) LastCommits() [][]byte {
+	commits := make([][]byte, len(s.Chains))
+	for i, c := range s.Chains {
+		commits[i] = c.Last
+	}
+	return commits
+}
+
+// CurrCommits returns the current commitments of the chains, as taken by sumcheck
+func (s *Sealed) CurrCommits() [][]byte {
+	commits := make([][]byte, len(s.Chains))
+	for i, c := range s.Chains {
+		commits[i] = c.Current
+	}
+	return commits
+}
+
+// EpochCommits returns the epoch sums of the chains, as taken by sumcheck.CheckAllOrgEpoch
+func (s *Sealed) EpochCommits() [][]byte {
+	commits := make([][]byte, len(s.Chains))
+	for i, c := range s.Chains {
+		commits[i] = c.Sum
+	}
+	return commits
+}
+
+// TXLists returns the transactions of the chains, as taken by sumcheck.CheckOrgEpoch
+func (s *Sealed) TXLists() [][]*transaction.Hidden {
+	txLists := make([][]*transaction.Hidden, len(s.Chains))
+	for i, c := range s.Chains {
+		txLists[i] = c.Transactions
+	}
+	return txLists
+}
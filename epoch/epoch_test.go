@@ -0,0 +1,281 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/sumcheck"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// generators returns the generators of the commitments, of which the discrete log of h with respect to g is unknown
+func generators(t *testing.T) (g, h *ed25519.Point) {
+	t.Helper()
+	g, err := autied25519.HashToPoint([]byte("auti-core epoch test g"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	h, err = autied25519.HashToPoint([]byte("auti-core epoch test h"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	return g, h
+}
+
+func hiddenPair(t *testing.T, amount int64, counter uint64) (*transaction.Hidden, *transaction.Hidden) {
+	t.Helper()
+	g, h := generators(t)
+	p := transaction.NewPlain("org1", "org2", amount)
+	p.Timestamp = 1672531200000000000 + int64(counter)
+	h1, h2, err := p.HidePair(counter, g, h)
+	if err != nil {
+		t.Fatalf("HidePair() error = %v", err)
+	}
+	return h1, h2
+}
+
+func newManager(t *testing.T, chainIDs ...string) *Manager {
+	t.Helper()
+	m := NewManager()
+	for _, id := range chainIDs {
+		if err := m.AddChain(id, nil); err != nil {
+			t.Fatalf("AddChain() error = %v", err)
+		}
+	}
+	return m
+}
+
+func TestManager_Seal(t *testing.T) {
+	m := newManager(t, "chain-b", "chain-a")
+	for i, amount := range []int64{100, -20, 7} {
+		h1, h2 := hiddenPair(t, amount, uint64(i))
+		if err := m.Apply("chain-a", h1); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if err := m.Apply("chain-b", h2); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+	}
+	first := m.Seal()
+	h1, h2 := hiddenPair(t, 5, 3)
+	if err := m.Apply("chain-a", h1); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err := m.Apply("chain-b", h2); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	second := m.Seal()
+	empty := m.Seal()
+
+	if got := []uint64{first.Epoch, second.Epoch, empty.Epoch}; !reflect.DeepEqual(got, []uint64{0, 1, 2}) {
+		t.Errorf("Seal() epochs = %v, want [0 1 2]", got)
+	}
+	got := []string{first.Chains[0].ChainID, first.Chains[1].ChainID}
+	if !reflect.DeepEqual(got, []string{"chain-a", "chain-b"}) {
+		t.Errorf("Seal() chains = %v, want sorted", got)
+	}
+	if !reflect.DeepEqual(second.LastCommits(), first.CurrCommits()) {
+		t.Errorf("Seal() last commitments are not the current commitments of the previous epoch")
+	}
+	identity := ed25519.NewIdentityPoint().Bytes()
+	for _, c := range empty.Chains {
+		if !bytes.Equal(c.Sum, identity) || !bytes.Equal(c.Last, c.Current) || len(c.Transactions) != 0 {
+			t.Errorf("Seal() of an empty epoch changed chain %q", c.ChainID)
+		}
+	}
+	// the pairs cancel out across the chains
+	total := ed25519.NewIdentityPoint()
+	for _, commit := range second.CurrCommits() {
+		point, err := new(ed25519.Point).SetBytes(commit)
+		if err != nil {
+			t.Fatalf("SetBytes() error = %v", err)
+		}
+		total.Add(total, point)
+	}
+	if total.Equal(ed25519.NewIdentityPoint()) != 1 {
+		t.Errorf("Seal() current commitments of the pairs do not sum to the identity")
+	}
+
+	tampered := *first.Chains[0]
+	tampered.Current = second.Chains[0].Current
+	tests := []struct {
+		name   string
+		sealed *Sealed
+		want   bool
+	}{
+		{"Test_First", first, true},
+		{"Test_Second", second, true},
+		{"Test_Empty", empty, true},
+		{"Test_Tampered", &Sealed{Chains: []*ChainEpoch{&tampered, first.Chains[1]}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := sumcheck.CheckOrgEpoch(tt.sealed.LastCommits(), tt.sealed.CurrCommits(), tt.sealed.TXLists())
+			if err != nil {
+				t.Fatalf("CheckOrgEpoch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckOrgEpoch() got = %v, want %v", got, tt.want)
+			}
+			got, err = sumcheck.CheckAllOrgEpoch([][][]byte{tt.sealed.LastCommits()},
+				[][][]byte{tt.sealed.EpochCommits()}, [][][]byte{tt.sealed.CurrCommits()})
+			if err != nil {
+				t.Fatalf("CheckAllOrgEpoch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckAllOrgEpoch() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_Apply(t *testing.T) {
+	m := newManager(t, "chain-a")
+	valid, _ := hiddenPair(t, 1, 0)
+	invalid := *valid
+	invalid.Commitment = make([]byte, transaction.CommitmentSize)
+	invalid.Commitment[0] = 2
+	tests := []struct {
+		name    string
+		chainID string
+		tx      *transaction.Hidden
+		wantErr error
+	}{
+		{"Test_Valid", "chain-a", valid, nil},
+		{"Test_Unknown_Chain", "chain-b", valid, ErrUnknownChain},
+		{"Test_Invalid_Commitment", "chain-a", &invalid, transaction.ErrInvalidPoint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.Apply(tt.chainID, tt.tx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	current, err := m.Current("chain-a")
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+	if !bytes.Equal(current, valid.Commitment) {
+		t.Errorf("Current() = %x, want %x", current, valid.Commitment)
+	}
+	if err = m.AddChain("chain-a", nil); !errors.Is(err, ErrDuplicateChain) {
+		t.Errorf("AddChain() error = %v, wantErr %v", err, ErrDuplicateChain)
+	}
+}
+
+func TestManagerWith_Suite(t *testing.T) {
+	g, h := generators(t)
+	suite := hashsuite.BLAKE2b_512
+	tx, _, err := transaction.NewPlain("org1", "org2", 1).HidePairWith(0, &commitment.Params{G: g, H: h, Suite: suite})
+	if err != nil {
+		t.Fatalf("HidePairWith() error = %v", err)
+	}
+	m := NewManagerWith(suite)
+	if err = m.AddChain("chain-a", nil); err != nil {
+		t.Fatalf("AddChain() error = %v", err)
+	}
+	if err = m.Apply("chain-a", tx); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err = newManager(t, "chain-a").Apply("chain-a", tx); !errors.Is(err, transaction.ErrInvalidLength) {
+		t.Errorf("Apply() to a manager of the default suite error = %v, wantErr %v", err, transaction.ErrInvalidLength)
+	}
+	path := filepath.Join(t.TempDir(), "epoch.json")
+	if err = m.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if _, err = LoadFileWith(suite, path); err != nil {
+		t.Errorf("LoadFileWith() error = %v", err)
+	}
+	if _, err = LoadFile(path); !errors.Is(err, transaction.ErrInvalidLength) {
+		t.Errorf("LoadFile() error = %v, wantErr %v", err, transaction.ErrInvalidLength)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	m := newManager(t, "chain-a", "chain-b")
+	for i := 0; i < 2; i++ {
+		h1, h2 := hiddenPair(t, int64(10*(i+1)), uint64(i))
+		if err := m.Apply("chain-a", h1); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if i == 0 {
+			m.Seal()
+		}
+		if err := m.Apply("chain-b", h2); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "epoch.json")
+	if err := m.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err = m.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, want := loaded.Seal(), m.Seal()
+	if got.Epoch != want.Epoch || !reflect.DeepEqual(got.LastCommits(), want.LastCommits()) ||
+		!reflect.DeepEqual(got.CurrCommits(), want.CurrCommits()) ||
+		!reflect.DeepEqual(got.EpochCommits(), want.EpochCommits()) {
+		t.Errorf("LoadFile() does not seal as the saved manager")
+	}
+	for i := range want.Chains {
+		if len(got.Chains[i].Transactions) != len(want.Chains[i].Transactions) {
+			t.Errorf("LoadFile() got %d transactions of chain %q, want %d", len(got.Chains[i].Transactions),
+				want.Chains[i].ChainID, len(want.Chains[i].Transactions))
+		}
+	}
+
+	h1, _ := hiddenPair(t, 3, 9)
+	mutate := func(f func(s *state)) []byte {
+		var c state
+		if err := json.Unmarshal(buf.Bytes(), &c); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		f(&c)
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return data
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"Test_Valid", buf.Bytes(), false},
+		{"Test_Malformed", []byte("{"), true},
+		{"Test_Duplicate_Chain", mutate(func(s *state) { s.Chains[1].ChainID = s.Chains[0].ChainID }), true},
+		{"Test_Invalid_Last", mutate(func(s *state) { s.Chains[0].Last = "zz" }), true},
+		{"Test_Invalid_Sum", mutate(func(s *state) { s.Chains[0].Sum = s.Chains[0].Sum[:10] }), true},
+		{"Test_Extra_Transaction", mutate(func(s *state) {
+			s.Chains[0].Transactions = append(s.Chains[0].Transactions, h1.ToOnChain())
+		}), true},
+		{"Test_Invalid_Transaction", mutate(func(s *state) {
+			s.Chains[0].Transactions = append(s.Chains[0].Transactions, &transaction.OnChain{Timestamp: "x"})
+		}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package epoch
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/internal/atomicfile"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// state is the JSON form of a manager
+type state struct {
+	Epoch  uint64       `json:"epoch"`
+	Chains []chainState `json:"chains"`
+}
+
+// chainState is the JSON form of a chain, the transactions are kept in their on-chain form
+type chainState struct {
+	ChainID      string                 `json:"chain_id"`
+	Last         string                 `json:"last"`
+	Sum          string                 `json:"sum"`
+	Transactions []*transaction.OnChain `json:"transactions"`
+}
+
+// Save writes the state of the manager to w
+func (m *Manager) Save(w io.Writer) error {
+	m.mu.Lock()
+	s := state{Epoch: m.epoch, Chains: make([]chainState, 0, len(m.chains))}
+	for _, id := range m.chainIDs() {
+		c := m.chains[id]
+		cs := chainState{
+			ChainID:      id,
+			Last:         hex.EncodeToString(c.last.Bytes()),
+			Sum:          hex.EncodeToString(c.sum.Bytes()),
+			Transactions: make([]*transaction.OnChain, len(c.transactions)),
+		}
+		for i, tx := range c.transactions {
+			cs.Transactions[i] = tx.ToOnChain()
+		}
+		s.Chains = append(s.Chains, cs)
+	}
+	m.mu.Unlock()
+	return json.NewEncoder(w).Encode(s)
+}
+
+// Load reads a manager saved by Save from r, checking that the sum of every chain matches its transactions,
+// of transactions of the default hash suite
+func Load(r io.Reader) (*Manager, error) {
+	return LoadWith(hashsuite.Default(), r)
+}
+
+// LoadWith reads a manager as Load, of transactions of the hash suite
+func LoadWith(suite *hashsuite.Suite, r io.Reader) (*Manager, error) {
+	var s state
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	m := NewManagerWith(suite)
+	m.epoch = s.Epoch
+	for _, cs := range s.Chains {
+		if _, ok := m.chains[cs.ChainID]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateChain, cs.ChainID)
+		}
+		last, err := decodePoint(cs.Last)
+		if err != nil {
+			return nil, fmt.Errorf("epoch: last commitment of chain %q: %w", cs.ChainID, err)
+		}
+		sum, err := decodePoint(cs.Sum)
+		if err != nil {
+			return nil, fmt.Errorf("epoch: sum of chain %q: %w", cs.ChainID, err)
+		}
+		c := &chain{last: last, sum: ed25519.NewIdentityPoint()}
+		for _, o := range cs.Transactions {
+			tx, err := o.ToHide()
+			if err != nil {
+				return nil, fmt.Errorf("epoch: transaction of chain %q: %w", cs.ChainID, err)
+			}
+			if err = tx.ValidateWith(suite); err != nil {
+				return nil, fmt.Errorf("epoch: transaction of chain %q: %w", cs.ChainID, err)
+			}
+			commitment, _ := new(ed25519.Point).SetBytes(tx.Commitment)
+			c.sum.Add(c.sum, commitment)
+			c.transactions = append(c.transactions, tx)
+		}
+		if c.sum.Equal(sum) != 1 {
+			return nil, fmt.Errorf("epoch: sum of chain %q does not match its transactions", cs.ChainID)
+		}
+		m.chains[cs.ChainID] = c
+	}
+	return m, nil
+}
+
+// SaveFile writes the state of the manager to a file, replaced atomically
+func (m *Manager) SaveFile(path string) error {
+	return atomicfile.WriteFile(path, m.Save)
+}
+
+// LoadFile reads a manager saved by SaveFile, of transactions of the default hash suite
+func LoadFile(path string) (*Manager, error) {
+	return LoadFileWith(hashsuite.Default(), path)
+}
+
+// LoadFileWith reads a manager saved by SaveFile, of transactions of the hash suite
+func LoadFileWith(suite *hashsuite.Suite, path string) (*Manager, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadWith(suite, f)
+}
+
+func decodePoint(s string) (*ed25519.Point, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(ed25519.Point).SetBytes(b)
+}
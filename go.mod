@@ -2,4 +2,9 @@ module github.com/auti-project/auti-core
 
 go 1.19
 
-require filippo.io/edwards25519 v1.0.0
+require (
+	filippo.io/edwards25519 v1.0.0
+	golang.org/x/crypto v0.14.0
+)
+
+require golang.org/x/sys v0.13.0 // indirect
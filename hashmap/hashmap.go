@@ -1,49 +1,114 @@
 package hashmap
 
-import "sync"
+import (
+	"bytes"
+	"sync"
+)
 
-// HashMap is the struct for storing the key-value pairs of names and their hashes
+// HashMap is the struct for storing the key-value pairs of names and their hashes.
+// It is a bijection: every name has at most one hash and every hash at most one name.
+// The zero value is an empty map, and it is safe for concurrent use.
 type HashMap struct {
-	data   sync.Map
-	revert sync.Map
+	mu     sync.RWMutex
+	data   map[string]string
+	revert map[string]string
 }
 
 // Get returns the hash of a name
 func (m *HashMap) Get(name string) ([]byte, bool) {
-	val, ok := m.data.Load(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	val, ok := m.data[name]
 	if ok {
-		return []byte(val.(string)), true
+		return []byte(val), true
 	}
 	return nil, false
 }
 
-// Set sets the hash of a name, and accordingly set the
+// Set sets the hash of a name, and accordingly set the name of the hash.
+// The previous hash of the name and the previous name of the hash are unbound.
 func (m *HashMap) Set(name string, hash []byte) {
-	hashStr := string(hash)
-	m.data.Store(name, hashStr)
-	m.revert.Store(hashStr, name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(name, string(hash))
+}
+
+func (m *HashMap) set(name, hash string) {
+	if m.data == nil {
+		m.data = make(map[string]string)
+		m.revert = make(map[string]string)
+	}
+	if oldHash, ok := m.data[name]; ok {
+		delete(m.revert, oldHash)
+	}
+	if oldName, ok := m.revert[hash]; ok {
+		delete(m.data, oldName)
+	}
+	m.data[name] = hash
+	m.revert[hash] = name
+}
+
+// CompareAndSet sets the hash of a name only if its current hash is old, a nil old means the name is not set.
+// It reports whether the hash was set.
+func (m *HashMap) CompareAndSet(name string, old, hash []byte) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.data[name]
+	if ok != (old != nil) || (ok && !bytes.Equal([]byte(val), old)) {
+		return false
+	}
+	m.set(name, string(hash))
+	return true
 }
 
 // Delete deletes the hash of a name, and accordingly deletes the name of the hash
 func (m *HashMap) Delete(key string) {
-	val, ok := m.data.Load(key)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.data[key]
 	if ok {
-		m.revert.Delete(val.(string))
-		m.data.Delete(key)
+		delete(m.revert, val)
+		delete(m.data, key)
 	}
 }
 
 // FindName returns the name of a hash
 func (m *HashMap) FindName(hash []byte) (string, bool) {
-	val, ok := m.revert.Load(string(hash))
-	if ok {
-		return val.(string), true
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	val, ok := m.revert[string(hash)]
+	return val, ok
+}
+
+// Len returns the number of names in the map
+func (m *HashMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// Range calls f for every name and its hash, until f returns false.
+// It iterates over a consistent copy of the map, so f may update the map.
+func (m *HashMap) Range(f func(name string, hash []byte) bool) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.data))
+	hashes := make([]string, 0, len(m.data))
+	for name, hash := range m.data {
+		names = append(names, name)
+		hashes = append(hashes, hash)
+	}
+	m.mu.RUnlock()
+	for i := range names {
+		if !f(names[i], []byte(hashes[i])) {
+			return
+		}
 	}
-	return "", false
 }
 
 // Drop drops the whole map and revert map
 func (m *HashMap) Drop() {
-	m.data = sync.Map{}
-	m.revert = sync.Map{}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = nil
+	m.revert = nil
 }
@@ -0,0 +1,193 @@
+package hashmap
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestHashMap_Set(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  func(m *HashMap)
+		want map[string]string
+	}{
+		{
+			name: "Test_Set",
+			ops:  func(m *HashMap) { m.Set("org1", []byte("hash1")) },
+			want: map[string]string{"org1": "hash1"},
+		},
+		{
+			name: "Test_Rebind_Name",
+			ops: func(m *HashMap) {
+				m.Set("org1", []byte("hash1"))
+				m.Set("org1", []byte("hash2"))
+			},
+			want: map[string]string{"org1": "hash2"},
+		},
+		{
+			name: "Test_Rebind_Hash",
+			ops: func(m *HashMap) {
+				m.Set("org1", []byte("hash1"))
+				m.Set("org2", []byte("hash1"))
+			},
+			want: map[string]string{"org2": "hash1"},
+		},
+		{
+			name: "Test_Delete",
+			ops: func(m *HashMap) {
+				m.Set("org1", []byte("hash1"))
+				m.Set("org2", []byte("hash2"))
+				m.Delete("org1")
+			},
+			want: map[string]string{"org2": "hash2"},
+		},
+		{
+			name: "Test_Drop",
+			ops: func(m *HashMap) {
+				m.Set("org1", []byte("hash1"))
+				m.Drop()
+				m.Set("org2", []byte("hash2"))
+			},
+			want: map[string]string{"org2": "hash2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(HashMap)
+			tt.ops(m)
+			checkEntries(t, m, tt.want)
+			if got := m.Len(); got != len(tt.want) {
+				t.Errorf("Len() got = %d, want %d", got, len(tt.want))
+			}
+			for _, stale := range []string{"hash1", "hash2"} {
+				if name, ok := m.FindName([]byte(stale)); ok && tt.want[name] != stale {
+					t.Errorf("FindName(%s) got stale name %s", stale, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHashMap_CompareAndSet(t *testing.T) {
+	type args struct {
+		old  []byte
+		hash []byte
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     bool
+		wantHash string
+	}{
+		{
+			name:     "Test_Matching_Old",
+			args:     args{old: []byte("hash1"), hash: []byte("hash2")},
+			want:     true,
+			wantHash: "hash2",
+		},
+		{
+			name:     "Test_Stale_Old",
+			args:     args{old: []byte("hash0"), hash: []byte("hash2")},
+			want:     false,
+			wantHash: "hash1",
+		},
+		{
+			name:     "Test_Nil_Old_When_Set",
+			args:     args{old: nil, hash: []byte("hash2")},
+			want:     false,
+			wantHash: "hash1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(HashMap)
+			m.Set("org1", []byte("hash1"))
+			if got := m.CompareAndSet("org1", tt.args.old, tt.args.hash); got != tt.want {
+				t.Errorf("CompareAndSet() got = %v, want %v", got, tt.want)
+			}
+			checkEntries(t, m, map[string]string{"org1": tt.wantHash})
+		})
+	}
+	m := new(HashMap)
+	if !m.CompareAndSet("org1", nil, []byte("hash1")) {
+		t.Errorf("CompareAndSet() of an unset name with nil old got = false, want true")
+	}
+}
+
+// TestHashMap_Concurrent is meant to be run with the race detector, go test -race
+func TestHashMap_Concurrent(t *testing.T) {
+	m := new(HashMap)
+	const numWorkers, numOps = 8, 200
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < numOps; j++ {
+				name := fmt.Sprintf("org%d", j%10)
+				hash := []byte(fmt.Sprintf("hash%d-%d", worker, j))
+				switch j % 6 {
+				case 0:
+					m.Set(name, hash)
+				case 1:
+					m.Get(name)
+				case 2:
+					m.FindName(hash)
+				case 3:
+					old, _ := m.Get(name)
+					m.CompareAndSet(name, old, hash)
+				case 4:
+					m.Range(func(name string, hash []byte) bool { return true })
+				case 5:
+					if j%30 == 5 {
+						m.Drop()
+					} else {
+						m.Delete(name)
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	// both directions agree after the concurrent updates
+	numNames := 0
+	m.Range(func(name string, hash []byte) bool {
+		numNames++
+		if got, ok := m.FindName(hash); !ok || got != name {
+			t.Errorf("FindName(%s) got = %s, want %s", hash, got, name)
+		}
+		return true
+	})
+	if numNames != m.Len() {
+		t.Errorf("Len() got = %d, want %d", m.Len(), numNames)
+	}
+}
+
+// TestPersistent_Concurrent is meant to be run with the race detector, go test -race
+func TestPersistent_Concurrent(t *testing.T) {
+	p, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer p.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				name := fmt.Sprintf("org%d", j%5)
+				old, _ := p.Get(name)
+				if _, err := p.CompareAndSet(name, old, []byte(fmt.Sprintf("hash%d-%d", worker, j))); err != nil {
+					t.Errorf("CompareAndSet() error = %v", err)
+				}
+				p.FindName(old)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := p.Len(); got != 5 {
+		t.Errorf("Len() got = %d, want 5", got)
+	}
+}
@@ -0,0 +1,305 @@
+package hashmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/internal/atomicfile"
+)
+
+const (
+	opSet    = "set"
+	opDelete = "del"
+
+	snapshotFile = "snapshot"
+	logFile      = "log"
+)
+
+// Op is an update of the map recorded by a backend
+type Op struct {
+	Op   string `json:"op"`
+	Name string `json:"name"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// Backend is the pluggable persistence of a Persistent map
+type Backend interface {
+	// Restore sets the persisted state in the map
+	Restore(m *HashMap) error
+	// Append records an update, it is called before the update is applied
+	Append(op Op) error
+	// Compact replaces the persisted state by a snapshot of the map
+	Compact(m *HashMap) error
+	Close() error
+}
+
+// Persistent is a HashMap whose updates are recorded by a backend
+type Persistent struct {
+	mu      sync.Mutex
+	m       *HashMap
+	backend Backend
+}
+
+// Open opens the persistent map stored in a directory by a FileBackend
+func Open(dir string) (*Persistent, error) {
+	backend, err := NewFileBackend(dir)
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewPersistent(backend)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewPersistent creates a new persistent map, restoring its state from the backend
+func NewPersistent(backend Backend) (*Persistent, error) {
+	p := &Persistent{
+		m:       new(HashMap),
+		backend: backend,
+	}
+	if err := backend.Restore(p.m); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Get returns the hash of a name
+func (p *Persistent) Get(name string) ([]byte, bool) {
+	return p.m.Get(name)
+}
+
+// FindName returns the name of a hash
+func (p *Persistent) FindName(hash []byte) (string, bool) {
+	return p.m.FindName(hash)
+}
+
+// Range calls f for every name and its hash, until f returns false
+func (p *Persistent) Range(f func(name string, hash []byte) bool) {
+	p.m.Range(f)
+}
+
+// Len returns the number of names in the map
+func (p *Persistent) Len() int {
+	return p.m.Len()
+}
+
+// Set records and sets the hash of a name
+func (p *Persistent) Set(name string, hash []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.backend.Append(Op{Op: opSet, Name: name, Hash: hex.EncodeToString(hash)}); err != nil {
+		return err
+	}
+	p.m.Set(name, hash)
+	return nil
+}
+
+// CompareAndSet records and sets the hash of a name only if its current hash is old,
+// a nil old means the name is not set. It reports whether the hash was set.
+func (p *Persistent) CompareAndSet(name string, old, hash []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// all updates hold p.mu, so the map cannot change between the check and the set
+	val, ok := p.m.Get(name)
+	if ok != (old != nil) || (ok && !bytes.Equal(val, old)) {
+		return false, nil
+	}
+	if err := p.backend.Append(Op{Op: opSet, Name: name, Hash: hex.EncodeToString(hash)}); err != nil {
+		return false, err
+	}
+	p.m.Set(name, hash)
+	return true, nil
+}
+
+// Delete records and deletes the hash of a name
+func (p *Persistent) Delete(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.backend.Append(Op{Op: opDelete, Name: name}); err != nil {
+		return err
+	}
+	p.m.Delete(name)
+	return nil
+}
+
+// ImportRoster records and sets the names in a roster file, see HashMap.ImportRoster for the format
+func (p *Persistent) ImportRoster(r io.Reader) (int, error) {
+	return p.ImportRosterWith(hashsuite.Default(), r)
+}
+
+// ImportRosterWith records and sets the names in a roster file, see HashMap.ImportRosterWith
+func (p *Persistent) ImportRosterWith(suite *hashsuite.Suite, r io.Reader) (int, error) {
+	entries, err := readRoster(suite, r)
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range entries {
+		if err = p.Set(e.name, e.hash); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
+
+// Compact replaces the persisted updates by a snapshot of the map
+func (p *Persistent) Compact() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.backend.Compact(p.m)
+}
+
+// Close closes the backend
+func (p *Persistent) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.backend.Close()
+}
+
+// FileBackend persists a map in a directory, as a snapshot and an append-only log of the updates since
+type FileBackend struct {
+	dir string
+	log *os.File
+}
+
+// NewFileBackend creates a new file backend in the directory, creating it if needed
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileBackend{dir: dir}, nil
+}
+
+// Restore loads the snapshot and replays the log.
+// A torn record at the end of the log, left by a crash while appending, is discarded.
+func (b *FileBackend) Restore(m *HashMap) error {
+	snapshot, err := os.Open(filepath.Join(b.dir, snapshotFile))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		err = m.Load(snapshot)
+		snapshot.Close()
+		if err != nil {
+			return err
+		}
+	}
+	log, err := os.OpenFile(filepath.Join(b.dir, logFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	valid, err := replay(log, m)
+	if err == nil {
+		err = log.Truncate(valid)
+	}
+	if err == nil {
+		_, err = log.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		log.Close()
+		return err
+	}
+	b.log = log
+	return nil
+}
+
+// replay applies the complete records of the log to the map, and returns the size of the complete records
+func replay(r io.Reader, m *HashMap) (int64, error) {
+	reader := bufio.NewReader(r)
+	var valid int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a record without its newline is torn
+			return valid, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		var op Op
+		if err = json.Unmarshal(bytes.TrimSpace(line), &op); err != nil {
+			// only the last record can be torn
+			if _, peekErr := reader.Peek(1); errors.Is(peekErr, io.EOF) {
+				return valid, nil
+			}
+			return 0, fmt.Errorf("hashmap: corrupted log record at offset %d: %w", valid, err)
+		}
+		if err = apply(m, op); err != nil {
+			return 0, fmt.Errorf("hashmap: corrupted log record at offset %d: %w", valid, err)
+		}
+		valid += int64(len(line))
+	}
+}
+
+func apply(m *HashMap, op Op) error {
+	switch op.Op {
+	case opSet:
+		hash, err := hex.DecodeString(op.Hash)
+		if err != nil {
+			return err
+		}
+		m.Set(op.Name, hash)
+	case opDelete:
+		m.Delete(op.Name)
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+	return nil
+}
+
+// Append appends an update to the log and syncs it
+func (b *FileBackend) Append(op Op) error {
+	if b.log == nil {
+		return errors.New("hashmap: file backend is not restored")
+	}
+	record, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	if _, err = b.log.Write(append(record, '\n')); err != nil {
+		return err
+	}
+	return b.log.Sync()
+}
+
+// Compact atomically replaces the snapshot by the map and then truncates the log.
+// Replaying the log on top of the new snapshot after a crash in between gives the same map.
+func (b *FileBackend) Compact(m *HashMap) error {
+	if b.log == nil {
+		return errors.New("hashmap: file backend is not restored")
+	}
+	// the snapshot must be durable before the log is truncated
+	if err := atomicfile.WriteFile(filepath.Join(b.dir, snapshotFile), m.Save); err != nil {
+		return err
+	}
+	if err := b.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := b.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return b.log.Sync()
+}
+
+// Close closes the log
+func (b *FileBackend) Close() error {
+	if b.log == nil {
+		return nil
+	}
+	err := b.log.Close()
+	b.log = nil
+	return err
+}
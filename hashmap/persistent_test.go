@@ -0,0 +1,184 @@
+package hashmap
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+func TestPersistent_Restore(t *testing.T) {
+	dir := t.TempDir()
+	p, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	mustSet(t, p, "org1", []byte("hash1"))
+	mustSet(t, p, "org2", []byte("hash2"))
+	mustSet(t, p, "org3", []byte("hash3"))
+	if err = p.Delete("org2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err = p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	// a crash while appending leaves a torn record at the end of the log
+	log, err := os.OpenFile(filepath.Join(dir, logFile), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = log.WriteString(`{"op":"set","name":"org4","ha`); err != nil {
+		t.Fatal(err)
+	}
+	log.Close()
+
+	p, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	checkEntries(t, p, map[string]string{"org1": "hash1", "org3": "hash3"})
+	// the torn record is discarded and the log is appendable again
+	mustSet(t, p, "org4", []byte("hash4"))
+	p.Close()
+	p, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer p.Close()
+	checkEntries(t, p, map[string]string{"org1": "hash1", "org3": "hash3", "org4": "hash4"})
+}
+
+func TestPersistent_Compact(t *testing.T) {
+	dir := t.TempDir()
+	p, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	mustSet(t, p, "org1", []byte("hash1"))
+	mustSet(t, p, "org2", []byte("hash2"))
+	if err = p.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("Compact() left %d bytes in the log", info.Size())
+	}
+	mustSet(t, p, "org3", []byte("hash3"))
+	p.Close()
+
+	p, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer p.Close()
+	checkEntries(t, p, map[string]string{"org1": "hash1", "org2": "hash2", "org3": "hash3"})
+}
+
+func TestHashMap_ImportRoster(t *testing.T) {
+	orgHash := sha256.Sum256([]byte("org1"))
+	orgOneHash := sha256.Sum256([]byte("Org One"))
+	tests := []struct {
+		name    string
+		roster  string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "Test_Names_And_Hashes",
+			roster: "# consortium roster\norg1\n\norg2, 68617368\n",
+			want:   map[string]string{"org1": string(orgHash[:]), "org2": "hash"},
+		},
+		{
+			name:   "Test_Inner_Whitespace",
+			roster: "Org One\n",
+			want:   map[string]string{"Org One": string(orgOneHash[:])},
+		},
+		{
+			name:    "Test_Leading_Whitespace",
+			roster:  "org1\n org2\n",
+			wantErr: "line 2",
+		},
+		{
+			name:    "Test_Trailing_Whitespace",
+			roster:  "org1 ,68617368\n",
+			wantErr: "line 1",
+		},
+		{
+			name:    "Test_Invalid_Hash",
+			roster:  "org1\norg2,zz\n",
+			wantErr: "line 2",
+		},
+		{
+			name:    "Test_Too_Many_Fields",
+			roster:  "org1,00,00\n",
+			wantErr: "line 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(HashMap)
+			n, err := m.ImportRoster(strings.NewReader(tt.roster))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ImportRoster() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportRoster() error = %v", err)
+			}
+			if n != len(tt.want) {
+				t.Errorf("ImportRoster() got = %d, want %d", n, len(tt.want))
+			}
+			checkEntries(t, m, tt.want)
+		})
+	}
+}
+
+func TestHashMap_ImportRosterWith(t *testing.T) {
+	suite := hashsuite.SHA3_256
+	m := new(HashMap)
+	if _, err := m.ImportRosterWith(suite, strings.NewReader("org1\n")); err != nil {
+		t.Fatalf("ImportRosterWith() error = %v", err)
+	}
+	checkEntries(t, m, map[string]string{"org1": string(suite.Sum(hashsuite.Name, []byte("org1")))})
+}
+
+type reader interface {
+	Get(name string) ([]byte, bool)
+	FindName(hash []byte) (string, bool)
+	Range(f func(name string, hash []byte) bool)
+}
+
+func checkEntries(t *testing.T, m reader, want map[string]string) {
+	t.Helper()
+	got := make(map[string]string)
+	m.Range(func(name string, hash []byte) bool {
+		got[name] = string(hash)
+		return true
+	})
+	if len(got) != len(want) {
+		t.Errorf("Range() got = %v, want %v", got, want)
+	}
+	for name, hash := range want {
+		if val, ok := m.Get(name); !ok || string(val) != hash {
+			t.Errorf("Get(%s) got = %q, want %q", name, val, hash)
+		}
+		if val, ok := m.FindName([]byte(hash)); !ok || val != name {
+			t.Errorf("FindName(%q) got = %s, want %s", hash, val, name)
+		}
+	}
+}
+
+func mustSet(t *testing.T, p *Persistent, name string, hash []byte) {
+	t.Helper()
+	if err := p.Set(name, hash); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+}
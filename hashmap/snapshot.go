@@ -0,0 +1,115 @@
+package hashmap
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+// entry is the JSON form of a name and its hash in snapshots
+type entry struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// Save writes a snapshot of the map to w, one JSON entry per line
+func (m *HashMap) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	var err error
+	m.Range(func(name string, hash []byte) bool {
+		err = encoder.Encode(entry{Name: name, Hash: hex.EncodeToString(hash)})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Load reads a snapshot written by Save from r and sets its entries in the map
+func (m *HashMap) Load(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var e entry
+		err := decoder.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		hash, err := hex.DecodeString(e.Hash)
+		if err != nil {
+			return err
+		}
+		m.Set(e.Name, hash)
+	}
+}
+
+// ImportRoster sets the names in a roster file in the map, and returns the number of names imported.
+// Each line of the roster is "name" or "name,hex_hash", empty lines and lines starting with '#' are skipped.
+// Names are kept as they are, since their hashes cover their exact bytes, and names with whitespace around them
+// are rejected; whitespace around the hash is ignored.
+// When the hash is omitted, it is the name hash of the default hash suite, as used by transaction.Plain.Hide.
+func (m *HashMap) ImportRoster(r io.Reader) (int, error) {
+	return m.ImportRosterWith(hashsuite.Default(), r)
+}
+
+// ImportRosterWith imports a roster file as ImportRoster, hashing the names without a hash with the hash suite
+func (m *HashMap) ImportRosterWith(suite *hashsuite.Suite, r io.Reader) (int, error) {
+	entries, err := readRoster(suite, r)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		m.Set(e.name, e.hash)
+	}
+	return len(entries), nil
+}
+
+type rosterEntry struct {
+	name string
+	hash []byte
+}
+
+func readRoster(suite *hashsuite.Suite, r io.Reader) ([]rosterEntry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	var entries []rosterEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		name := record[0]
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("hashmap: roster line %d: empty name", line)
+		case strings.TrimSpace(name) != name:
+			return nil, fmt.Errorf("hashmap: roster line %d: whitespace around name %q", line, name)
+		case len(record) == 1:
+			entries = append(entries, rosterEntry{name: name, hash: suite.Sum(hashsuite.Name, []byte(name))})
+		case len(record) == 2:
+			hash, err := hex.DecodeString(strings.TrimSpace(record[1]))
+			if err != nil {
+				return nil, fmt.Errorf("hashmap: roster line %d: %w", line, err)
+			}
+			entries = append(entries, rosterEntry{name: name, hash: hash})
+		default:
+			return nil, fmt.Errorf("hashmap: roster line %d: %d fields, want 1 or 2", line, len(record))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package hashsuite
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Purpose is the purpose of a hash, the domain tag of the hash in a domain separated suite
+type Purpose string
+
+const (
+	// Name is the purpose of the pseudonym hashes of senders and receivers
+	Name Purpose = "name"
+	// Blinding is the purpose of the hashes of timestamps and counters in commitment blindings
+	Blinding Purpose = "blinding"
+	// Key is the purpose of the keys of on-chain records
+	Key Purpose = "key"
+)
+
+// tagDomain prefixes the domain tags of all purposes
+const tagDomain = "auti-core/"
+
+// Suite is the hash function of transaction pseudonyms, commitment blindings and the keys of on-chain records.
+// The default suite is plain SHA-256 as the records have always been hashed, so that they keep their keys;
+// every other suite tags each hash with its purpose. A nil suite is the default suite.
+// Suites of other hash functions are created with New.
+type Suite struct {
+	name      string
+	newHash   func() hash.Hash
+	separated bool
+}
+
+var (
+	// SHA256 is plain SHA-256 without domain tags, the default suite
+	SHA256 = &Suite{name: "sha256", newHash: sha256.New}
+	// SHA256Tagged is SHA-256 with domain tags
+	SHA256Tagged = New("sha256-tagged", sha256.New)
+	// SHA512_256 is SHA-512/256 with domain tags, faster than SHA-256 on 64-bit platforms without SHA extensions
+	SHA512_256 = New("sha512/256", sha512.New512_256)
+	// SHA512 is SHA-512 with domain tags
+	SHA512 = New("sha512", sha512.New)
+	// SHA3_256 is SHA3-256 with domain tags
+	SHA3_256 = New("sha3-256", sha3.New256)
+	// SHA3_512 is SHA3-512 with domain tags
+	SHA3_512 = New("sha3-512", sha3.New512)
+	// BLAKE2b_256 is BLAKE2b-256 with domain tags
+	BLAKE2b_256 = New("blake2b-256", newBLAKE2b(blake2b.New256))
+	// BLAKE2b_512 is BLAKE2b-512 with domain tags
+	BLAKE2b_512 = New("blake2b-512", newBLAKE2b(blake2b.New512))
+)
+
+// newBLAKE2b returns the constructor of an unkeyed BLAKE2b hash, which never fails
+func newBLAKE2b(newKeyed func(key []byte) (hash.Hash, error)) func() hash.Hash {
+	return func() hash.Hash {
+		h, err := newKeyed(nil)
+		if err != nil {
+			panic(err)
+		}
+		return h
+	}
+}
+
+// Default returns the default suite, SHA256
+func Default() *Suite {
+	return SHA256
+}
+
+// New creates a new domain separated suite of the hash function
+func New(name string, newHash func() hash.Hash) *Suite {
+	return &Suite{name: name, newHash: newHash, separated: true}
+}
+
+// Name returns the name of the suite
+func (s *Suite) Name() string {
+	if s == nil {
+		s = Default()
+	}
+	return s.name
+}
+
+// Size returns the size of the hashes of the suite
+func (s *Suite) Size() int {
+	if s == nil {
+		s = Default()
+	}
+	return s.newHash().Size()
+}
+
+// New returns a new hash of the purpose, with the domain tag already written if the suite is domain separated
+func (s *Suite) New(purpose Purpose) hash.Hash {
+	if s == nil {
+		s = Default()
+	}
+	h := s.newHash()
+	if s.separated {
+		// the tag is length-prefixed, so that no tag is a prefix of another tag followed by data
+		tag := tagDomain + string(purpose)
+		h.Write([]byte{byte(len(tag))})
+		h.Write([]byte(tag))
+	}
+	return h
+}
+
+// Sum returns the hash of the purpose of the concatenated data
+func (s *Suite) Sum(purpose Purpose, data ...[]byte) []byte {
+	h := s.New(purpose)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
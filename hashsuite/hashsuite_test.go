@@ -0,0 +1,59 @@
+package hashsuite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSuite_Sum(t *testing.T) {
+	// the default suite is plain SHA-256, FIPS 180-2 "abc"
+	const sha256ABC = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	tests := []struct {
+		name    string
+		suite   *Suite
+		purpose Purpose
+		data    [][]byte
+		want    string
+	}{
+		{"Test_Default_Name", SHA256, Name, [][]byte{[]byte("abc")}, sha256ABC},
+		{"Test_Default_Key", SHA256, Key, [][]byte{[]byte("abc")}, sha256ABC},
+		{"Test_Nil_Suite", nil, Blinding, [][]byte{[]byte("a"), []byte("bc")}, sha256ABC},
+		{"Test_Tagged_Name", SHA256Tagged, Name, [][]byte{[]byte("abc")},
+			"3ec273b5addd4d6b9f69887058d84abd8e22c6310e0b81276b1051df87eb3016"},
+		{"Test_Tagged_Key", SHA256Tagged, Key, [][]byte{[]byte("abc")},
+			"68bfca6bc5810dbb4b11bd50c8c68bd7036d231ea8877c276c39332b89e2d886"},
+		{"Test_Sha512256_Name", SHA512_256, Name, [][]byte{[]byte("abc")},
+			"458d8c7284d1b16fda3607df144ddb9af83ead9641a8dafff2b99f7f29e56ced"},
+		{"Test_Sha512256_Key", SHA512_256, Key, [][]byte{[]byte("abc")},
+			"59238aa7f5f952cc33f5e286d5eb2758153e9cd7ca484c29af1f23daeeee768f"},
+		{"Test_Sha3256_Name", SHA3_256, Name, [][]byte{[]byte("abc")},
+			"6493d8d1a2b452c82bfe30fc9871569ab585845d6659914c0e412fb83fb45b18"},
+		{"Test_Sha3512_Key", SHA3_512, Key, [][]byte{[]byte("abc")},
+			"e745534ebd8b7abe8d3f499891b4ad9203f40b29b8768cc0720e80d45b3506192020e6888074bbf4dbb72bc6c102af716162f38b66cd0be005535e4e1109e73b"},
+		{"Test_Blake2b256_Name", BLAKE2b_256, Name, [][]byte{[]byte("abc")},
+			"212a700a812179cbc3b5b18e26b6b155f4adc3e6a64a8e7379a68bdd24c7b615"},
+		{"Test_Blake2b512_Name", BLAKE2b_512, Name, [][]byte{[]byte("abc")},
+			"335394b794125de0ec4f42044c90471b33b8a295f9965f102b16c066b601cc80d10fc8a132277e1081f5148e223661dea2b4acddafd37429281c8fd4170f12eb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.suite.Sum(tt.purpose, tt.data...)); got != tt.want {
+				t.Errorf("Sum() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	suite := New("custom", sha256.New)
+	if suite.Name() != "custom" || suite.Size() != sha256.Size {
+		t.Errorf("New() name = %s, size = %d", suite.Name(), suite.Size())
+	}
+	if got, want := suite.Sum(Name, []byte("abc")), SHA256Tagged.Sum(Name, []byte("abc")); string(got) != string(want) {
+		t.Errorf("Sum() = %x, want %x", got, want)
+	}
+	if SHA512.Size() != 64 || (*Suite)(nil).Name() != Default().Name() {
+		t.Errorf("Size() of %s = %d, Name() of nil = %s", SHA512.Name(), SHA512.Size(), (*Suite)(nil).Name())
+	}
+}
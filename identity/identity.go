@@ -0,0 +1,173 @@
+package identity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+// Role is the role of an entity in the consortium
+type Role string
+
+const (
+	// RoleOrganization is the role of an organization running local chains
+	RoleOrganization Role = "organization"
+	// RoleAuditor is the role of an auditor
+	RoleAuditor Role = "auditor"
+)
+
+var (
+	// ErrUnknownEntity is returned when looking up an entity that is not in the registry
+	ErrUnknownEntity = errors.New("identity: unknown entity")
+	// ErrDuplicateEntity is returned when adding an entity whose ID is already in the registry
+	ErrDuplicateEntity = errors.New("identity: duplicate entity")
+)
+
+// Entity is an organization or auditor of the consortium
+type Entity struct {
+	ID          string
+	DisplayName string
+	// Pseudonym is the hash of the ID under the hash suite of the deployment,
+	// as used for the sender and receiver of hidden transactions
+	Pseudonym []byte
+	PublicKey *ed25519.Point
+	Role      Role
+}
+
+// NewEntity creates a new entity, deriving its pseudonym from the ID with the default hash suite
+func NewEntity(id, displayName string, publicKey *ed25519.Point, role Role) *Entity {
+	return NewEntityWith(hashsuite.Default(), id, displayName, publicKey, role)
+}
+
+// NewEntityWith creates a new entity, deriving its pseudonym from the ID with the hash suite
+func NewEntityWith(suite *hashsuite.Suite, id, displayName string, publicKey *ed25519.Point, role Role) *Entity {
+	return &Entity{
+		ID:          id,
+		DisplayName: displayName,
+		Pseudonym:   PseudonymWith(suite, id),
+		PublicKey:   publicKey,
+		Role:        role,
+	}
+}
+
+// Pseudonym returns the pseudonym hash of an ID with the default hash suite, the same hash as transaction.Plain.Hide
+func Pseudonym(id string) []byte {
+	return PseudonymWith(hashsuite.Default(), id)
+}
+
+// PseudonymWith returns the pseudonym hash of an ID with the hash suite, the same hash as transaction.Plain.HideWith
+// with commitment parameters of the suite
+func PseudonymWith(suite *hashsuite.Suite, id string) []byte {
+	return suite.Sum(hashsuite.Name, []byte(id))
+}
+
+func (e *Entity) validate(suite *hashsuite.Suite) error {
+	if e.ID == "" {
+		return errors.New("identity: empty entity ID")
+	}
+	if e.Role != RoleOrganization && e.Role != RoleAuditor {
+		return fmt.Errorf("identity: entity %s has unknown role %q", e.ID, e.Role)
+	}
+	if e.PublicKey == nil {
+		return fmt.Errorf("identity: entity %s has no public key", e.ID)
+	}
+	if !bytes.Equal(e.Pseudonym, PseudonymWith(suite, e.ID)) {
+		return fmt.Errorf("identity: entity %s has a pseudonym that is not the hash of its ID", e.ID)
+	}
+	return nil
+}
+
+// Registry is the registry of the organizations and auditors of the consortium, it is safe for concurrent use
+type Registry struct {
+	mu          sync.RWMutex
+	suite       *hashsuite.Suite
+	byID        map[string]*Entity
+	byPseudonym map[string]*Entity
+}
+
+// NewRegistry creates a new empty registry of the pseudonyms of the default hash suite
+func NewRegistry() *Registry {
+	return NewRegistryWith(hashsuite.Default())
+}
+
+// NewRegistryWith creates a new empty registry of the pseudonyms of the hash suite
+func NewRegistryWith(suite *hashsuite.Suite) *Registry {
+	return &Registry{
+		suite:       suite,
+		byID:        make(map[string]*Entity),
+		byPseudonym: make(map[string]*Entity),
+	}
+}
+
+// Suite returns the hash suite of the pseudonyms of the registry
+func (r *Registry) Suite() *hashsuite.Suite {
+	return r.suite
+}
+
+// Add adds an entity to the registry, whose pseudonym must be of the hash suite of the registry
+func (r *Registry) Add(e *Entity) error {
+	if err := e.validate(r.suite); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byID[e.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateEntity, e.ID)
+	}
+	r.byID[e.ID] = e
+	r.byPseudonym[string(e.Pseudonym)] = e
+	return nil
+}
+
+// Lookup returns the entity of an ID
+func (r *Registry) Lookup(id string) (*Entity, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.byID[id]
+	return e, ok
+}
+
+// LookupPseudonym returns the entity of a pseudonym hash
+func (r *Registry) LookupPseudonym(pseudonym []byte) (*Entity, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.byPseudonym[string(pseudonym)]
+	return e, ok
+}
+
+// PublicKey returns the public key of an ID, for verifying digests and auditing records
+func (r *Registry) PublicKey(id string) (*ed25519.Point, error) {
+	e, ok := r.Lookup(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEntity, id)
+	}
+	return e.PublicKey, nil
+}
+
+// PublicKeyByPseudonym returns the public key of the organization of a pseudonym hash, for verifying transactions
+func (r *Registry) PublicKeyByPseudonym(pseudonym []byte) (*ed25519.Point, error) {
+	e, ok := r.LookupPseudonym(pseudonym)
+	if !ok || e.Role != RoleOrganization {
+		return nil, fmt.Errorf("%w: pseudonym %x", ErrUnknownEntity, pseudonym)
+	}
+	return e.PublicKey, nil
+}
+
+// Entities returns the entities of the registry sorted by ID
+func (r *Registry) Entities() []*Entity {
+	r.mu.RLock()
+	entities := make([]*Entity, 0, len(r.byID))
+	for _, e := range r.byID {
+		entities = append(entities, e)
+	}
+	r.mu.RUnlock()
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].ID < entities[j].ID
+	})
+	return entities
+}
@@ -0,0 +1,304 @@
+package identity
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/auditing"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/digest"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+type testEntity struct {
+	entity     *Entity
+	privateKey *ed25519.Scalar
+}
+
+// generators returns the generators of the commitments, of which the discrete log of h with respect to g is unknown
+func generators(t *testing.T) (g, h *ed25519.Point) {
+	t.Helper()
+	g, err := autied25519.HashToPoint([]byte("auti-core identity test g"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	h, err = autied25519.HashToPoint([]byte("auti-core identity test h"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	return g, h
+}
+
+func registrySetup(t *testing.T) (*Registry, map[string]*testEntity) {
+	t.Helper()
+	registry := NewRegistry()
+	entities := make(map[string]*testEntity)
+	for _, e := range []struct {
+		id   string
+		role Role
+	}{
+		{"org1", RoleOrganization},
+		{"org2", RoleOrganization},
+		{"auditor1", RoleAuditor},
+	} {
+		publicKey, privateKey, err := autied25519.KeyGen()
+		if err != nil {
+			t.Fatalf("KeyGen() error = %v", err)
+		}
+		entity := NewEntity(e.id, "Display "+e.id, publicKey, e.role)
+		if err = registry.Add(entity); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		entities[e.id] = &testEntity{entity: entity, privateKey: privateKey}
+	}
+	return registry, entities
+}
+
+func TestParseRoster(t *testing.T) {
+	registry, _ := registrySetup(t)
+	consortiumKey, consortiumPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	otherKey, _, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	roster, err := registry.SignRoster(consortiumPrivateKey)
+	if err != nil {
+		t.Fatalf("SignRoster() error = %v", err)
+	}
+	tampered := bytes.Replace(roster, []byte("Display org1"), []byte("Display org3"), 1)
+	tests := []struct {
+		name    string
+		roster  []byte
+		key     *ed25519.Point
+		wantErr error
+	}{
+		{
+			name:   "Test_Valid_Roster",
+			roster: roster,
+			key:    consortiumKey,
+		},
+		{
+			name:    "Test_Wrong_Consortium_Key",
+			roster:  roster,
+			key:     otherKey,
+			wantErr: ErrInvalidRoster,
+		},
+		{
+			name:    "Test_Tampered_Roster",
+			roster:  tampered,
+			key:     consortiumKey,
+			wantErr: ErrInvalidRoster,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRoster(tt.roster, tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseRoster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, want := range registry.Entities() {
+				e, ok := got.LookupPseudonym(want.Pseudonym)
+				if !ok || e.ID != want.ID || e.DisplayName != want.DisplayName || e.Role != want.Role ||
+					e.PublicKey.Equal(want.PublicKey) != 1 {
+					t.Errorf("ParseRoster() got = %+v, want %+v", e, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_Verify(t *testing.T) {
+	registry, entities := registrySetup(t)
+	org1, org2, auditor := entities["org1"], entities["org2"], entities["auditor1"]
+	g, h := ed25519.NewGeneratorPoint(), new(ed25519.Point).ScalarBaseMult(org2.privateKey)
+	plain := transaction.NewPlain("org1", "org2", 100)
+	h1, h2, err := plain.HidePairSigned(0, g, h, org1.privateKey)
+	if err != nil {
+		t.Fatalf("HidePairSigned() error = %v", err)
+	}
+	if err = h2.Sign(org2.privateKey); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	forged, err := plain.HideSigned(1, g, h, false, auditor.privateKey)
+	if err != nil {
+		t.Fatalf("HideSigned() error = %v", err)
+	}
+	// the receiver signs a record with the sender as its originator
+	byReceiver, err := plain.HideSigned(3, g, h, false, org2.privateKey)
+	if err != nil {
+		t.Fatalf("HideSigned() error = %v", err)
+	}
+	outsider, err := transaction.NewPlain("org3", "org4", 100).HideSigned(2, g, h, false, org1.privateKey)
+	if err != nil {
+		t.Fatalf("HideSigned() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		verify  func() error
+		wantErr error
+	}{
+		{
+			name:   "Test_Transaction",
+			verify: func() error { return h1.VerifyWith(registry) },
+		},
+		{
+			name:   "Test_Transaction_Pair",
+			verify: func() error { return h2.VerifyWith(registry) },
+		},
+		{
+			name: "Test_Transaction_On_Chain",
+			verify: func() error {
+				_, err := h1.ToOnChain().ToHideVerifiedWith(registry)
+				return err
+			},
+		},
+		{
+			name:    "Test_Transaction_Wrong_Signer",
+			verify:  func() error { return forged.VerifyWith(registry) },
+			wantErr: transaction.ErrInvalidSignature,
+		},
+		{
+			name:    "Test_Transaction_Receiver_Signer",
+			verify:  func() error { return byReceiver.VerifyWith(registry) },
+			wantErr: transaction.ErrInvalidSignature,
+		},
+		{
+			name: "Test_Transaction_Receiver_Signer_On_Chain",
+			verify: func() error {
+				_, err := byReceiver.ToOnChain().ToHideVerifiedWith(registry)
+				return err
+			},
+			wantErr: transaction.ErrInvalidSignature,
+		},
+		{
+			name:    "Test_Transaction_Unknown_Parties",
+			verify:  func() error { return outsider.VerifyWith(registry) },
+			wantErr: ErrUnknownEntity,
+		},
+		{
+			name: "Test_Digest",
+			verify: func() error {
+				d := digest.NewDigest([]byte("root"), "org2")
+				if err := d.Sign(org2.privateKey); err != nil {
+					return err
+				}
+				return d.VerifyWith(registry)
+			},
+		},
+		{
+			name: "Test_Digest_Wrong_Signer",
+			verify: func() error {
+				d := digest.NewDigest([]byte("root"), "org2")
+				if err := d.Sign(org1.privateKey); err != nil {
+					return err
+				}
+				return d.VerifyWith(registry)
+			},
+			wantErr: digest.ErrInvalidSignature,
+		},
+		{
+			name: "Test_Auditing_Record",
+			verify: func() error {
+				r := auditing.NewRecord([]byte("payload"), 1, "auditor1")
+				if err := r.Sign(auditor.privateKey); err != nil {
+					return err
+				}
+				return r.VerifyWith(registry)
+			},
+		},
+		{
+			name: "Test_Auditing_Record_Unsigned",
+			verify: func() error {
+				return auditing.NewRecord([]byte("payload"), 1, "auditor1").VerifyWith(registry)
+			},
+			wantErr: auditing.ErrUnsigned,
+		},
+		{
+			name: "Test_Auditing_Record_Unknown_Auditor",
+			verify: func() error {
+				r := auditing.NewRecord([]byte("payload"), 1, "auditor2")
+				if err := r.Sign(auditor.privateKey); err != nil {
+					return err
+				}
+				return r.VerifyWith(registry)
+			},
+			wantErr: ErrUnknownEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.verify(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verify error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryWith_Suite(t *testing.T) {
+	suite := hashsuite.SHA512_256
+	publicKey, privateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	registry := NewRegistryWith(suite)
+	if err = registry.Add(NewEntityWith(suite, "org1", "Display org1", publicKey, RoleOrganization)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err = registry.Add(NewEntity("org2", "Display org2", publicKey, RoleOrganization)); err == nil {
+		t.Errorf("Add() of a pseudonym of another suite error = nil")
+	}
+	consortiumKey, consortiumPrivateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	roster, err := registry.SignRoster(consortiumPrivateKey)
+	if err != nil {
+		t.Fatalf("SignRoster() error = %v", err)
+	}
+	parsed, err := ParseRosterWith(suite, roster, consortiumKey)
+	if err != nil {
+		t.Fatalf("ParseRosterWith() error = %v", err)
+	}
+	g, h := generators(t)
+	params := &commitment.Params{G: g, H: h, Suite: suite}
+	hidden, err := transaction.NewPlain("org1", "org2", 100).HideWith(0, params, false)
+	if err != nil {
+		t.Fatalf("HideWith() error = %v", err)
+	}
+	if err = hidden.Sign(privateKey); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	defaultRegistry := NewRegistry()
+	if err = defaultRegistry.Add(NewEntity("org1", "Display org1", publicKey, RoleOrganization)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		registry *Registry
+		wantErr  error
+	}{
+		{"Test_Suite_Registry", registry, nil},
+		{"Test_Suite_Roster", parsed, nil},
+		{"Test_Default_Registry", defaultRegistry, ErrUnknownEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := hidden.VerifyWith(tt.registry); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err = ParseRoster(roster, consortiumKey); err == nil {
+		t.Errorf("ParseRoster() of a roster of another suite error = nil")
+	}
+}
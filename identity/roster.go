@@ -0,0 +1,117 @@
+package identity
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/schnorr"
+)
+
+// rosterDomain separates roster signatures from other messages signed with the same key
+const rosterDomain = "auti-core/identity/roster"
+
+// ErrInvalidRoster is returned when the roster signature does not verify against the consortium key
+var ErrInvalidRoster = errors.New("identity: invalid roster signature")
+
+// Roster is the serialized form of a registry, signed by the consortium
+type Roster struct {
+	Entities  []RosterEntry `json:"entities"`
+	Signature string        `json:"sig"`
+}
+
+// RosterEntry is the serialized form of an entity
+type RosterEntry struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	Pseudonym   string `json:"pseudonym"`
+	PublicKey   string `json:"public_key"`
+	Role        Role   `json:"role"`
+}
+
+// SignRoster serializes the registry as a roster signed with the consortium private key
+func (r *Registry) SignRoster(privateKey *ed25519.Scalar) ([]byte, error) {
+	entities := r.Entities()
+	roster := &Roster{Entities: make([]RosterEntry, len(entities))}
+	for i, e := range entities {
+		roster.Entities[i] = RosterEntry{
+			ID:          e.ID,
+			DisplayName: e.DisplayName,
+			Pseudonym:   hex.EncodeToString(e.Pseudonym),
+			PublicKey:   hex.EncodeToString(e.PublicKey.Bytes()),
+			Role:        e.Role,
+		}
+	}
+	message, err := roster.signedBytes()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := schnorr.Sign(privateKey, message)
+	if err != nil {
+		return nil, err
+	}
+	roster.Signature = hex.EncodeToString(signature)
+	return json.Marshal(roster)
+}
+
+// ParseRoster parses a roster, verifies its signature against the consortium public key and creates its registry
+// of the pseudonyms of the default hash suite
+func ParseRoster(data []byte, publicKey *ed25519.Point) (*Registry, error) {
+	return ParseRosterWith(hashsuite.Default(), data, publicKey)
+}
+
+// ParseRosterWith parses a roster as ParseRoster, creating its registry of the pseudonyms of the hash suite
+func ParseRosterWith(suite *hashsuite.Suite, data []byte, publicKey *ed25519.Point) (*Registry, error) {
+	roster := new(Roster)
+	if err := json.Unmarshal(data, roster); err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(roster.Signature)
+	if err != nil {
+		return nil, ErrInvalidRoster
+	}
+	message, err := roster.signedBytes()
+	if err != nil {
+		return nil, err
+	}
+	if err = schnorr.Verify(publicKey, message, signature); err != nil {
+		return nil, ErrInvalidRoster
+	}
+	registry := NewRegistryWith(suite)
+	for _, entry := range roster.Entities {
+		pseudonym, err := hex.DecodeString(entry.Pseudonym)
+		if err != nil {
+			return nil, err
+		}
+		keyBytes, err := hex.DecodeString(entry.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := new(ed25519.Point).SetBytes(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		err = registry.Add(&Entity{
+			ID:          entry.ID,
+			DisplayName: entry.DisplayName,
+			Pseudonym:   pseudonym,
+			PublicKey:   key,
+			Role:        entry.Role,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// signedBytes returns the message covered by the signature, the JSON encoding of the entities
+func (r *Roster) signedBytes() ([]byte, error) {
+	entities, err := json.Marshal(r.Entities)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(rosterDomain), entities...), nil
+}
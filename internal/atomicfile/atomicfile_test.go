@@ -0,0 +1,53 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	errWrite := errors.New("write failed")
+	tests := []struct {
+		name    string
+		write   func(w io.Writer) error
+		want    string
+		wantErr error
+	}{
+		{"Test_Replace", func(w io.Writer) error {
+			_, err := io.WriteString(w, "new")
+			return err
+		}, "new", nil},
+		{"Test_Write_Error", func(w io.Writer) error {
+			if _, err := io.WriteString(w, "partial"); err != nil {
+				return err
+			}
+			return errWrite
+		}, "old", errWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+			if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() error = %v", err)
+			}
+			if err := WriteFile(path, tt.write); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("os.ReadFile() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("WriteFile() content = %q, want %q", got, tt.want)
+			}
+			// the temporary file is removed
+			if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+				t.Errorf("ReadDir() = %v, %v, want only the file", entries, err)
+			}
+		})
+	}
+}
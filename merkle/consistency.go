@@ -0,0 +1,143 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ConsistencyProof is the proof that the tree of the second size extends the tree of the first size,
+// the consistency proof of RFC 6962
+type ConsistencyProof struct {
+	First  uint64
+	Second uint64
+	Path   [][]byte
+}
+
+// RootAt returns the root of the tree when it had the size
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, ErrIndexOutOfRange
+	}
+	return t.subtree(0, size), nil
+}
+
+// ProveConsistency returns the consistency proof between the trees of the first and the second sizes
+func (t *Tree) ProveConsistency(first, second uint64) (*ConsistencyProof, error) {
+	if first > second || second > t.Size() {
+		return nil, ErrIndexOutOfRange
+	}
+	proof := &ConsistencyProof{First: first, Second: second}
+	if first > 0 && first < second {
+		proof.Path = t.subproof(first, 0, second, true)
+	}
+	return proof, nil
+}
+
+// subproof returns the consistency path of the first m leaves of the subtree [begin, end),
+// complete is whether the subtree of the m leaves is a subtree of the first tree
+func (t *Tree) subproof(m, begin, end uint64, complete bool) [][]byte {
+	if m == end-begin {
+		if complete {
+			return nil
+		}
+		return [][]byte{t.subtree(begin, end)}
+	}
+	k := splitPoint(end - begin)
+	if m <= k {
+		return append(t.subproof(m, begin, begin+k, complete), t.subtree(begin+k, end))
+	}
+	return append(t.subproof(m-k, begin+k, end, false), t.subtree(begin, begin+k))
+}
+
+// VerifyConsistency verifies that the tree of the second root extends the tree of the first root, as in RFC 9162
+func VerifyConsistency(firstRoot, secondRoot []byte, proof *ConsistencyProof) error {
+	first, second := proof.First, proof.Second
+	switch {
+	case first > second:
+		return ErrIndexOutOfRange
+	case first == second:
+		if len(proof.Path) != 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return ErrInvalidProof
+		}
+		return nil
+	case first == 0:
+		// every tree extends the empty tree
+		if len(proof.Path) != 0 || !bytes.Equal(firstRoot, EmptyRoot()) {
+			return ErrInvalidProof
+		}
+		return nil
+	}
+	path := proof.Path
+	// the first tree is a complete subtree, whose root starts the path
+	if first&(first-1) == 0 {
+		path = append([][]byte{firstRoot}, path...)
+	}
+	if len(path) == 0 {
+		return ErrInvalidProof
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// Bytes returns the serialized proof, the first and the second sizes as uint64 followed by the path
+func (p *ConsistencyProof) Bytes() []byte {
+	return encodeProof(p.First, p.Second, p.Path)
+}
+
+// ParseConsistencyProof parses a serialized consistency proof
+func ParseConsistencyProof(data []byte) (*ConsistencyProof, error) {
+	first, second, path, err := parseProof(data)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsistencyProof{First: first, Second: second, Path: path}, nil
+}
+
+// encodeProof serializes two uint64 followed by the hashes of a path
+func encodeProof(a, b uint64, path [][]byte) []byte {
+	buf := make([]byte, 0, 16+len(path)*HashSize)
+	buf = binary.BigEndian.AppendUint64(buf, a)
+	buf = binary.BigEndian.AppendUint64(buf, b)
+	for _, hash := range path {
+		buf = append(buf, hash...)
+	}
+	return buf
+}
+
+// parseProof parses a proof serialized by encodeProof
+func parseProof(data []byte) (uint64, uint64, [][]byte, error) {
+	if len(data) < 16 || (len(data)-16)%HashSize != 0 {
+		return 0, 0, nil, fmt.Errorf("%w: malformed proof of %d bytes", ErrInvalidProof, len(data))
+	}
+	path := make([][]byte, (len(data)-16)/HashSize)
+	for i := range path {
+		path[i] = append([]byte{}, data[16+i*HashSize:16+(i+1)*HashSize]...)
+	}
+	return binary.BigEndian.Uint64(data), binary.BigEndian.Uint64(data[8:]), path, nil
+}
@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVerifyConsistency(t *testing.T) {
+	tree := testTree(t, 21)
+	for second := uint64(0); second <= tree.Size(); second++ {
+		secondRoot, err := tree.RootAt(second)
+		if err != nil {
+			t.Fatalf("RootAt() error = %v", err)
+		}
+		for first := uint64(0); first <= second; first++ {
+			firstRoot, err := tree.RootAt(first)
+			if err != nil {
+				t.Fatalf("RootAt() error = %v", err)
+			}
+			proof, err := tree.ProveConsistency(first, second)
+			if err != nil {
+				t.Fatalf("ProveConsistency() error = %v", err)
+			}
+			if err = VerifyConsistency(firstRoot, secondRoot, proof); err != nil {
+				t.Errorf("VerifyConsistency() of %d and %d error = %v", first, second, err)
+			}
+			parsed, err := ParseConsistencyProof(proof.Bytes())
+			if err != nil || parsed.First != first || parsed.Second != second ||
+				!bytes.Equal(parsed.Bytes(), proof.Bytes()) {
+				t.Errorf("ParseConsistencyProof() got = %v, %v, want %v", parsed, err, proof)
+			}
+		}
+	}
+
+	root6, _ := tree.RootAt(6)
+	root13, _ := tree.RootAt(13)
+	proof, err := tree.ProveConsistency(6, 13)
+	if err != nil {
+		t.Fatalf("ProveConsistency() error = %v", err)
+	}
+	// a tree of 13 leaves that rewrote leaf 2 of the tree of 6 leaves
+	data := testData(t, 13)
+	data[2] = []byte("rewritten")
+	rewritten := NewTree(data)
+	rewrittenProof, err := rewritten.ProveConsistency(6, 13)
+	if err != nil {
+		t.Fatalf("ProveConsistency() error = %v", err)
+	}
+	tamperedPath := append([][]byte{}, proof.Path...)
+	tamperedPath[0] = LeafHash(nil)
+	tests := []struct {
+		name       string
+		firstRoot  []byte
+		secondRoot []byte
+		proof      *ConsistencyProof
+		wantErr    error
+	}{
+		{"Test_Valid", root6, root13, proof, nil},
+		{"Test_Rewritten_History", root6, rewritten.Root(), rewrittenProof, ErrInvalidProof},
+		{"Test_Swapped_Roots", root13, root6, proof, ErrInvalidProof},
+		{"Test_Other_First_Size", root6, root13, &ConsistencyProof{First: 5, Second: 13, Path: proof.Path}, ErrInvalidProof},
+		{"Test_First_Size_Above_Second", root6, root13, &ConsistencyProof{First: 14, Second: 13}, ErrIndexOutOfRange},
+		{"Test_Tampered_Path", root6, root13, &ConsistencyProof{First: 6, Second: 13, Path: tamperedPath}, ErrInvalidProof},
+		{"Test_Short_Path", root6, root13, &ConsistencyProof{First: 6, Second: 13, Path: proof.Path[1:]}, ErrInvalidProof},
+		{"Test_Empty_Path", root6, root13, &ConsistencyProof{First: 6, Second: 13}, ErrInvalidProof},
+		{"Test_Long_Path", root6, root13, &ConsistencyProof{First: 6, Second: 13, Path: append(proof.Path, root6)},
+			ErrInvalidProof},
+		{"Test_Same_Size_With_Path", root13, root13, &ConsistencyProof{First: 13, Second: 13, Path: proof.Path},
+			ErrInvalidProof},
+		{"Test_Same_Size_Other_Root", root6, root13, &ConsistencyProof{First: 13, Second: 13}, ErrInvalidProof},
+		{"Test_From_Non_Empty_Root_Of_Size_0", root6, root13, &ConsistencyProof{First: 0, Second: 13}, ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyConsistency(tt.firstRoot, tt.secondRoot, tt.proof); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyConsistency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err = tree.ProveConsistency(3, 22); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("ProveConsistency() error = %v, wantErr %v", err, ErrIndexOutOfRange)
+	}
+	if _, err = tree.RootAt(22); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("RootAt() error = %v, wantErr %v", err, ErrIndexOutOfRange)
+	}
+}
@@ -0,0 +1,203 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+)
+
+// HashSize is the size of the hashes of the tree
+const HashSize = sha256.Size
+
+// Domain separation prefixes of RFC 6962, so that a leaf cannot be passed off as a node
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var (
+	// ErrInvalidProof is returned when a proof does not verify against the root
+	ErrInvalidProof = errors.New("merkle: invalid proof")
+	// ErrIndexOutOfRange is returned for a leaf index or tree size beyond the tree
+	ErrIndexOutOfRange = errors.New("merkle: index out of range")
+)
+
+// DataBlock is the data of a leaf, such as a transaction.Hidden
+type DataBlock interface {
+	Serialize() ([]byte, error)
+}
+
+// LeafHash returns the hash of the leaf data, SHA-256(0x00 || data)
+func LeafHash(data []byte) []byte {
+	hashFunc := sha256.New()
+	hashFunc.Write([]byte{leafPrefix})
+	hashFunc.Write(data)
+	return hashFunc.Sum(nil)
+}
+
+// NodeHash returns the hash of an inner node, SHA-256(0x01 || left || right)
+func NodeHash(left, right []byte) []byte {
+	hashFunc := sha256.New()
+	hashFunc.Write([]byte{nodePrefix})
+	hashFunc.Write(left)
+	hashFunc.Write(right)
+	return hashFunc.Sum(nil)
+}
+
+// EmptyRoot returns the root of the empty tree, SHA-256 of the empty string
+func EmptyRoot() []byte {
+	hash := sha256.Sum256(nil)
+	return hash[:]
+}
+
+// Tree is an append-only Merkle tree as in RFC 6962, over the leaf hashes of its data
+type Tree struct {
+	// nodes are the hashes of the complete subtrees by level: nodes[l][i] is the hash of the 2^l leaves from
+	// i * 2^l, and nodes[0] are the leaf hashes. A subtree is hashed once, when its last leaf is appended.
+	nodes [][][]byte
+}
+
+// NewTree creates a new tree of the leaf data
+func NewTree(data [][]byte) *Tree {
+	t := &Tree{nodes: [][][]byte{make([][]byte, 0, len(data))}}
+	for _, d := range data {
+		t.Append(d)
+	}
+	return t
+}
+
+// NewTreeFromBlocks creates a new tree of the serialized data blocks
+func NewTreeFromBlocks[T DataBlock](blocks []T) (*Tree, error) {
+	t := &Tree{nodes: [][][]byte{make([][]byte, 0, len(blocks))}}
+	for _, block := range blocks {
+		data, err := block.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		t.Append(data)
+	}
+	return t, nil
+}
+
+// Append appends the leaf data to the tree, and returns its index
+func (t *Tree) Append(data []byte) uint64 {
+	if t.nodes == nil {
+		t.nodes = make([][][]byte, 1)
+	}
+	t.nodes[0] = append(t.nodes[0], LeafHash(data))
+	// the leaf completes a subtree at every level where it leaves an even number of nodes
+	for level := 0; len(t.nodes[level])%2 == 0; level++ {
+		if level+1 == len(t.nodes) {
+			t.nodes = append(t.nodes, nil)
+		}
+		n := len(t.nodes[level])
+		t.nodes[level+1] = append(t.nodes[level+1], NodeHash(t.nodes[level][n-2], t.nodes[level][n-1]))
+	}
+	return t.Size() - 1
+}
+
+// Size returns the number of leaves of the tree
+func (t *Tree) Size() uint64 {
+	if t.nodes == nil {
+		return 0
+	}
+	return uint64(len(t.nodes[0]))
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() []byte {
+	return t.subtree(0, t.Size())
+}
+
+// InclusionProof is the proof that a leaf is in the tree of a size, the audit path of RFC 6962
+type InclusionProof struct {
+	Index uint64
+	Size  uint64
+	Path  [][]byte
+}
+
+// ProveInclusion returns the inclusion proof of the leaf of the index
+func (t *Tree) ProveInclusion(index uint64) (*InclusionProof, error) {
+	if index >= t.Size() {
+		return nil, ErrIndexOutOfRange
+	}
+	return &InclusionProof{Index: index, Size: t.Size(), Path: t.inclusionPath(index, 0, t.Size())}, nil
+}
+
+// inclusionPath returns the audit path of the leaf of the index in the subtree [begin, end)
+func (t *Tree) inclusionPath(index, begin, end uint64) [][]byte {
+	if end-begin == 1 {
+		return nil
+	}
+	k := splitPoint(end - begin)
+	if index < begin+k {
+		return append(t.inclusionPath(index, begin, begin+k), t.subtree(begin+k, end))
+	}
+	return append(t.inclusionPath(index, begin+k, end), t.subtree(begin, begin+k))
+}
+
+// subtree returns the hash of the subtree of the leaves [begin, end), from the cached hash of a complete
+// subtree when the range is one, so that it hashes O(log n) nodes
+func (t *Tree) subtree(begin, end uint64) []byte {
+	n := end - begin
+	if n == 0 {
+		return EmptyRoot()
+	}
+	if n&(n-1) == 0 && begin%n == 0 {
+		level := bits.TrailingZeros64(n)
+		return t.nodes[level][begin>>level]
+	}
+	k := splitPoint(n)
+	return NodeHash(t.subtree(begin, begin+k), t.subtree(begin+k, end))
+}
+
+// splitPoint returns the largest power of two less than n, for n > 1
+func splitPoint(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+// VerifyInclusion verifies that the leaf data is in the tree of the root, as in RFC 9162
+func VerifyInclusion(root, data []byte, proof *InclusionProof) error {
+	if proof.Index >= proof.Size {
+		return ErrIndexOutOfRange
+	}
+	hash := LeafHash(data)
+	fn, sn := proof.Index, proof.Size-1
+	for _, p := range proof.Path {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			hash = NodeHash(p, hash)
+			if fn&1 == 0 {
+				for fn&1 == 0 && fn != 0 {
+					fn >>= 1
+					sn >>= 1
+				}
+			}
+		} else {
+			hash = NodeHash(hash, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(hash, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// Bytes returns the serialized proof, the index and the size as uint64 followed by the path
+func (p *InclusionProof) Bytes() []byte {
+	return encodeProof(p.Index, p.Size, p.Path)
+}
+
+// ParseInclusionProof parses a serialized inclusion proof
+func ParseInclusionProof(data []byte) (*InclusionProof, error) {
+	index, size, path, err := parseProof(data)
+	if err != nil {
+		return nil, err
+	}
+	return &InclusionProof{Index: index, Size: size, Path: path}, nil
+}
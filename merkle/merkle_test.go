@@ -0,0 +1,170 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// rfc6962Leaves are the leaves of the reference test vectors of RFC 6962 implementations
+var rfc6962Leaves = []string{
+	"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f",
+}
+
+func testTree(t *testing.T, size int) *Tree {
+	t.Helper()
+	return NewTree(testData(t, size))
+}
+
+// testData returns the leaf data of the test trees, the leaves of RFC 6962 followed by their variations
+func testData(t *testing.T, size int) [][]byte {
+	t.Helper()
+	data := make([][]byte, size)
+	for i := range data {
+		leaf, err := hex.DecodeString(rfc6962Leaves[i%len(rfc6962Leaves)])
+		if err != nil {
+			t.Fatalf("hex.DecodeString() error = %v", err)
+		}
+		data[i] = append(leaf, byte(i/len(rfc6962Leaves)))
+		if i < len(rfc6962Leaves) {
+			data[i] = leaf
+		}
+	}
+	return data
+}
+
+// referenceRoot is the root of the leaf hashes, hashed from the leaves as in RFC 6962
+func referenceRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(uint64(len(leaves)))
+	return NodeHash(referenceRoot(leaves[:k]), referenceRoot(leaves[k:]))
+}
+
+func TestTree_Append(t *testing.T) {
+	data := testData(t, 70)
+	tree := new(Tree)
+	var leaves [][]byte
+	for i, d := range data {
+		if got := tree.Append(d); got != uint64(i) {
+			t.Fatalf("Append() = %d, want %d", got, i)
+		}
+		leaves = append(leaves, LeafHash(d))
+		if got, want := tree.Root(), referenceRoot(leaves); !bytes.Equal(got, want) {
+			t.Errorf("Root() of size %d = %x, want %x", i+1, got, want)
+		}
+		for size := 0; size <= i+1; size++ {
+			if got, err := tree.RootAt(uint64(size)); err != nil || !bytes.Equal(got, referenceRoot(leaves[:size])) {
+				t.Errorf("RootAt(%d) of size %d = %x, %v", size, i+1, got, err)
+			}
+		}
+	}
+}
+
+func TestTree_Root(t *testing.T) {
+	tests := []struct {
+		size int
+		want string
+	}{
+		{0, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{1, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"},
+		{2, "fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125"},
+		{3, "aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77"},
+		{4, "d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7"},
+		{5, "4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4"},
+		{6, "76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef"},
+		{7, "ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c"},
+		{8, "5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want[:8], func(t *testing.T) {
+			if got := hex.EncodeToString(testTree(t, tt.size).Root()); got != tt.want {
+				t.Errorf("Root() of size %d = %s, want %s", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	for size := 1; size <= 33; size++ {
+		tree := testTree(t, size)
+		root := tree.Root()
+		for index := 0; index < size; index++ {
+			proof, err := tree.ProveInclusion(uint64(index))
+			if err != nil {
+				t.Fatalf("ProveInclusion() error = %v", err)
+			}
+			leaf, _ := hex.DecodeString(rfc6962Leaves[index%len(rfc6962Leaves)])
+			if index >= len(rfc6962Leaves) {
+				leaf = append(leaf, byte(index/len(rfc6962Leaves)))
+			}
+			if err = VerifyInclusion(root, leaf, proof); err != nil {
+				t.Errorf("VerifyInclusion() of leaf %d of %d error = %v", index, size, err)
+			}
+			parsed, err := ParseInclusionProof(proof.Bytes())
+			if err != nil || parsed.Index != proof.Index || parsed.Size != proof.Size ||
+				!bytes.Equal(parsed.Bytes(), proof.Bytes()) {
+				t.Errorf("ParseInclusionProof() got = %v, %v, want %v", parsed, err, proof)
+			}
+		}
+	}
+
+	tree := testTree(t, 7)
+	root := tree.Root()
+	proof, err := tree.ProveInclusion(5)
+	if err != nil {
+		t.Fatalf("ProveInclusion() error = %v", err)
+	}
+	leaf, _ := hex.DecodeString(rfc6962Leaves[5])
+	tamperedPath := append([][]byte{}, proof.Path...)
+	tamperedPath[1] = LeafHash(nil)
+	tests := []struct {
+		name    string
+		root    []byte
+		data    []byte
+		proof   *InclusionProof
+		wantErr error
+	}{
+		{"Test_Valid", root, leaf, proof, nil},
+		{"Test_Other_Leaf", root, []byte("x"), proof, ErrInvalidProof},
+		{"Test_Other_Index", root, leaf, &InclusionProof{Index: 4, Size: 7, Path: proof.Path}, ErrInvalidProof},
+		{"Test_Other_Size", root, leaf, &InclusionProof{Index: 5, Size: 6, Path: proof.Path}, ErrInvalidProof},
+		{"Test_Index_Out_Of_Range", root, leaf, &InclusionProof{Index: 7, Size: 7, Path: proof.Path}, ErrIndexOutOfRange},
+		{"Test_Short_Path", root, leaf, &InclusionProof{Index: 5, Size: 7, Path: proof.Path[1:]}, ErrInvalidProof},
+		{"Test_Long_Path", root, leaf, &InclusionProof{Index: 5, Size: 7, Path: append(proof.Path, root)},
+			ErrInvalidProof},
+		{"Test_Tampered_Path", root, leaf, &InclusionProof{Index: 5, Size: 7, Path: tamperedPath}, ErrInvalidProof},
+		// a node is not a leaf, thanks to the prefixes
+		{"Test_Node_As_Leaf", root, append(append([]byte{}, proof.Path[1]...), proof.Path[2]...),
+			&InclusionProof{Index: 2, Size: 7, Path: proof.Path[2:]}, ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyInclusion(tt.root, tt.data, tt.proof); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyInclusion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err = tree.ProveInclusion(7); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("ProveInclusion() error = %v, wantErr %v", err, ErrIndexOutOfRange)
+	}
+	if _, err = ParseInclusionProof(make([]byte, 17)); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("ParseInclusionProof() error = %v, wantErr %v", err, ErrInvalidProof)
+	}
+}
+
+func BenchmarkTree_Root(b *testing.B) {
+	tree := NewTree(nil)
+	for i := 0; i < 1<<16; i++ {
+		tree.Append([]byte{byte(i), byte(i >> 8)})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Root()
+	}
+}
@@ -0,0 +1,141 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// MultiProof is the proof that many leaves are in the tree of a size. It holds every sibling hash once,
+// so its size and its verification cost scale with the distinct siblings of the leaves
+type MultiProof struct {
+	// Indices are the leaf indices, strictly increasing
+	Indices []uint64
+	Size    uint64
+	// Path are the roots of the subtrees without any of the leaves, from left to right
+	Path [][]byte
+}
+
+// ProveMulti returns the multiproof of the leaves of the indices, in increasing order
+func (t *Tree) ProveMulti(indices []uint64) (*MultiProof, error) {
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("%w: no leaves", ErrInvalidProof)
+	}
+	sorted := append([]uint64{}, indices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, index := range sorted {
+		if index >= t.Size() {
+			return nil, ErrIndexOutOfRange
+		}
+		if i > 0 && index == sorted[i-1] {
+			return nil, fmt.Errorf("merkle: leaf %d selected twice", index)
+		}
+	}
+	return &MultiProof{Indices: sorted, Size: t.Size(), Path: t.multiPath(sorted, 0, t.Size())}, nil
+}
+
+// multiPath returns the roots of the subtrees of [begin, end) without any of the sorted indices
+func (t *Tree) multiPath(indices []uint64, begin, end uint64) [][]byte {
+	if len(indices) == 0 {
+		return [][]byte{t.subtree(begin, end)}
+	}
+	if end-begin == 1 {
+		return nil
+	}
+	k := splitPoint(end - begin)
+	split := sort.Search(len(indices), func(i int) bool { return indices[i] >= begin+k })
+	return append(t.multiPath(indices[:split], begin, begin+k), t.multiPath(indices[split:], begin+k, end)...)
+}
+
+// VerifyMulti verifies that the leaf data are in the tree of the root, data[i] at the leaf proof.Indices[i]
+func VerifyMulti(root []byte, data [][]byte, proof *MultiProof) error {
+	if len(proof.Indices) == 0 || len(data) != len(proof.Indices) {
+		return fmt.Errorf("%w: got %d leaves and %d indices", ErrInvalidProof, len(data), len(proof.Indices))
+	}
+	for i, index := range proof.Indices {
+		if index >= proof.Size {
+			return ErrIndexOutOfRange
+		}
+		if i > 0 && index <= proof.Indices[i-1] {
+			return fmt.Errorf("%w: indices not increasing", ErrInvalidProof)
+		}
+	}
+	leaves := make([][]byte, len(data))
+	for i, d := range data {
+		leaves[i] = LeafHash(d)
+	}
+	v := &multiVerifier{path: proof.Path}
+	hash := v.subtree(proof.Indices, leaves, 0, proof.Size)
+	if v.err != nil || len(v.path) != 0 || !bytes.Equal(hash, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// multiVerifier recomputes the root of a multiproof, consuming its path from left to right
+type multiVerifier struct {
+	path [][]byte
+	err  error
+}
+
+// subtree returns the hash of the subtree [begin, end) holding the leaves of the sorted indices
+func (v *multiVerifier) subtree(indices []uint64, leaves [][]byte, begin, end uint64) []byte {
+	if v.err != nil {
+		return nil
+	}
+	if len(indices) == 0 {
+		if len(v.path) == 0 {
+			v.err = ErrInvalidProof
+			return nil
+		}
+		hash := v.path[0]
+		v.path = v.path[1:]
+		return hash
+	}
+	if end-begin == 1 {
+		return leaves[0]
+	}
+	k := splitPoint(end - begin)
+	split := sort.Search(len(indices), func(i int) bool { return indices[i] >= begin+k })
+	left := v.subtree(indices[:split], leaves[:split], begin, begin+k)
+	right := v.subtree(indices[split:], leaves[split:], begin+k, end)
+	return NodeHash(left, right)
+}
+
+// Bytes returns the serialized proof, the size and the number of indices as uint64,
+// followed by the indices as uint64 and the path
+func (p *MultiProof) Bytes() []byte {
+	buf := make([]byte, 0, 16+len(p.Indices)*8+len(p.Path)*HashSize)
+	buf = binary.BigEndian.AppendUint64(buf, p.Size)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(p.Indices)))
+	for _, index := range p.Indices {
+		buf = binary.BigEndian.AppendUint64(buf, index)
+	}
+	for _, hash := range p.Path {
+		buf = append(buf, hash...)
+	}
+	return buf
+}
+
+// ParseMultiProof parses a serialized multiproof
+func ParseMultiProof(data []byte) (*MultiProof, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("%w: malformed proof of %d bytes", ErrInvalidProof, len(data))
+	}
+	size, count := binary.BigEndian.Uint64(data), binary.BigEndian.Uint64(data[8:])
+	data = data[16:]
+	if count > uint64(len(data))/8 || (uint64(len(data))-count*8)%HashSize != 0 {
+		return nil, fmt.Errorf("%w: malformed proof of %d indices", ErrInvalidProof, count)
+	}
+	proof := &MultiProof{Indices: make([]uint64, count), Size: size}
+	for i := range proof.Indices {
+		proof.Indices[i] = binary.BigEndian.Uint64(data[i*8:])
+	}
+	data = data[count*8:]
+	proof.Path = make([][]byte, len(data)/HashSize)
+	for i := range proof.Path {
+		proof.Path[i] = append([]byte{}, data[i*HashSize:(i+1)*HashSize]...)
+	}
+	return proof, nil
+}
@@ -0,0 +1,133 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVerifyMulti(t *testing.T) {
+	data := make([][]byte, 37)
+	for i := range data {
+		data[i] = []byte{byte(i)}
+	}
+	tree := NewTree(data)
+	root := tree.Root()
+	selected := func(indices []uint64) [][]byte {
+		leaves := make([][]byte, len(indices))
+		for i, index := range indices {
+			leaves[i] = data[index]
+		}
+		return leaves
+	}
+	prove := func(indices ...uint64) *MultiProof {
+		proof, err := tree.ProveMulti(indices)
+		if err != nil {
+			t.Fatalf("ProveMulti() error = %v", err)
+		}
+		return proof
+	}
+	all := make([]uint64, len(data))
+	for i := range all {
+		all[i] = uint64(i)
+	}
+	valid := prove(3, 4, 17, 36)
+	tamperedPath := *valid
+	tamperedPath.Path = append([][]byte{LeafHash(nil)}, valid.Path[1:]...)
+	shortPath := *valid
+	shortPath.Path = valid.Path[1:]
+	longPath := *valid
+	longPath.Path = append(append([][]byte{}, valid.Path...), LeafHash(nil))
+	tests := []struct {
+		name    string
+		data    [][]byte
+		proof   *MultiProof
+		wantErr error
+	}{
+		{"Test_Valid", selected(valid.Indices), valid, nil},
+		{"Test_Single", selected([]uint64{36}), prove(36), nil},
+		{"Test_All", data, prove(all...), nil},
+		{"Test_Unsorted", selected([]uint64{1, 9, 20}), prove(20, 1, 9), nil},
+		{"Test_Wrong_Data", selected([]uint64{3, 4, 17, 35}), valid, ErrInvalidProof},
+		{"Test_Swapped_Data", selected([]uint64{4, 3, 17, 36}), valid, ErrInvalidProof},
+		{"Test_Missing_Data", selected([]uint64{3, 4, 17}), valid, ErrInvalidProof},
+		{"Test_Tampered_Path", selected(valid.Indices), &tamperedPath, ErrInvalidProof},
+		{"Test_Short_Path", selected(valid.Indices), &shortPath, ErrInvalidProof},
+		{"Test_Long_Path", selected(valid.Indices), &longPath, ErrInvalidProof},
+		{"Test_Out_Of_Range", [][]byte{data[0]}, &MultiProof{Indices: []uint64{37}, Size: 37}, ErrIndexOutOfRange},
+		{"Test_Repeated_Index", selected([]uint64{3, 3}), &MultiProof{Indices: []uint64{3, 3}, Size: 37},
+			ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := ParseMultiProof(tt.proof.Bytes())
+			if err != nil {
+				t.Fatalf("ParseMultiProof() error = %v", err)
+			}
+			if !bytes.Equal(proof.Bytes(), tt.proof.Bytes()) {
+				t.Fatalf("Bytes() = %x, want %x", proof.Bytes(), tt.proof.Bytes())
+			}
+			if err = VerifyMulti(root, tt.data, proof); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyMulti() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the siblings of the first four leaves are the roots of [4, 8), [8, 16), [16, 32) and [32, 37)
+	separate := 0
+	for index := uint64(0); index < 4; index++ {
+		proof, err := tree.ProveInclusion(index)
+		if err != nil {
+			t.Fatalf("ProveInclusion() error = %v", err)
+		}
+		separate += len(proof.Path)
+	}
+	if got := len(prove(0, 1, 2, 3).Path); got != 4 || separate != 24 {
+		t.Errorf("len(Path) of the first four leaves = %d, want 4, separate paths have %d hashes, want 24",
+			got, separate)
+	}
+	if got := len(prove(all...).Path); got != 0 {
+		t.Errorf("len(Path) of all leaves = %d, want 0", got)
+	}
+}
+
+func TestTree_ProveMulti(t *testing.T) {
+	tree := testTree(t, 5)
+	tests := []struct {
+		name    string
+		indices []uint64
+		wantErr bool
+	}{
+		{"Test_Valid", []uint64{0, 4}, false},
+		{"Test_Empty", nil, true},
+		{"Test_Duplicate", []uint64{1, 2, 1}, true},
+		{"Test_Out_Of_Range", []uint64{5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tree.ProveMulti(tt.indices); (err != nil) != tt.wantErr {
+				t.Errorf("ProveMulti() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMultiProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"Test_No_Indices", make([]byte, 16), false},
+		{"Test_Short", make([]byte, 15), true},
+		{"Test_Missing_Indices", append(make([]byte, 15), 2), true},
+		{"Test_Partial_Hash", make([]byte, 16+HashSize-1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMultiProof(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("ParseMultiProof() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// Version1 is the version of the records before versioning, which carry no version field
+	Version1 = 1
+	// Version2 is the version of the records carrying their version field
+	Version2 = 2
+	// Version3 is the version of the commitments encoding their amount as the integer it is modulo the group order,
+	// so that they add up as their amounts do. The commitments of earlier versions encode the magnitude of the
+	// amount big-endian, and negate it for a negative amount, so that only the two commitments of a pair cancel.
+	Version3 = 3
+	// CurrentVersion is the version of new records
+	CurrentVersion = Version3
+)
+
+// ErrUnsupportedVersion is returned when decoding a record of an unknown version
+var ErrUnsupportedVersion = errors.New("protocol: unsupported version")
+
+// Resolve returns the version of the version field of a record, Version1 when the field is absent.
+// A version 1 record never carries the field, so an explicit version 1 is rejected.
+func Resolve(field int) (int, error) {
+	switch field {
+	case 0:
+		return Version1, nil
+	case Version2, Version3:
+		return field, nil
+	}
+	return 0, fmt.Errorf("%w %d", ErrUnsupportedVersion, field)
+}
+
+// Field returns the version field of a record of the version, 0 for version 1
+func Field(version int) int {
+	if version == Version1 {
+		return 0
+	}
+	return version
+}
+
+// HomomorphicAmounts reports whether the commitments of the version add up as their amounts do
+func HomomorphicAmounts(version int) bool {
+	return version >= Version3
+}
+
+// UpgradeVersion returns the version a record of the version is upgraded to. A record carrying commitments
+// is upgraded to the last version of its amount encoding only, since the commitments cannot be re-encoded
+// without their openings; any other record is upgraded to the current version.
+func UpgradeVersion(version int, commitments bool) int {
+	if commitments && !HomomorphicAmounts(version) {
+		return Version2
+	}
+	return CurrentVersion
+}
+
+// PeekVersion returns the version of a JSON encoded record, whose version field is named version in any case
+func PeekVersion(data []byte) (int, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	return Resolve(header.Version)
+}
+
+// Decode decodes a JSON encoded record of any supported version into v, and returns its version. Every version
+// shares the JSON layout of its record, a version 1 record simply lacking the version field.
+func Decode(data []byte, v interface{}) (int, error) {
+	version, err := PeekVersion(data)
+	if err != nil {
+		return 0, err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Upgrade returns the version field a record with the version field is upgraded to, see UpgradeVersion, and
+// whether it changes. A signature covers the version field, so a record whose field changes must be signed again.
+func Upgrade(field int, commitments bool) (int, bool, error) {
+	version, err := Resolve(field)
+	if err != nil {
+		return 0, false, err
+	}
+	upgraded := Field(UpgradeVersion(version, commitments))
+	return upgraded, upgraded != field, nil
+}
+
+// SignatureDomain returns the domain of the signatures of a record with the version field. Version 1 signatures
+// predate the field and keep the domain, later versions append the version to it, so that a signature covers
+// the version of its record. The message of a version 1 record goes on with a length whose first byte is 0,
+// never the '/' of a versioned domain, so the messages of different versions never collide.
+func SignatureDomain(domain string, field int) string {
+	if field == 0 {
+		return domain
+	}
+	return domain + "/v" + strconv.Itoa(field)
+}
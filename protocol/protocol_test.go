@@ -0,0 +1,125 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPeekVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr error
+	}{
+		{"Test_Absent", `{"Sender":"00"}`, Version1, nil},
+		{"Test_Pascal_Case", `{"Version":2,"Sender":"00"}`, Version2, nil},
+		{"Test_Snake_Case", `{"version":2,"data":"00"}`, Version2, nil},
+		{"Test_Version_3", `{"Version":3,"Sender":"00"}`, Version3, nil},
+		{"Test_Explicit_Version_1", `{"version":1}`, 0, ErrUnsupportedVersion},
+		{"Test_Future", `{"Version":4}`, 0, ErrUnsupportedVersion},
+		{"Test_Negative", `{"Version":-1}`, 0, ErrUnsupportedVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PeekVersion([]byte(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PeekVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PeekVersion() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if _, err := PeekVersion([]byte(`{"Version":"2"}`)); err == nil {
+		t.Errorf("PeekVersion() of a string version error = nil")
+	}
+}
+
+func TestSignatureDomain(t *testing.T) {
+	tests := []struct {
+		name  string
+		field int
+		want  string
+	}{
+		{"Test_Version_1", 0, "domain"},
+		{"Test_Version_2", Version2, "domain/v2"},
+		{"Test_Version_3", Version3, "domain/v3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignatureDomain("domain", tt.field); got != tt.want {
+				t.Errorf("SignatureDomain() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradeVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		commitments bool
+		want        int
+	}{
+		{"Test_Version_1", Version1, false, CurrentVersion},
+		{"Test_Version_1_Commitments", Version1, true, Version2},
+		{"Test_Version_2_Commitments", Version2, true, Version2},
+		{"Test_Version_3_Commitments", Version3, true, CurrentVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UpgradeVersion(tt.version, tt.commitments); got != tt.want {
+				t.Errorf("UpgradeVersion() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       int
+		commitments bool
+		want        int
+		wantChanged bool
+		wantErr     error
+	}{
+		{"Test_Version_1", 0, false, CurrentVersion, true, nil},
+		{"Test_Version_1_Commitments", 0, true, Version2, true, nil},
+		{"Test_Version_2_Commitments", Version2, true, Version2, false, nil},
+		{"Test_Current", CurrentVersion, false, CurrentVersion, false, nil},
+		{"Test_Future", CurrentVersion + 1, false, 0, false, ErrUnsupportedVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := Upgrade(tt.field, tt.commitments)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Upgrade() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf("Upgrade() got = %d, %t, want %d, %t", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var record struct {
+		Version int    `json:"version"`
+		Data    string `json:"data"`
+	}
+	version, err := Decode([]byte(`{"data":"00"}`), &record)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if version != Version1 || record.Data != "00" {
+		t.Errorf("Decode() got = %d, %+v, want %d", version, record, Version1)
+	}
+	if _, err = Decode([]byte(`{"version":4,"data":"00"}`), &record); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Decode() of a future version error = %v, wantErr %v", err, ErrUnsupportedVersion)
+	}
+	if _, err = Decode([]byte(`{"data":0}`), &record); err == nil {
+		t.Errorf("Decode() of a mistyped field error = nil")
+	}
+}
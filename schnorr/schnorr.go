@@ -0,0 +1,80 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+// SignatureSize is the size of a signature in bytes, R || s
+const SignatureSize = 64
+
+// ErrInvalidSignature is returned when a signature does not verify
+var ErrInvalidSignature = errors.New("schnorr: invalid signature")
+
+// Sign signs the message with a private key generated by ed25519.KeyGen.
+// The signature satisfies the Ed25519 verification equation s * B = R + Hash(R || A || message) * A,
+// where A is the public key, so it can be checked by any Ed25519 verifier given A.
+func Sign(privateKey *ed25519.Scalar, message []byte) ([]byte, error) {
+	publicKey := new(ed25519.Point).ScalarBaseMult(privateKey)
+	// the nonce is hedged: derived from fresh randomness, the private key and the message
+	randBytes := make([]byte, 32)
+	_, err := rand.Read(randBytes)
+	if err != nil {
+		return nil, err
+	}
+	hashFunc := sha512.New()
+	hashFunc.Write(randBytes)
+	hashFunc.Write(privateKey.Bytes())
+	hashFunc.Write(message)
+	nonce, err := ed25519.NewScalar().SetUniformBytes(hashFunc.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	r := new(ed25519.Point).ScalarBaseMult(nonce)
+	challenge, err := Challenge(r, publicKey, message)
+	if err != nil {
+		return nil, err
+	}
+	s := ed25519.NewScalar().MultiplyAdd(challenge, privateKey, nonce)
+	signature := make([]byte, 0, SignatureSize)
+	signature = append(signature, r.Bytes()...)
+	return append(signature, s.Bytes()...), nil
+}
+
+// Verify verifies the signature of the message against the public key
+func Verify(publicKey *ed25519.Point, message, signature []byte) error {
+	if len(signature) != SignatureSize {
+		return ErrInvalidSignature
+	}
+	r, err := new(ed25519.Point).SetBytes(signature[:32])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	s, err := ed25519.NewScalar().SetCanonicalBytes(signature[32:])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	challenge, err := Challenge(r, publicKey, message)
+	if err != nil {
+		return err
+	}
+	// s * B - challenge * A must be R
+	challenge.Negate(challenge)
+	check := new(ed25519.Point).VarTimeDoubleScalarBaseMult(challenge, publicKey, s)
+	if check.Equal(r) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Challenge computes the challenge scalar Hash(R || A || message)
+func Challenge(r, publicKey *ed25519.Point, message []byte) (*ed25519.Scalar, error) {
+	hashFunc := sha512.New()
+	hashFunc.Write(r.Bytes())
+	hashFunc.Write(publicKey.Bytes())
+	hashFunc.Write(message)
+	return ed25519.NewScalar().SetUniformBytes(hashFunc.Sum(nil))
+}
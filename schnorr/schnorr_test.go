@@ -0,0 +1,95 @@
+package schnorr
+
+import (
+	stded25519 "crypto/ed25519"
+	"errors"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+)
+
+func TestVerify(t *testing.T) {
+	publicKey, privateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	otherPublicKey, _, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	message := []byte("message")
+	signature, err := Sign(privateKey, message)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	tampered := append([]byte{}, signature...)
+	tampered[40] ^= 1
+	type args struct {
+		publicKey *ed25519.Point
+		message   []byte
+		signature []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Test_Valid",
+			args:    args{publicKey: publicKey, message: message, signature: signature},
+			wantErr: false,
+		},
+		{
+			name:    "Test_Wrong_Key",
+			args:    args{publicKey: otherPublicKey, message: message, signature: signature},
+			wantErr: true,
+		},
+		{
+			name:    "Test_Wrong_Message",
+			args:    args{publicKey: publicKey, message: []byte("massage"), signature: signature},
+			wantErr: true,
+		},
+		{
+			name:    "Test_Tampered_Signature",
+			args:    args{publicKey: publicKey, message: message, signature: tampered},
+			wantErr: true,
+		},
+		{
+			name:    "Test_Short_Signature",
+			args:    args{publicKey: publicKey, message: message, signature: signature[:63]},
+			wantErr: true,
+		},
+		{
+			name:    "Test_Empty_Signature",
+			args:    args{publicKey: publicKey, message: message},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.args.publicKey, tt.args.message, tt.args.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestSign_Ed25519Compatible(t *testing.T) {
+	publicKey, privateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	message := []byte("message")
+	signature, err := Sign(privateKey, message)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if !stded25519.Verify(publicKey.Bytes(), message, signature) {
+		t.Errorf("Sign() signature is rejected by crypto/ed25519")
+	}
+}
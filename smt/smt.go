@@ -0,0 +1,299 @@
+package smt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+const (
+	// KeySize is the size of the keys of the tree
+	KeySize = sha256.Size
+	// HashSize is the size of the hashes of the tree
+	HashSize = sha256.Size
+	// Depth is the depth of the tree, a leaf for every key
+	Depth = KeySize * 8
+)
+
+// Domain separation prefixes, so that a leaf cannot be passed off as a node
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var (
+	// ErrInvalidProof is returned when a proof does not verify against the root
+	ErrInvalidProof = errors.New("smt: invalid proof")
+	// ErrEmptyValue is returned when setting an empty value, which cannot be told apart from an absent key
+	ErrEmptyValue = errors.New("smt: empty value")
+)
+
+// Key is the key of a leaf, the hash of the pseudonym pair of a chain
+type Key [KeySize]byte
+
+// ChainKey returns the key of the chain between the sender and the receiver pseudonym hashes,
+// SHA-256(sender || receiver)
+func ChainKey(sender, receiver []byte) Key {
+	hashFunc := sha256.New()
+	hashFunc.Write(sender)
+	hashFunc.Write(receiver)
+	var key Key
+	copy(key[:], hashFunc.Sum(nil))
+	return key
+}
+
+// TransactionKey returns the key of the chain of the hidden transaction
+func TransactionKey(tx *transaction.Hidden) Key {
+	return ChainKey(tx.Sender, tx.Receiver)
+}
+
+// bit returns the bit of the key at the depth, the most significant bit first
+func (k *Key) bit(depth int) byte {
+	return k[depth/8] >> (7 - depth%8) & 1
+}
+
+// defaultHashes are the roots of empty subtrees, by the depth of their root;
+// the empty leaf is all zeros
+var defaultHashes = func() [Depth + 1][]byte {
+	var hashes [Depth + 1][]byte
+	hashes[Depth] = make([]byte, HashSize)
+	for d := Depth - 1; d >= 0; d-- {
+		hashes[d] = nodeHash(hashes[d+1], hashes[d+1])
+	}
+	return hashes
+}()
+
+// EmptyRoot returns the root of the empty tree
+func EmptyRoot() []byte {
+	return append([]byte{}, defaultHashes[0]...)
+}
+
+func leafHash(key *Key, value []byte) []byte {
+	hashFunc := sha256.New()
+	hashFunc.Write([]byte{leafPrefix})
+	hashFunc.Write(key[:])
+	hashFunc.Write(value)
+	return hashFunc.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	hashFunc := sha256.New()
+	hashFunc.Write([]byte{nodePrefix})
+	hashFunc.Write(left)
+	hashFunc.Write(right)
+	return hashFunc.Sum(nil)
+}
+
+// Tree is a sparse Merkle tree of depth 256, holding the running aggregate commitment of every chain
+type Tree struct {
+	values map[Key][]byte
+}
+
+// NewTree creates a new empty tree
+func NewTree() *Tree {
+	return &Tree{values: make(map[Key][]byte)}
+}
+
+// Get returns the value of the key, and whether it is in the tree
+func (t *Tree) Get(key Key) ([]byte, bool) {
+	value, ok := t.values[key]
+	return value, ok
+}
+
+// Set sets the value of the key
+func (t *Tree) Set(key Key, value []byte) error {
+	if len(value) == 0 {
+		return ErrEmptyValue
+	}
+	t.values[key] = append([]byte{}, value...)
+	return nil
+}
+
+// Delete removes the key from the tree
+func (t *Tree) Delete(key Key) {
+	delete(t.values, key)
+}
+
+// Size returns the number of keys in the tree
+func (t *Tree) Size() int {
+	return len(t.values)
+}
+
+// Apply adds the commitment of the hidden transaction to the aggregate commitment of its chain
+func (t *Tree) Apply(tx *transaction.Hidden) error {
+	point, err := new(ed25519.Point).SetBytes(tx.Commitment)
+	if err != nil {
+		return err
+	}
+	key := TransactionKey(tx)
+	if value, ok := t.values[key]; ok {
+		sum, err := new(ed25519.Point).SetBytes(value)
+		if err != nil {
+			return err
+		}
+		point.Add(point, sum)
+	}
+	t.values[key] = point.Bytes()
+	return nil
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() []byte {
+	return t.hash(t.sortedKeys(), 0)
+}
+
+// Prove returns the proof of the key, an inclusion proof if the key is in the tree,
+// and a non-inclusion proof otherwise
+func (t *Tree) Prove(key Key) *Proof {
+	proof := new(Proof)
+	keys := t.sortedKeys()
+	for d := 0; d < Depth; d++ {
+		// keys are sorted, so the subtree of each side is a contiguous range
+		split := sort.Search(len(keys), func(i int) bool { return keys[i].bit(d) == 1 })
+		left, right := keys[:split], keys[split:]
+		if key.bit(d) == 0 {
+			keys = left
+			proof.add(d, t.hash(right, d+1))
+		} else {
+			keys = right
+			proof.add(d, t.hash(left, d+1))
+		}
+	}
+	return proof
+}
+
+// hash returns the root of the subtree at the depth holding the sorted keys
+func (t *Tree) hash(keys []Key, depth int) []byte {
+	switch {
+	case len(keys) == 0:
+		return defaultHashes[depth]
+	case depth == Depth:
+		return leafHash(&keys[0], t.values[keys[0]])
+	}
+	split := sort.Search(len(keys), func(i int) bool { return keys[i].bit(depth) == 1 })
+	return nodeHash(t.hash(keys[:split], depth+1), t.hash(keys[split:], depth+1))
+}
+
+func (t *Tree) sortedKeys() []Key {
+	keys := make([]Key, 0, len(t.values))
+	for key := range t.values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	return keys
+}
+
+// Proof is the path of siblings from the root to a leaf, without the siblings of empty subtrees
+type Proof struct {
+	// Bitmap has the bit of a depth set when the sibling at the depth is not empty
+	Bitmap   [Depth / 8]byte
+	Siblings [][]byte
+}
+
+func (p *Proof) add(depth int, sibling []byte) {
+	if bytes.Equal(sibling, defaultHashes[depth+1]) {
+		return
+	}
+	p.Bitmap[depth/8] |= 1 << (7 - depth%8)
+	p.Siblings = append(p.Siblings, sibling)
+}
+
+// wellFormed returns whether the proof has a sibling hash for every bit set in the bitmap
+func (p *Proof) wellFormed() bool {
+	count := 0
+	for _, b := range p.Bitmap {
+		count += bits.OnesCount8(b)
+	}
+	if count != len(p.Siblings) {
+		return false
+	}
+	for _, sibling := range p.Siblings {
+		if len(sibling) != HashSize {
+			return false
+		}
+	}
+	return true
+}
+
+// root recomputes the root of the proof from the leaf hash
+func (p *Proof) root(key *Key, leaf []byte) ([]byte, error) {
+	if !p.wellFormed() {
+		return nil, ErrInvalidProof
+	}
+	hash := leaf
+	next := len(p.Siblings) - 1
+	for d := Depth - 1; d >= 0; d-- {
+		sibling := defaultHashes[d+1]
+		if p.Bitmap[d/8]>>(7-d%8)&1 == 1 {
+			sibling = p.Siblings[next]
+			next--
+		}
+		if key.bit(d) == 0 {
+			hash = nodeHash(hash, sibling)
+		} else {
+			hash = nodeHash(sibling, hash)
+		}
+	}
+	return hash, nil
+}
+
+// VerifyInclusion verifies that the key has the value in the tree of the root
+func VerifyInclusion(root []byte, key Key, value []byte, proof *Proof) error {
+	if len(value) == 0 {
+		return ErrEmptyValue
+	}
+	got, err := proof.root(&key, leafHash(&key, value))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// VerifyNonInclusion verifies that the key is not in the tree of the root
+func VerifyNonInclusion(root []byte, key Key, proof *Proof) error {
+	got, err := proof.root(&key, defaultHashes[Depth])
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// Bytes returns the serialized proof, the bitmap followed by the siblings
+func (p *Proof) Bytes() []byte {
+	buf := make([]byte, 0, len(p.Bitmap)+len(p.Siblings)*HashSize)
+	buf = append(buf, p.Bitmap[:]...)
+	for _, sibling := range p.Siblings {
+		buf = append(buf, sibling...)
+	}
+	return buf
+}
+
+// ParseProof parses a serialized proof
+func ParseProof(data []byte) (*Proof, error) {
+	proof := new(Proof)
+	if len(data) < len(proof.Bitmap) || (len(data)-len(proof.Bitmap))%HashSize != 0 {
+		return nil, fmt.Errorf("%w: malformed proof of %d bytes", ErrInvalidProof, len(data))
+	}
+	copy(proof.Bitmap[:], data)
+	data = data[len(proof.Bitmap):]
+	proof.Siblings = make([][]byte, len(data)/HashSize)
+	for i := range proof.Siblings {
+		proof.Siblings[i] = append([]byte{}, data[i*HashSize:(i+1)*HashSize]...)
+	}
+	if !proof.wellFormed() {
+		return nil, fmt.Errorf("%w: %d siblings do not match the bitmap", ErrInvalidProof, len(proof.Siblings))
+	}
+	return proof, nil
+}
@@ -0,0 +1,130 @@
+package smt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+func TestTree_Prove(t *testing.T) {
+	tree := NewTree()
+	if !bytes.Equal(tree.Root(), EmptyRoot()) {
+		t.Fatalf("Root() of the empty tree = %x, want %x", tree.Root(), EmptyRoot())
+	}
+	keyA := ChainKey([]byte("sender"), []byte("receiver"))
+	keyB := ChainKey([]byte("receiver"), []byte("sender"))
+	// a neighbour of keyA, sharing all but the last bit
+	neighbour := keyA
+	neighbour[KeySize-1] ^= 1
+	absent := ChainKey([]byte("sender"), []byte("other"))
+	for _, key := range []Key{keyA, keyB, neighbour} {
+		if err := tree.Set(key, key[:]); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	root := tree.Root()
+	tests := []struct {
+		name     string
+		key      Key
+		value    []byte
+		included bool
+		wantErr  error
+	}{
+		{"Test_Included", keyA, keyA[:], true, nil},
+		{"Test_Included_Other", keyB, keyB[:], true, nil},
+		{"Test_Included_Neighbour", neighbour, neighbour[:], true, nil},
+		{"Test_Wrong_Value", keyA, keyB[:], true, ErrInvalidProof},
+		{"Test_Absent", absent, nil, false, nil},
+		{"Test_Absent_Claimed_Included", absent, absent[:], true, ErrInvalidProof},
+		{"Test_Included_Claimed_Absent", keyA, nil, false, ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := ParseProof(tree.Prove(tt.key).Bytes())
+			if err != nil {
+				t.Fatalf("ParseProof() error = %v", err)
+			}
+			if tt.included {
+				err = VerifyInclusion(root, tt.key, tt.value, proof)
+			} else {
+				err = VerifyNonInclusion(root, tt.key, proof)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	tree.Delete(neighbour)
+	if err := VerifyNonInclusion(tree.Root(), neighbour, tree.Prove(neighbour)); err != nil {
+		t.Errorf("VerifyNonInclusion() after Delete() error = %v", err)
+	}
+	tree.Delete(keyA)
+	tree.Delete(keyB)
+	if !bytes.Equal(tree.Root(), EmptyRoot()) {
+		t.Errorf("Root() after deleting all keys = %x, want %x", tree.Root(), EmptyRoot())
+	}
+}
+
+func TestTree_Apply(t *testing.T) {
+	// testvectors imports crosschain, which imports smt, so the generators are derived here
+	g := ed25519.NewGeneratorPoint()
+	h := new(ed25519.Point).Add(g, g)
+	tree := NewTree()
+	sum := ed25519.NewIdentityPoint()
+	var key Key
+	for i, amount := range []int64{100, -40, 7} {
+		tx, err := transaction.NewPlain("org1", "org2", amount).Hide(uint64(i), g, h, false)
+		if err != nil {
+			t.Fatalf("Hide() error = %v", err)
+		}
+		if err = tree.Apply(tx); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		point, err := new(ed25519.Point).SetBytes(tx.Commitment)
+		if err != nil {
+			t.Fatalf("SetBytes() error = %v", err)
+		}
+		sum.Add(sum, point)
+		key = TransactionKey(tx)
+	}
+	if tree.Size() != 1 {
+		t.Fatalf("Size() = %d, want 1", tree.Size())
+	}
+	got, ok := tree.Get(key)
+	if !ok || !bytes.Equal(got, sum.Bytes()) {
+		t.Fatalf("Get() = %x, %v, want %x, true", got, ok, sum.Bytes())
+	}
+	if err := VerifyInclusion(tree.Root(), key, sum.Bytes(), tree.Prove(key)); err != nil {
+		t.Errorf("VerifyInclusion() error = %v", err)
+	}
+}
+
+func TestParseProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"Test_Empty_Proof", make([]byte, 32), false},
+		{"Test_One_Sibling", append(append([]byte{0x80}, make([]byte, 31)...), make([]byte, 32)...), false},
+		{"Test_Short", make([]byte, 31), true},
+		{"Test_Partial_Sibling", make([]byte, 48), true},
+		{"Test_Missing_Sibling", append([]byte{0x80}, make([]byte, 31)...), true},
+		{"Test_Extra_Sibling", make([]byte, 64), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProof(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProof() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(got.Bytes(), tt.data) {
+				t.Errorf("Bytes() = %x, want %x", got.Bytes(), tt.data)
+			}
+		})
+	}
+}
@@ -2,12 +2,42 @@ package sumcheck
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 
 	"filippo.io/edwards25519"
 	"github.com/auti-project/auti-core/transaction"
 )
 
+// ErrSmallOrder is returned when the commitments of a chain differ by a point with a small-order component,
+// which honest commitments never do
+var ErrSmallOrder = errors.New("sumcheck: commitments differ by a small-order component")
+
+// orderMinusOne is the scalar l - 1 for the group order l
+var orderMinusOne = edwards25519.NewScalar().Subtract(edwards25519.NewScalar(), one())
+
+func one() *edwards25519.Scalar {
+	b := make([]byte, 32)
+	b[0] = 1
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// checkPrimeOrder checks that the difference of the commitments of a chain is in the prime-order subgroup,
+// l * d = (l - 1) * d + d is the identity, before it is multiplied by a random scalar: a small-order component
+// would pass or fail the sum-check depending on the scalar
+func checkPrimeOrder(d *edwards25519.Point) error {
+	check := new(edwards25519.Point).VarTimeMultiScalarMult([]*edwards25519.Scalar{orderMinusOne},
+		[]*edwards25519.Point{d})
+	if check.Add(check, d).Equal(edwards25519.NewIdentityPoint()) != 1 {
+		return ErrSmallOrder
+	}
+	return nil
+}
+
 func CheckOrgEpoch(lastCommits, currCommits [][]byte, txLists [][]*transaction.Hidden) (
 	[]*edwards25519.Point, bool, error,
 ) {
@@ -58,6 +88,9 @@ func computeTXCommitCheck(lastCommit, currCommit []byte,
 		return nil, err
 	}
 	commit.Subtract(commit, currCommitPoint)
+	if err = checkPrimeOrder(commit); err != nil {
+		return nil, err
+	}
 	randBytes := make([]byte, 64)
 	_, err = rand.Read(randBytes)
 	if err != nil {
@@ -132,6 +165,9 @@ func computeOrgCommitCheck(lastCommits, epochCommits, currCommits [][]byte) (*ed
 			return nil, err
 		}
 		check.Subtract(check, currCommitPoint)
+		if err = checkPrimeOrder(check); err != nil {
+			return nil, err
+		}
 		_, err = rand.Read(randBytes)
 		if err != nil {
 			return nil, err
@@ -2,6 +2,8 @@ package sumcheck
 
 import (
 	"crypto/rand"
+	"errors"
+	"math/big"
 	rand2 "math/rand"
 	"reflect"
 	"testing"
@@ -14,7 +16,7 @@ import (
 
 func computeTXCommitCheckSetUp(numTXs int) (*edwards25519.Point, *edwards25519.Point, []*transaction.Hidden) {
 	g, h := paramSetup()
-	lastCommit, err := commitment.Commit(12345, time.Now().UnixNano(), 0, g, h)
+	lastCommit, err := commitment.Commit(12345, time.Now().UnixNano(), 0, g, h, false)
 	if err != nil {
 		panic(err)
 	}
@@ -81,7 +83,7 @@ func dummyTXs(g, h *edwards25519.Point, numTXs int) []*transaction.Hidden {
 			Amount:    amount,
 			Timestamp: time.Now().UnixNano(),
 		}
-		txList[i], err = tx.Hide(uint64(i), g, h)
+		txList[i], err = tx.Hide(uint64(i), g, h, false)
 		if err != nil {
 			panic(err)
 		}
@@ -148,7 +150,7 @@ func checkAllOrgEpochSetup(numOrg, numTXs int) (orgLastCommits, orgEpochCommits,
 				}
 				epochCommitPoint.Add(epochCommitPoint, tmp)
 			}
-			lastCommit, err := commitment.Commit(12345, time.Now().UnixNano(), 0, g, h)
+			lastCommit, err := commitment.Commit(12345, time.Now().UnixNano(), 0, g, h, false)
 			if err != nil {
 				panic(err)
 			}
@@ -253,3 +255,177 @@ func TestCheckOrgEpoch(t *testing.T) {
 		})
 	}
 }
+
+func FuzzCheckOrgEpoch(f *testing.F) {
+	g, h := paramSetup()
+	lastCommit, err := commitment.Commit(12345, 100, 0, g, h, false)
+	if err != nil {
+		panic(err)
+	}
+	f.Add(lastCommit, lastCommit, []byte{})
+	f.Add(lastCommit, edwards25519.NewIdentityPoint().Bytes(), lastCommit)
+	f.Add([]byte{}, lastCommit, lastCommit)
+	f.Add(make([]byte, 32), lastCommit, make([]byte, 31))
+	// y = 2 is not the y-coordinate of any curve point
+	invalid := make([]byte, 32)
+	invalid[0] = 2
+	f.Add(lastCommit, invalid, lastCommit)
+	f.Fuzz(func(t *testing.T, lastCommit, currCommit, txCommit []byte) {
+		txLists := [][]*transaction.Hidden{{transaction.NewHidden(nil, nil, txCommit, nil, 0)}}
+		commits, got, err := CheckOrgEpoch([][]byte{lastCommit}, [][]byte{currCommit}, txLists)
+		if !isPoint(lastCommit) || !isPoint(currCommit) || !isPoint(txCommit) {
+			if err == nil {
+				t.Fatalf("CheckOrgEpoch() accepted a malformed point")
+			}
+			return
+		}
+		// the check fails with an error when last + tx - curr has a small-order component,
+		// and passes exactly when it is the identity otherwise
+		want := new(edwards25519.Point)
+		want.Add(mustPoint(lastCommit), mustPoint(txCommit))
+		want.Subtract(want, mustPoint(currCommit))
+		if wantErr := !isPrimeOrder(want); (err != nil) != wantErr {
+			t.Fatalf("CheckOrgEpoch() error = %v, wantErr %v", err, wantErr)
+		}
+		if err != nil {
+			if !errors.Is(err, ErrSmallOrder) {
+				t.Errorf("CheckOrgEpoch() error = %v, wantErr %v", err, ErrSmallOrder)
+			}
+			return
+		}
+		if len(commits) != 1 {
+			t.Fatalf("CheckOrgEpoch() got %d commits, want 1", len(commits))
+		}
+		if got != (want.Equal(edwards25519.NewIdentityPoint()) == 1) {
+			t.Errorf("CheckOrgEpoch() got = %v, want %v", got, !got)
+		}
+	})
+}
+
+func FuzzCheckAllOrgEpoch(f *testing.F) {
+	g, h := paramSetup()
+	lastCommit, err := commitment.Commit(12345, 100, 0, g, h, false)
+	if err != nil {
+		panic(err)
+	}
+	f.Add(lastCommit, edwards25519.NewIdentityPoint().Bytes(), lastCommit)
+	f.Add(lastCommit, lastCommit, lastCommit)
+	f.Add([]byte{}, lastCommit, lastCommit)
+	f.Add(lastCommit, make([]byte, 33), lastCommit)
+	f.Fuzz(func(t *testing.T, lastCommit, epochCommit, currCommit []byte) {
+		got, err := CheckAllOrgEpoch(
+			[][][]byte{{lastCommit}}, [][][]byte{{epochCommit}}, [][][]byte{{currCommit}})
+		if !isPoint(lastCommit) || !isPoint(epochCommit) || !isPoint(currCommit) {
+			if err == nil {
+				t.Fatalf("CheckAllOrgEpoch() accepted a malformed point")
+			}
+			return
+		}
+		want := new(edwards25519.Point)
+		want.Add(mustPoint(lastCommit), mustPoint(epochCommit))
+		want.Subtract(want, mustPoint(currCommit))
+		if wantErr := !isPrimeOrder(want); (err != nil) != wantErr {
+			t.Fatalf("CheckAllOrgEpoch() error = %v, wantErr %v", err, wantErr)
+		}
+		if err != nil {
+			if !errors.Is(err, ErrSmallOrder) {
+				t.Errorf("CheckAllOrgEpoch() error = %v, wantErr %v", err, ErrSmallOrder)
+			}
+			return
+		}
+		if got != (want.Equal(edwards25519.NewIdentityPoint()) == 1) {
+			t.Errorf("CheckAllOrgEpoch() got = %v, want %v", got, !got)
+		}
+	})
+}
+
+func TestCheckOrgEpoch_MalformedPoint(t *testing.T) {
+	lastCommits, currCommits, txLists := checkOrgEpochSetup(10)
+	// y = 2 is not the y-coordinate of any curve point
+	invalid := make([]byte, 32)
+	invalid[0] = 2
+	tests := []struct {
+		name  string
+		setup func()
+	}{
+		{
+			name:  "Test_Short_Last_Commit",
+			setup: func() { lastCommits[0] = lastCommits[0][:31] },
+		},
+		{
+			name:  "Test_Invalid_Current_Commit",
+			setup: func() { currCommits[1] = invalid },
+		},
+		{
+			name:  "Test_Empty_TX_Commit",
+			setup: func() { txLists[2][0] = transaction.NewHidden(nil, nil, nil, nil, 0) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastCommits, currCommits, txLists = checkOrgEpochSetup(10)
+			tt.setup()
+			_, _, err := CheckOrgEpoch(lastCommits, currCommits, txLists)
+			if err == nil {
+				t.Errorf("CheckOrgEpoch() accepted a malformed point")
+			}
+		})
+	}
+}
+
+func TestCheckOrgEpoch_SmallOrder(t *testing.T) {
+	lastCommits, currCommits, txLists := checkOrgEpochSetup(10)
+	// y = 0 is a point of order 4
+	torsion := mustPoint(make([]byte, 32))
+	currCommits[1] = new(edwards25519.Point).Add(mustPoint(currCommits[1]), torsion).Bytes()
+	if _, _, err := CheckOrgEpoch(lastCommits, currCommits, txLists); !errors.Is(err, ErrSmallOrder) {
+		t.Errorf("CheckOrgEpoch() error = %v, wantErr %v", err, ErrSmallOrder)
+	}
+	epochCommits := make([][]byte, len(txLists))
+	for i, txList := range txLists {
+		sum := edwards25519.NewIdentityPoint()
+		for _, tx := range txList {
+			sum.Add(sum, mustPoint(tx.Commitment))
+		}
+		epochCommits[i] = sum.Bytes()
+	}
+	_, err := CheckAllOrgEpoch([][][]byte{lastCommits}, [][][]byte{epochCommits}, [][][]byte{currCommits})
+	if !errors.Is(err, ErrSmallOrder) {
+		t.Errorf("CheckAllOrgEpoch() error = %v, wantErr %v", err, ErrSmallOrder)
+	}
+}
+
+func isPoint(b []byte) bool {
+	_, err := new(edwards25519.Point).SetBytes(b)
+	return err == nil
+}
+
+// isPrimeOrder reports whether the point is in the prime-order subgroup, by multiplying it by the group order
+// as 2^252 + (l - 2^252), since no scalar holds l itself
+func isPrimeOrder(p *edwards25519.Point) bool {
+	q := new(edwards25519.Point).Set(p)
+	for i := 0; i < 252; i++ {
+		q.Add(q, q)
+	}
+	tail := make([]byte, 32)
+	// l - 2^252
+	n, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	n.FillBytes(tail)
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(tail)
+	if err != nil {
+		panic(err)
+	}
+	q.Add(q, new(edwards25519.Point).ScalarMult(s, p))
+	return q.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+func mustPoint(b []byte) *edwards25519.Point {
+	point, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return point
+}
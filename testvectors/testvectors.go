@@ -0,0 +1,260 @@
+package testvectors
+
+import (
+	"encoding/hex"
+	"math"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/auditing"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/crosschain"
+	"github.com/auti-project/auti-core/digest"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// Version is the version of the test vector format
+const Version = 1
+
+// Vectors is the struct for the cross-implementation test vectors
+type Vectors struct {
+	Version      int                 `json:"version"`
+	G            string              `json:"g"`
+	H            string              `json:"h"`
+	Commitments  []CommitmentVector  `json:"commitments"`
+	Transactions []TransactionVector `json:"transactions"`
+	Digests      []DigestVector      `json:"digests"`
+	Auditing     []AuditingVector    `json:"auditing_records"`
+	CrossChain   []CrossChainVector  `json:"crosschain_records"`
+}
+
+// CommitmentVector is the test vector for commitment.Commit
+type CommitmentVector struct {
+	Amount     int64  `json:"amount"`
+	Timestamp  int64  `json:"timestamp"`
+	Counter    uint64 `json:"counter"`
+	NegateHash bool   `json:"negate_hash"`
+	Commitment string `json:"commitment"`
+}
+
+// TransactionVector is the test vector for transaction.Plain.Hide, HidePair and OnChain.KeyVal
+type TransactionVector struct {
+	Sender         string `json:"sender"`
+	Receiver       string `json:"receiver"`
+	Amount         int64  `json:"amount"`
+	Auxiliary      string `json:"auxiliary"`
+	Timestamp      int64  `json:"timestamp"`
+	Counter        uint64 `json:"counter"`
+	SenderHash     string `json:"sender_hash"`
+	ReceiverHash   string `json:"receiver_hash"`
+	Commitment     string `json:"commitment"`
+	PairCommitment string `json:"pair_commitment"`
+	OnChainJSON    string `json:"on_chain_json"`
+	Key            string `json:"key"`
+}
+
+// DigestVector is the test vector for digest.Digest.KeyVal
+type DigestVector struct {
+	Data  string `json:"data"`
+	OrgID string `json:"org_id"`
+	JSON  string `json:"json"`
+	Key   string `json:"key"`
+}
+
+// AuditingVector is the test vector for auditing.Record.KeyVal
+type AuditingVector struct {
+	Payload string `json:"payload"`
+	Type    int    `json:"type"`
+	OrgID   string `json:"org_id"`
+	JSON    string `json:"json"`
+	Key     string `json:"key"`
+}
+
+// CrossChainVector is the test vector for crosschain.Record.KeyVal
+type CrossChainVector struct {
+	Commitment string `json:"commitment"`
+	Proof      string `json:"proof"`
+	Root       string `json:"root"`
+	JSON       string `json:"json"`
+	Key        string `json:"key"`
+}
+
+// Generators derives the fixed generators g and h used by the test vectors from labels with
+// ed25519.HashToPoint, so that no one knows the discrete log of h with respect to g
+func Generators() (g, h *ed25519.Point, err error) {
+	if g, err = autied25519.HashToPoint([]byte("auti-core test vectors g")); err != nil {
+		return
+	}
+	h, err = autied25519.HashToPoint([]byte("auti-core test vectors h"))
+	return
+}
+
+// Generate generates the test vectors of the current protocol version from the current implementation
+func Generate() (*Vectors, error) {
+	return GenerateWith(protocol.CurrentVersion)
+}
+
+// GenerateWith generates the test vectors of records and commitments of the protocol version
+func GenerateWith(version int) (*Vectors, error) {
+	if _, err := protocol.Resolve(protocol.Field(version)); err != nil {
+		return nil, err
+	}
+	g, h, err := Generators()
+	if err != nil {
+		return nil, err
+	}
+	v := &Vectors{
+		Version: Version,
+		G:       hex.EncodeToString(g.Bytes()),
+		H:       hex.EncodeToString(h.Bytes()),
+	}
+	params := &commitment.Params{G: g, H: h, Version: version}
+	if v.Commitments, err = commitmentVectors(params); err != nil {
+		return nil, err
+	}
+	if v.Transactions, err = transactionVectors(params); err != nil {
+		return nil, err
+	}
+	field := protocol.Field(version)
+	if v.Digests, err = digestVectors(field); err != nil {
+		return nil, err
+	}
+	if v.Auditing, err = auditingVectors(field); err != nil {
+		return nil, err
+	}
+	if v.CrossChain, err = crossChainVectors(field, v.Commitments); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func commitmentVectors(params *commitment.Params) ([]CommitmentVector, error) {
+	inputs := []CommitmentVector{
+		{Amount: 0, Timestamp: 0, Counter: 0},
+		{Amount: 100, Timestamp: 100, Counter: 100},
+		{Amount: -100, Timestamp: 100, Counter: 100, NegateHash: true},
+		{Amount: 256, Timestamp: 1672531200000000000, Counter: 1},
+		{Amount: math.MaxInt64, Timestamp: 1672531200000000000, Counter: math.MaxUint64},
+		{Amount: math.MinInt64, Timestamp: -1, Counter: 0, NegateHash: true},
+	}
+	for i := range inputs {
+		c, err := commitment.CommitWith(params, inputs[i].Amount, inputs[i].Timestamp, inputs[i].Counter,
+			inputs[i].NegateHash)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i].Commitment = hex.EncodeToString(c)
+	}
+	return inputs, nil
+}
+
+func transactionVectors(params *commitment.Params) ([]TransactionVector, error) {
+	inputs := []TransactionVector{
+		{Sender: "org1", Receiver: "org2", Amount: 100, Timestamp: 1672531200000000000, Counter: 0},
+		{Sender: "org2", Receiver: "org1", Amount: -2500, Auxiliary: "6d656d6f",
+			Timestamp: 1672531200000000001, Counter: 1},
+		{Sender: "", Receiver: "", Amount: 0, Timestamp: 0, Counter: 0},
+	}
+	for i := range inputs {
+		aux, err := hex.DecodeString(inputs[i].Auxiliary)
+		if err != nil {
+			return nil, err
+		}
+		plain := &transaction.Plain{
+			Sender:    inputs[i].Sender,
+			Receiver:  inputs[i].Receiver,
+			Amount:    inputs[i].Amount,
+			Auxiliary: aux,
+			Timestamp: inputs[i].Timestamp,
+		}
+		h1, h2, err := plain.HidePairWith(inputs[i].Counter, params)
+		if err != nil {
+			return nil, err
+		}
+		key, val, err := h1.ToOnChain().KeyVal()
+		if err != nil {
+			return nil, err
+		}
+		inputs[i].SenderHash = hex.EncodeToString(h1.Sender)
+		inputs[i].ReceiverHash = hex.EncodeToString(h1.Receiver)
+		inputs[i].Commitment = hex.EncodeToString(h1.Commitment)
+		inputs[i].PairCommitment = hex.EncodeToString(h2.Commitment)
+		inputs[i].OnChainJSON = string(val)
+		inputs[i].Key = key
+	}
+	return inputs, nil
+}
+
+func digestVectors(field int) ([]DigestVector, error) {
+	inputs := []DigestVector{
+		{Data: "", OrgID: ""},
+		{Data: "00010203", OrgID: "org1"},
+	}
+	for i := range inputs {
+		data, err := hex.DecodeString(inputs[i].Data)
+		if err != nil {
+			return nil, err
+		}
+		d := digest.NewDigest(data, inputs[i].OrgID)
+		d.Version = field
+		key, val, err := d.KeyVal()
+		if err != nil {
+			return nil, err
+		}
+		inputs[i].JSON = string(val)
+		inputs[i].Key = key
+	}
+	return inputs, nil
+}
+
+func auditingVectors(field int) ([]AuditingVector, error) {
+	inputs := []AuditingVector{
+		{Payload: "", Type: 0, OrgID: ""},
+		{Payload: "deadbeef", Type: 1, OrgID: "org1"},
+	}
+	for i := range inputs {
+		payload, err := hex.DecodeString(inputs[i].Payload)
+		if err != nil {
+			return nil, err
+		}
+		r := auditing.NewRecord(payload, inputs[i].Type, inputs[i].OrgID)
+		r.Version = field
+		key, val, err := r.KeyVal()
+		if err != nil {
+			return nil, err
+		}
+		inputs[i].JSON = string(val)
+		inputs[i].Key = key
+	}
+	return inputs, nil
+}
+
+func crossChainVectors(field int, commits []CommitmentVector) ([]CrossChainVector, error) {
+	inputs := []CrossChainVector{
+		{Commitment: commits[1].Commitment, Proof: "", Root: commits[1].Commitment},
+		{Commitment: commits[3].Commitment, Proof: commits[4].Commitment, Root: commits[5].Commitment},
+	}
+	for i := range inputs {
+		var fields [3][]byte
+		for j, field := range []string{inputs[i].Commitment, inputs[i].Proof, inputs[i].Root} {
+			b, err := hex.DecodeString(field)
+			if err != nil {
+				return nil, err
+			}
+			fields[j] = b
+		}
+		record, err := crosschain.NewRecord(fields[0], fields[1], fields[2])
+		if err != nil {
+			return nil, err
+		}
+		record.Version = field
+		key, val, err := record.KeyVal()
+		if err != nil {
+			return nil, err
+		}
+		inputs[i].JSON = string(val)
+		inputs[i].Key = key
+	}
+	return inputs, nil
+}
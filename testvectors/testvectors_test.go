@@ -0,0 +1,331 @@
+package testvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/auti-project/auti-core/auditing"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/crosschain"
+	"github.com/auti-project/auti-core/digest"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+const vectorsFile = "vectors.json"
+
+// versionFiles are the files of the test vectors of every protocol version, the current one in vectorsFile
+var versionFiles = map[int]string{
+	protocol.Version1: "testdata/vectors_v1.json",
+	protocol.Version2: "testdata/vectors_v2.json",
+	protocol.Version3: vectorsFile,
+}
+
+var update = flag.Bool("update", false, "regenerate "+vectorsFile+" and the vectors of earlier versions")
+
+func TestGenerate(t *testing.T) {
+	for version, file := range versionFiles {
+		v, err := GenerateWith(version)
+		if err != nil {
+			t.Fatalf("GenerateWith(%d) error = %v", version, err)
+		}
+		got, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			t.Fatalf("cannot marshal vectors = %v", err)
+		}
+		got = append(got, '\n')
+		if *update {
+			if err = os.WriteFile(file, got, 0o644); err != nil {
+				t.Fatalf("cannot write %s = %v", file, err)
+			}
+		}
+		want, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("cannot read %s = %v", file, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("GenerateWith(%d) drifted from %s, run go test -update if the change is deliberate", version, file)
+		}
+	}
+	if _, ok := versionFiles[protocol.CurrentVersion]; !ok || versionFiles[protocol.CurrentVersion] != vectorsFile {
+		t.Errorf("the vectors of the current version %d are not in %s", protocol.CurrentVersion, vectorsFile)
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	v1, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	v2, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	b1, _ := json.Marshal(v1)
+	b2, _ := json.Marshal(v2)
+	if !bytes.Equal(b1, b2) {
+		t.Errorf("Generate() is not deterministic")
+	}
+}
+
+func TestDefaultSuite(t *testing.T) {
+	v := readVectors(t, vectorsFile)
+	g, h, err := Generators()
+	if err != nil {
+		t.Fatalf("Generators() error = %v", err)
+	}
+	params := commitment.NewParams(g, h)
+	tagged := &commitment.Params{G: g, H: h, Suite: hashsuite.SHA256Tagged}
+	for _, c := range v.Commitments {
+		got, err := commitment.CommitWith(params, c.Amount, c.Timestamp, c.Counter, c.NegateHash)
+		if err != nil {
+			t.Fatalf("CommitWith() error = %v", err)
+		}
+		if hex.EncodeToString(got) != c.Commitment {
+			t.Errorf("CommitWith() = %x, want %s", got, c.Commitment)
+		}
+		got, err = commitment.CommitWith(tagged, c.Amount, c.Timestamp, c.Counter, c.NegateHash)
+		if err != nil || hex.EncodeToString(got) == c.Commitment {
+			t.Errorf("CommitWith() of %s = the commitment of the default suite", hashsuite.SHA256Tagged.Name())
+		}
+	}
+	for _, tx := range v.Transactions {
+		plain := transaction.NewPlain(tx.Sender, tx.Receiver, tx.Amount)
+		plain.Auxiliary, _ = hex.DecodeString(tx.Auxiliary)
+		plain.Timestamp = tx.Timestamp
+		h1, h2, err := plain.HidePairWith(tx.Counter, params)
+		if err != nil {
+			t.Fatalf("HidePairWith() error = %v", err)
+		}
+		key, _, err := h1.ToOnChain().KeyValWith(hashsuite.SHA256)
+		if err != nil {
+			t.Fatalf("KeyValWith() error = %v", err)
+		}
+		if hex.EncodeToString(h1.Sender) != tx.SenderHash || hex.EncodeToString(h1.Receiver) != tx.ReceiverHash ||
+			hex.EncodeToString(h1.Commitment) != tx.Commitment || hex.EncodeToString(h2.Commitment) != tx.PairCommitment ||
+			key != tx.Key {
+			t.Errorf("HidePairWith() and KeyValWith() of %s to %s drifted from %s", tx.Sender, tx.Receiver, vectorsFile)
+		}
+		if taggedKey, _, _ := h1.ToOnChain().KeyValWith(hashsuite.SHA256Tagged); taggedKey == tx.Key {
+			t.Errorf("KeyValWith() of %s = the key of the default suite", hashsuite.SHA256Tagged.Name())
+		}
+	}
+	for _, d := range v.Digests {
+		data, _ := hex.DecodeString(d.Data)
+		if key, _, err := digest.NewDigest(data, d.OrgID).KeyValWith(hashsuite.SHA256); err != nil || key != d.Key {
+			t.Errorf("digest KeyValWith() = %s, %v, want %s", key, err, d.Key)
+		}
+	}
+	for _, a := range v.Auditing {
+		payload, _ := hex.DecodeString(a.Payload)
+		if key, _, err := auditing.NewRecord(payload, a.Type, a.OrgID).KeyValWith(hashsuite.SHA256); err != nil ||
+			key != a.Key {
+			t.Errorf("auditing KeyValWith() = %s, %v, want %s", key, err, a.Key)
+		}
+	}
+	for _, c := range v.CrossChain {
+		record, err := crosschain.DecodeRecord([]byte(c.JSON))
+		if err != nil {
+			t.Fatalf("DecodeRecord() error = %v", err)
+		}
+		if key, _, err := record.KeyValWith(hashsuite.SHA256); err != nil || key != c.Key {
+			t.Errorf("crosschain KeyValWith() = %s, %v, want %s", key, err, c.Key)
+		}
+	}
+}
+
+// keyValer is an on-chain record of any type
+type keyValer interface {
+	KeyVal() (string, []byte, error)
+}
+
+func TestUpgrade(t *testing.T) {
+	type record struct {
+		name string
+		// commitments is true for the records carrying commitments, which keep their amount encoding
+		commitments bool
+		decode      func([]byte) (keyValer, int, error)
+		upgrade     func(keyValer) (keyValer, error)
+		// vectors returns the JSON and the key of the records of the vectors
+		vectors func(v *Vectors) (jsons, keys []string)
+	}
+	records := []record{
+		{
+			name:        "Test_Transaction",
+			commitments: true,
+			decode: func(data []byte) (keyValer, int, error) {
+				o, err := transaction.DecodeOnChain(data)
+				if err != nil {
+					return nil, 0, err
+				}
+				version, err := o.ProtocolVersion()
+				return o, version, err
+			},
+			upgrade: func(r keyValer) (keyValer, error) { return r.(*transaction.OnChain).Upgrade() },
+			vectors: func(v *Vectors) (jsons, keys []string) {
+				for _, tx := range v.Transactions {
+					jsons, keys = append(jsons, tx.OnChainJSON), append(keys, tx.Key)
+				}
+				return
+			},
+		},
+		{
+			name: "Test_Digest",
+			decode: func(data []byte) (keyValer, int, error) {
+				d, err := digest.DecodeDigest(data)
+				if err != nil {
+					return nil, 0, err
+				}
+				version, err := d.ProtocolVersion()
+				return d, version, err
+			},
+			upgrade: func(r keyValer) (keyValer, error) { return r.(*digest.Digest).Upgrade() },
+			vectors: func(v *Vectors) (jsons, keys []string) {
+				for _, d := range v.Digests {
+					jsons, keys = append(jsons, d.JSON), append(keys, d.Key)
+				}
+				return
+			},
+		},
+		{
+			name: "Test_Auditing",
+			decode: func(data []byte) (keyValer, int, error) {
+				r, err := auditing.DecodeRecord(data)
+				if err != nil {
+					return nil, 0, err
+				}
+				version, err := r.ProtocolVersion()
+				return r, version, err
+			},
+			upgrade: func(r keyValer) (keyValer, error) { return r.(*auditing.Record).Upgrade() },
+			vectors: func(v *Vectors) (jsons, keys []string) {
+				for _, a := range v.Auditing {
+					jsons, keys = append(jsons, a.JSON), append(keys, a.Key)
+				}
+				return
+			},
+		},
+		{
+			name:        "Test_Cross_Chain",
+			commitments: true,
+			decode: func(data []byte) (keyValer, int, error) {
+				r, err := crosschain.DecodeRecord(data)
+				if err != nil {
+					return nil, 0, err
+				}
+				version, err := r.ProtocolVersion()
+				return r, version, err
+			},
+			upgrade: func(r keyValer) (keyValer, error) { return r.(*crosschain.Record).Upgrade() },
+			vectors: func(v *Vectors) (jsons, keys []string) {
+				for _, c := range v.CrossChain {
+					jsons, keys = append(jsons, c.JSON), append(keys, c.Key)
+				}
+				return
+			},
+		},
+	}
+	for _, version := range []int{protocol.Version1, protocol.Version2, protocol.Version3} {
+		for _, r := range records {
+			target := protocol.UpgradeVersion(version, r.commitments)
+			oldJSONs, oldKeys := r.vectors(readVectors(t, versionFiles[version]))
+			newJSONs, newKeys := r.vectors(readVectors(t, versionFiles[target]))
+			for i := range oldJSONs {
+				t.Run(fmt.Sprintf("%s_Version_%d", r.name, version), func(t *testing.T) {
+					old, got, err := r.decode([]byte(oldJSONs[i]))
+					if err != nil || got != version {
+						t.Fatalf("decode() of version %d version = %d, error = %v", version, got, err)
+					}
+					// the old record still has its key
+					key, val, err := old.KeyVal()
+					if err != nil || key != oldKeys[i] || string(val) != oldJSONs[i] {
+						t.Errorf("KeyVal() of version %d = %s, %s, %v, want %s, %s",
+							version, key, val, err, oldKeys[i], oldJSONs[i])
+					}
+					upgraded, err := r.upgrade(old)
+					if err != nil {
+						t.Fatalf("Upgrade() error = %v", err)
+					}
+					key, val, err = upgraded.KeyVal()
+					if err != nil || key != newKeys[i] || string(val) != newJSONs[i] {
+						t.Errorf("KeyVal() of upgraded = %s, %s, %v, want %s, %s", key, val, err, newKeys[i], newJSONs[i])
+					}
+					if _, got, err = r.decode(val); err != nil || got != target {
+						t.Errorf("decode() of upgraded version = %d, error = %v, want %d", got, err, target)
+					}
+				})
+			}
+		}
+	}
+}
+
+func readVectors(t *testing.T, file string) *Vectors {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("cannot read %s = %v", file, err)
+	}
+	v := new(Vectors)
+	if err = json.Unmarshal(data, v); err != nil {
+		t.Fatalf("cannot unmarshal %s = %v", file, err)
+	}
+	return v
+}
+
+// binaryRecord is an on-chain record with a binary encoding
+type binaryRecord interface {
+	keyValer
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
+}
+
+func TestMarshalBinary(t *testing.T) {
+	v := readVectors(t, vectorsFile)
+	type vector struct {
+		name   string
+		record binaryRecord
+		json   string
+		key    string
+	}
+	var vectors []vector
+	for _, tx := range v.Transactions {
+		vectors = append(vectors, vector{"Test_Transaction", new(transaction.OnChain), tx.OnChainJSON, tx.Key})
+	}
+	for _, d := range v.Digests {
+		vectors = append(vectors, vector{"Test_Digest", new(digest.Digest), d.JSON, d.Key})
+	}
+	for _, a := range v.Auditing {
+		vectors = append(vectors, vector{"Test_Auditing", new(auditing.Record), a.JSON, a.Key})
+	}
+	for _, c := range v.CrossChain {
+		vectors = append(vectors, vector{"Test_Cross_Chain", new(crosschain.Record), c.JSON, c.Key})
+	}
+	for _, tt := range vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.json), tt.record); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			data, err := tt.record.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+			if len(data) >= len(tt.json) {
+				t.Errorf("MarshalBinary() of %d bytes, JSON of %d bytes", len(data), len(tt.json))
+			}
+			if err = tt.record.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			// the key is derived from the JSON, whatever the encoding of the record
+			key, val, err := tt.record.KeyVal()
+			if err != nil || key != tt.key || string(val) != tt.json {
+				t.Errorf("KeyVal() = %s, %s, %v, want %s, %s", key, val, err, tt.key, tt.json)
+			}
+		})
+	}
+}
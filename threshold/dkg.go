@@ -0,0 +1,255 @@
+package threshold
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/schnorr"
+	"github.com/auti-project/auti-core/vss"
+)
+
+// proofDomain separates the proofs of knowledge of the DKG from other messages signed with the same secret
+const proofDomain = "auti-core/threshold/dkg"
+
+const (
+	roundCommitments = 1
+	roundShares      = 2
+)
+
+// ErrInvalidProof is returned when a participant does not prove knowledge of its secret
+var ErrInvalidProof = errors.New("threshold: invalid proof of knowledge")
+
+// Round1Message is broadcast by every participant, with the Feldman commitments of its polynomial
+// and a proof of knowledge of its secret against rogue-key attacks
+type Round1Message struct {
+	Commitments []*ed25519.Point
+	Proof       []byte
+}
+
+// Round2Message is sent privately by every participant to every other participant, with the share of the recipient
+type Round2Message struct {
+	Share *vss.Share
+}
+
+// KeyShare is the result of the DKG for a participant
+type KeyShare struct {
+	Index     uint32
+	Threshold int
+	// Secret is the secret share of the participant, its public key is VerificationShares[Index]
+	Secret *ed25519.Scalar
+	// PublicKey is the joint public key of the committee
+	PublicKey *ed25519.Point
+	// VerificationShares are the public keys of the secret shares of all participants
+	VerificationShares map[uint32]*ed25519.Point
+}
+
+// Verify checks that the secret share matches its verification share
+func (k *KeyShare) Verify() error {
+	verificationShare, ok := k.VerificationShares[k.Index]
+	if !ok || new(ed25519.Point).ScalarBaseMult(k.Secret).Equal(verificationShare) != 1 {
+		return vss.ErrInvalidShare
+	}
+	return nil
+}
+
+// Participant runs the Pedersen DKG, with Feldman verifiable secret sharing, for one member of the committee
+type Participant struct {
+	index       uint32
+	threshold   int
+	indices     []uint32
+	polynomial  *vss.Polynomial
+	commitments map[uint32][]*ed25519.Point
+}
+
+// NewParticipant creates a new participant of the index in a committee of the indices, any threshold of which can sign
+func NewParticipant(index uint32, threshold int, indices []uint32) (*Participant, error) {
+	if threshold < 1 || threshold > len(indices) {
+		return nil, fmt.Errorf("threshold: threshold %d out of range [1, %d]", threshold, len(indices))
+	}
+	found := false
+	seen := make(map[uint32]struct{}, len(indices))
+	for _, i := range indices {
+		if i == 0 {
+			return nil, errors.New("threshold: participant index 0 is reserved")
+		}
+		if _, ok := seen[i]; ok {
+			return nil, fmt.Errorf("threshold: duplicate participant index %d", i)
+		}
+		seen[i] = struct{}{}
+		found = found || i == index
+	}
+	if !found {
+		return nil, fmt.Errorf("threshold: participant %d is not in the committee", index)
+	}
+	return &Participant{
+		index:       index,
+		threshold:   threshold,
+		indices:     append([]uint32{}, indices...),
+		commitments: make(map[uint32][]*ed25519.Point, len(indices)),
+	}, nil
+}
+
+// Round1 samples the polynomial of the participant and returns its broadcast message
+func (p *Participant) Round1() (*Round1Message, error) {
+	secret, err := vss.RandomScalar()
+	if err != nil {
+		return nil, err
+	}
+	if p.polynomial, err = vss.NewPolynomial(secret, p.threshold); err != nil {
+		return nil, err
+	}
+	commitments := p.polynomial.Commitments()
+	proof, err := schnorr.Sign(secret, proofMessage(p.index, commitments))
+	if err != nil {
+		return nil, err
+	}
+	p.commitments[p.index] = commitments
+	return &Round1Message{Commitments: commitments, Proof: proof}, nil
+}
+
+// Round2 verifies the broadcast messages of the other participants, keyed by their index,
+// and returns the shares to send to them, keyed by their index
+func (p *Participant) Round2(round1 map[uint32]*Round1Message) (map[uint32]*Round2Message, error) {
+	if p.polynomial == nil {
+		return nil, errors.New("threshold: round 1 is not done")
+	}
+	if len(round1) != len(p.indices)-1 {
+		return nil, fmt.Errorf("threshold: got %d round 1 messages, want %d", len(round1), len(p.indices)-1)
+	}
+	for _, i := range p.indices {
+		if i == p.index {
+			continue
+		}
+		msg, ok := round1[i]
+		if !ok {
+			return nil, fmt.Errorf("threshold: missing round 1 message of participant %d", i)
+		}
+		if len(msg.Commitments) != p.threshold {
+			return nil, fmt.Errorf("threshold: participant %d committed to %d coefficients, want %d",
+				i, len(msg.Commitments), p.threshold)
+		}
+		if err := schnorr.Verify(msg.Commitments[0], proofMessage(i, msg.Commitments), msg.Proof); err != nil {
+			return nil, fmt.Errorf("%w of participant %d", ErrInvalidProof, i)
+		}
+		p.commitments[i] = msg.Commitments
+	}
+	round2 := make(map[uint32]*Round2Message, len(p.indices)-1)
+	for _, i := range p.indices {
+		if i == p.index {
+			continue
+		}
+		share, err := p.polynomial.Share(i)
+		if err != nil {
+			return nil, err
+		}
+		round2[i] = &Round2Message{Share: share}
+	}
+	return round2, nil
+}
+
+// Finalize verifies the shares sent by the other participants, keyed by their index, and computes the key share
+func (p *Participant) Finalize(round2 map[uint32]*Round2Message) (*KeyShare, error) {
+	if len(p.commitments) != len(p.indices) {
+		return nil, errors.New("threshold: round 2 is not done")
+	}
+	secret, err := p.polynomial.Share(p.index)
+	if err != nil {
+		return nil, err
+	}
+	sum := secret.Value
+	for _, i := range p.indices {
+		if i == p.index {
+			continue
+		}
+		msg, ok := round2[i]
+		if !ok {
+			return nil, fmt.Errorf("threshold: missing round 2 message of participant %d", i)
+		}
+		if msg.Share.Index != p.index {
+			return nil, fmt.Errorf("threshold: participant %d sent the share of %d", i, msg.Share.Index)
+		}
+		if err = msg.Share.Verify(p.commitments[i]); err != nil {
+			return nil, fmt.Errorf("%w of participant %d", err, i)
+		}
+		sum.Add(sum, msg.Share.Value)
+	}
+	// the joint polynomial is the sum of all polynomials, so are its commitments
+	joint := make([]*ed25519.Point, p.threshold)
+	for k := range joint {
+		joint[k] = ed25519.NewIdentityPoint()
+		for _, commitments := range p.commitments {
+			joint[k].Add(joint[k], commitments[k])
+		}
+	}
+	keyShare := &KeyShare{
+		Index:              p.index,
+		Threshold:          p.threshold,
+		Secret:             sum,
+		PublicKey:          joint[0],
+		VerificationShares: make(map[uint32]*ed25519.Point, len(p.indices)),
+	}
+	for _, i := range p.indices {
+		keyShare.VerificationShares[i] = vss.EvaluateCommitments(joint, i)
+	}
+	if err = keyShare.Verify(); err != nil {
+		return nil, err
+	}
+	return keyShare, nil
+}
+
+// RunDKG runs the DKG for the participant over the transport, and returns its key share
+func RunDKG(p *Participant, transport Transport) (*KeyShare, error) {
+	msg1, err := p.Round1()
+	if err != nil {
+		return nil, err
+	}
+	if err = transport.Send(&Message{From: p.index, Round: roundCommitments, Payload: msg1}); err != nil {
+		return nil, err
+	}
+	received, err := transport.Receive(p.index, roundCommitments, len(p.indices)-1)
+	if err != nil {
+		return nil, err
+	}
+	round1 := make(map[uint32]*Round1Message, len(received))
+	for _, msg := range received {
+		payload, ok := msg.Payload.(*Round1Message)
+		if !ok {
+			return nil, fmt.Errorf("threshold: unexpected round 1 message of participant %d", msg.From)
+		}
+		round1[msg.From] = payload
+	}
+	round2, err := p.Round2(round1)
+	if err != nil {
+		return nil, err
+	}
+	for to, msg2 := range round2 {
+		if err = transport.Send(&Message{From: p.index, To: to, Round: roundShares, Payload: msg2}); err != nil {
+			return nil, err
+		}
+	}
+	received, err = transport.Receive(p.index, roundShares, len(p.indices)-1)
+	if err != nil {
+		return nil, err
+	}
+	shares := make(map[uint32]*Round2Message, len(received))
+	for _, msg := range received {
+		payload, ok := msg.Payload.(*Round2Message)
+		if !ok {
+			return nil, fmt.Errorf("threshold: unexpected round 2 message of participant %d", msg.From)
+		}
+		shares[msg.From] = payload
+	}
+	return p.Finalize(shares)
+}
+
+// proofMessage binds the proof of knowledge to the participant and its commitments
+func proofMessage(index uint32, commitments []*ed25519.Point) []byte {
+	buf := []byte(proofDomain)
+	buf = binary.BigEndian.AppendUint32(buf, index)
+	for _, commitment := range commitments {
+		buf = append(buf, commitment.Bytes()...)
+	}
+	return buf
+}
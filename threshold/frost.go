@@ -0,0 +1,281 @@
+package threshold
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/schnorr"
+	"github.com/auti-project/auti-core/vss"
+)
+
+// bindingDomain separates the binding factors of FROST from other hashes
+const bindingDomain = "auti-core/threshold/frost/binding"
+
+const (
+	roundNonces          = 3
+	roundSignatureShares = 4
+)
+
+var (
+	// ErrInvalidSignatureShare is returned when a signature share does not verify against its verification share
+	ErrInvalidSignatureShare = errors.New("threshold: invalid signature share")
+	// ErrMissingNonceCommitment is returned when the nonce commitments given to a signer do not include
+	// its own hiding and binding commitments
+	ErrMissingNonceCommitment = errors.New("threshold: the nonce commitments do not include the commitment of the signer")
+)
+
+// NonceCommitment is the commitment of a signer to its hiding and binding nonces for one signature
+type NonceCommitment struct {
+	Index   uint32
+	Hiding  *ed25519.Point
+	Binding *ed25519.Point
+}
+
+// SignatureShare is the share of a signer in a threshold signature
+type SignatureShare struct {
+	Index uint32
+	Value *ed25519.Scalar
+}
+
+// Signer runs the FROST signing protocol for a key share.
+// The signature is a Schnorr signature under the joint public key, verified by schnorr.Verify.
+type Signer struct {
+	share   *KeyShare
+	hiding  *ed25519.Scalar
+	binding *ed25519.Scalar
+}
+
+// NewSigner creates a new signer of the key share
+func NewSigner(share *KeyShare) *Signer {
+	return &Signer{share: share}
+}
+
+// Commit samples the nonces of the next signature and returns their commitment
+func (s *Signer) Commit() (*NonceCommitment, error) {
+	var err error
+	if s.hiding, err = s.nonce(); err != nil {
+		return nil, err
+	}
+	if s.binding, err = s.nonce(); err != nil {
+		return nil, err
+	}
+	return &NonceCommitment{
+		Index:   s.share.Index,
+		Hiding:  new(ed25519.Point).ScalarBaseMult(s.hiding),
+		Binding: new(ed25519.Point).ScalarBaseMult(s.binding),
+	}, nil
+}
+
+// nonce derives a nonce hedged with the secret share, so that a weak random source alone does not leak it
+func (s *Signer) nonce() (*ed25519.Scalar, error) {
+	randBytes := make([]byte, 32)
+	if _, err := rand.Read(randBytes); err != nil {
+		return nil, err
+	}
+	hashFunc := sha512.New()
+	hashFunc.Write(randBytes)
+	hashFunc.Write(s.share.Secret.Bytes())
+	return ed25519.NewScalar().SetUniformBytes(hashFunc.Sum(nil))
+}
+
+// Sign computes the signature share of the message, given the nonce commitments of all signers.
+// The nonces are erased, so that they are never used for two signatures.
+func (s *Signer) Sign(message []byte, commitments []*NonceCommitment) (*SignatureShare, error) {
+	if s.hiding == nil {
+		return nil, errors.New("threshold: no nonce commitment for the signature")
+	}
+	hiding, binding := s.hiding, s.binding
+	s.hiding, s.binding = nil, nil
+	sorted, indices, err := sortCommitments(commitments, s.share.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	own := false
+	for _, c := range sorted {
+		if c.Index == s.share.Index {
+			own = c.Hiding.Equal(new(ed25519.Point).ScalarBaseMult(hiding)) == 1 &&
+				c.Binding.Equal(new(ed25519.Point).ScalarBaseMult(binding)) == 1
+		}
+	}
+	if !own {
+		return nil, ErrMissingNonceCommitment
+	}
+	bindingFactors, groupCommitment, err := groupCommitment(s.share.PublicKey, message, sorted)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := schnorr.Challenge(groupCommitment, s.share.PublicKey, message)
+	if err != nil {
+		return nil, err
+	}
+	lambda, err := vss.LagrangeCoefficient(s.share.Index, indices)
+	if err != nil {
+		return nil, err
+	}
+	// z = hiding + binding * rho + lambda * secret * challenge
+	value := ed25519.NewScalar().MultiplyAdd(binding, bindingFactors[s.share.Index], hiding)
+	lambda.Multiply(lambda, challenge)
+	value.MultiplyAdd(lambda, s.share.Secret, value)
+	return &SignatureShare{Index: s.share.Index, Value: value}, nil
+}
+
+// Aggregate verifies the signature shares of the message and combines them into the signature, R || z.
+// The public key and the verification shares are those of the key shares of the signers.
+func Aggregate(publicKey *ed25519.Point, verificationShares map[uint32]*ed25519.Point, message []byte,
+	commitments []*NonceCommitment, shares []*SignatureShare) ([]byte, error) {
+	sorted, indices, err := sortCommitments(commitments, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) != len(sorted) {
+		return nil, fmt.Errorf("threshold: got %d signature shares, want %d", len(shares), len(sorted))
+	}
+	bindingFactors, groupCommitment, err := groupCommitment(publicKey, message, sorted)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := schnorr.Challenge(groupCommitment, publicKey, message)
+	if err != nil {
+		return nil, err
+	}
+	byIndex := make(map[uint32]*NonceCommitment, len(sorted))
+	for _, c := range sorted {
+		byIndex[c.Index] = c
+	}
+	z := ed25519.NewScalar()
+	for _, share := range shares {
+		c, ok := byIndex[share.Index]
+		if !ok {
+			return nil, fmt.Errorf("threshold: signature share of %d without a nonce commitment", share.Index)
+		}
+		verificationShare, ok := verificationShares[share.Index]
+		if !ok {
+			return nil, fmt.Errorf("threshold: unknown signer %d", share.Index)
+		}
+		lambda, err := vss.LagrangeCoefficient(share.Index, indices)
+		if err != nil {
+			return nil, err
+		}
+		// z_i * B must be hiding + rho * binding + lambda * challenge * verification share
+		lambda.Multiply(lambda, challenge)
+		expected := new(ed25519.Point).VarTimeMultiScalarMult(
+			[]*ed25519.Scalar{bindingFactors[share.Index], lambda},
+			[]*ed25519.Point{c.Binding, verificationShare})
+		expected.Add(expected, c.Hiding)
+		if new(ed25519.Point).ScalarBaseMult(share.Value).Equal(expected) != 1 {
+			return nil, fmt.Errorf("%w of signer %d", ErrInvalidSignatureShare, share.Index)
+		}
+		z.Add(z, share.Value)
+		delete(byIndex, share.Index)
+	}
+	signature := make([]byte, 0, schnorr.SignatureSize)
+	signature = append(signature, groupCommitment.Bytes()...)
+	signature = append(signature, z.Bytes()...)
+	// valid shares of fewer signers than the threshold do not combine into a valid signature
+	if err = schnorr.Verify(publicKey, message, signature); err != nil {
+		return nil, fmt.Errorf("threshold: the signature shares do not combine into a valid signature: %w", err)
+	}
+	return signature, nil
+}
+
+// RunSign runs the signing protocol for the signer over the transport, among the signers of the indices.
+// The transport must connect exactly these signers. Every signer obtains the signature,
+// since the signature shares are broadcast and aggregated by all.
+func RunSign(s *Signer, transport Transport, message []byte, indices []uint32) ([]byte, error) {
+	commitment, err := s.Commit()
+	if err != nil {
+		return nil, err
+	}
+	if err = transport.Send(&Message{From: s.share.Index, Round: roundNonces, Payload: commitment}); err != nil {
+		return nil, err
+	}
+	received, err := transport.Receive(s.share.Index, roundNonces, len(indices)-1)
+	if err != nil {
+		return nil, err
+	}
+	commitments := []*NonceCommitment{commitment}
+	for _, msg := range received {
+		payload, ok := msg.Payload.(*NonceCommitment)
+		if !ok || payload.Index != msg.From {
+			return nil, fmt.Errorf("threshold: unexpected nonce commitment of signer %d", msg.From)
+		}
+		commitments = append(commitments, payload)
+	}
+	share, err := s.Sign(message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	if err = transport.Send(&Message{From: s.share.Index, Round: roundSignatureShares, Payload: share}); err != nil {
+		return nil, err
+	}
+	received, err = transport.Receive(s.share.Index, roundSignatureShares, len(indices)-1)
+	if err != nil {
+		return nil, err
+	}
+	shares := []*SignatureShare{share}
+	for _, msg := range received {
+		payload, ok := msg.Payload.(*SignatureShare)
+		if !ok || payload.Index != msg.From {
+			return nil, fmt.Errorf("threshold: unexpected signature share of signer %d", msg.From)
+		}
+		shares = append(shares, payload)
+	}
+	return Aggregate(s.share.PublicKey, s.share.VerificationShares, message, commitments, shares)
+}
+
+// sortCommitments sorts the nonce commitments by index, checking that there are at least threshold distinct signers
+func sortCommitments(commitments []*NonceCommitment, threshold int) ([]*NonceCommitment, []uint32, error) {
+	if len(commitments) < threshold {
+		return nil, nil, fmt.Errorf("threshold: got %d signers, want at least %d", len(commitments), threshold)
+	}
+	sorted := append([]*NonceCommitment{}, commitments...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+	indices := make([]uint32, len(sorted))
+	for i, c := range sorted {
+		if i > 0 && c.Index == sorted[i-1].Index {
+			return nil, nil, fmt.Errorf("threshold: duplicate signer %d", c.Index)
+		}
+		indices[i] = c.Index
+	}
+	return sorted, indices, nil
+}
+
+// groupCommitment computes the binding factor of every signer and the group commitment R,
+// the binding factors bind every nonce to the group public key, the message and the commitments of all signers,
+// as in RFC 9591
+func groupCommitment(publicKey *ed25519.Point, message []byte,
+	sorted []*NonceCommitment) (map[uint32]*ed25519.Scalar, *ed25519.Point, error) {
+	encoded := make([]byte, 0, len(sorted)*68)
+	for _, c := range sorted {
+		encoded = binary.BigEndian.AppendUint32(encoded, c.Index)
+		encoded = append(encoded, c.Hiding.Bytes()...)
+		encoded = append(encoded, c.Binding.Bytes()...)
+	}
+	publicKeyBytes := publicKey.Bytes()
+	messageHash := sha512.Sum512(message)
+	bindingFactors := make(map[uint32]*ed25519.Scalar, len(sorted))
+	r := ed25519.NewIdentityPoint()
+	for _, c := range sorted {
+		hashFunc := sha512.New()
+		hashFunc.Write([]byte(bindingDomain))
+		hashFunc.Write(binary.BigEndian.AppendUint32(nil, c.Index))
+		hashFunc.Write(publicKeyBytes)
+		hashFunc.Write(messageHash[:])
+		hashFunc.Write(encoded)
+		rho, err := ed25519.NewScalar().SetUniformBytes(hashFunc.Sum(nil))
+		if err != nil {
+			return nil, nil, err
+		}
+		bindingFactors[c.Index] = rho
+		r.Add(r, c.Hiding)
+		r.Add(r, new(ed25519.Point).ScalarMult(rho, c.Binding))
+	}
+	return bindingFactors, r, nil
+}
@@ -0,0 +1,308 @@
+package threshold
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/auditing"
+	"github.com/auti-project/auti-core/schnorr"
+	"github.com/auti-project/auti-core/vss"
+)
+
+func runDKG(t *testing.T, threshold int, indices []uint32) map[uint32]*KeyShare {
+	t.Helper()
+	transport := NewLocalTransport(indices)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		shares = make(map[uint32]*KeyShare, len(indices))
+	)
+	for _, index := range indices {
+		p, err := NewParticipant(index, threshold, indices)
+		if err != nil {
+			t.Fatalf("NewParticipant() error = %v", err)
+		}
+		wg.Add(1)
+		go func(index uint32) {
+			defer wg.Done()
+			share, err := RunDKG(p, transport)
+			if err != nil {
+				t.Errorf("RunDKG() of %d error = %v", index, err)
+				return
+			}
+			mu.Lock()
+			shares[index] = share
+			mu.Unlock()
+		}(index)
+	}
+	wg.Wait()
+	if len(shares) != len(indices) {
+		t.FailNow()
+	}
+	return shares
+}
+
+func runSign(t *testing.T, shares map[uint32]*KeyShare, signers []uint32, message []byte) [][]byte {
+	t.Helper()
+	transport := NewLocalTransport(signers)
+	var wg sync.WaitGroup
+	signatures := make([][]byte, len(signers))
+	for i, index := range signers {
+		wg.Add(1)
+		go func(i int, index uint32) {
+			defer wg.Done()
+			signature, err := RunSign(NewSigner(shares[index]), transport, message, signers)
+			if err != nil {
+				t.Errorf("RunSign() of %d error = %v", index, err)
+				return
+			}
+			signatures[i] = signature
+		}(i, index)
+	}
+	wg.Wait()
+	return signatures
+}
+
+func TestRunDKG(t *testing.T) {
+	indices := []uint32{1, 2, 3, 4, 5}
+	shares := runDKG(t, 3, indices)
+	publicKey := shares[1].PublicKey
+	for _, share := range shares {
+		if share.PublicKey.Equal(publicKey) != 1 {
+			t.Errorf("RunDKG() of %d got a different joint public key", share.Index)
+		}
+		for i, verificationShare := range share.VerificationShares {
+			if verificationShare.Equal(shares[i].VerificationShares[i]) != 1 {
+				t.Errorf("RunDKG() of %d got a different verification share of %d", share.Index, i)
+			}
+		}
+	}
+	// any threshold of shares reconstruct the secret key of the joint public key
+	tests := []struct {
+		name    string
+		indices []uint32
+		want    bool
+	}{
+		{name: "Test_Threshold_Shares", indices: []uint32{1, 3, 5}, want: true},
+		{name: "Test_All_Shares", indices: indices, want: true},
+		{name: "Test_Too_Few_Shares", indices: []uint32{2, 4}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := ed25519.NewScalar()
+			for _, index := range tt.indices {
+				lambda, err := vss.LagrangeCoefficient(index, tt.indices)
+				if err != nil {
+					t.Fatalf("LagrangeCoefficient() error = %v", err)
+				}
+				secret.MultiplyAdd(lambda, shares[index].Secret, secret)
+			}
+			got := new(ed25519.Point).ScalarBaseMult(secret).Equal(publicKey) == 1
+			if got != tt.want {
+				t.Errorf("reconstructed secret matches the joint public key = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParticipant_Round2(t *testing.T) {
+	indices := []uint32{1, 2, 3}
+	participants := make(map[uint32]*Participant)
+	round1 := make(map[uint32]*Round1Message)
+	for _, index := range indices {
+		p, err := NewParticipant(index, 2, indices)
+		if err != nil {
+			t.Fatalf("NewParticipant() error = %v", err)
+		}
+		participants[index] = p
+		if round1[index], err = p.Round1(); err != nil {
+			t.Fatalf("Round1() error = %v", err)
+		}
+	}
+	// participant 3 claims the commitments of participant 2, without knowing its secret
+	round1[3] = &Round1Message{Commitments: round1[2].Commitments, Proof: round1[2].Proof}
+	_, err := participants[1].Round2(map[uint32]*Round1Message{2: round1[2], 3: round1[3]})
+	if !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("Round2() error = %v, want %v", err, ErrInvalidProof)
+	}
+}
+
+func TestRunSign(t *testing.T) {
+	shares := runDKG(t, 3, []uint32{1, 2, 3, 4, 5})
+	publicKey := shares[1].PublicKey
+	record := auditing.NewRecord([]byte("epoch 1 passed"), 1, "committee")
+	err := record.SignWith(func(message []byte) ([]byte, error) {
+		signatures := runSign(t, shares, []uint32{1, 3, 5}, message)
+		for _, signature := range signatures {
+			if err := schnorr.Verify(publicKey, message, signature); err != nil {
+				t.Errorf("RunSign() signature does not verify, error = %v", err)
+			}
+		}
+		return signatures[0], nil
+	})
+	if err != nil {
+		t.Fatalf("SignWith() error = %v", err)
+	}
+	// the committee signature is an ordinary signature of the auditing record
+	if err := record.Verify(publicKey); err != nil {
+		t.Errorf("Verify() of the jointly signed record error = %v", err)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	shares := runDKG(t, 2, []uint32{1, 2, 3})
+	message := []byte("message")
+	signers := []*Signer{NewSigner(shares[1]), NewSigner(shares[3])}
+	var commitments []*NonceCommitment
+	for _, s := range signers {
+		c, err := s.Commit()
+		if err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		commitments = append(commitments, c)
+	}
+	var signatureShares []*SignatureShare
+	for _, s := range signers {
+		share, err := s.Sign(message, commitments)
+		if err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		signatureShares = append(signatureShares, share)
+	}
+	tampered := &SignatureShare{
+		Index: signatureShares[1].Index,
+		Value: ed25519.NewScalar().Add(signatureShares[1].Value, vss.ScalarFromIndex(1)),
+	}
+	tests := []struct {
+		name    string
+		message []byte
+		shares  []*SignatureShare
+		wantErr error
+	}{
+		{
+			name:    "Test_Valid_Shares",
+			message: message,
+			shares:  signatureShares,
+		},
+		{
+			name:    "Test_Tampered_Share",
+			message: message,
+			shares:  []*SignatureShare{signatureShares[0], tampered},
+			wantErr: ErrInvalidSignatureShare,
+		},
+		{
+			name:    "Test_Other_Message",
+			message: []byte("massage"),
+			shares:  signatureShares,
+			wantErr: ErrInvalidSignatureShare,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, err := Aggregate(shares[1].PublicKey, shares[1].VerificationShares, tt.message,
+				commitments, tt.shares)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Aggregate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err = schnorr.Verify(shares[1].PublicKey, tt.message, signature); err != nil {
+					t.Errorf("Aggregate() signature does not verify, error = %v", err)
+				}
+			}
+		})
+	}
+	// the nonces are used once
+	if _, err := signers[0].Sign(message, commitments); err == nil {
+		t.Errorf("Sign() reused the nonces")
+	}
+}
+
+func TestSigner_Sign_BelowThreshold(t *testing.T) {
+	shares := runDKG(t, 3, []uint32{1, 2, 3})
+	signer := NewSigner(shares[1])
+	c1, err := signer.Commit()
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	c2, err := NewSigner(shares[2]).Commit()
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err = signer.Sign([]byte("message"), []*NonceCommitment{c1, c2}); err == nil {
+		t.Errorf("Sign() accepted 2 signers for a threshold of 3")
+	}
+}
+
+func TestSigner_Sign_OwnCommitment(t *testing.T) {
+	shares := runDKG(t, 2, []uint32{1, 2, 3})
+	other, err := NewSigner(shares[2]).Commit()
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	// a commitment to nonces that the signer did not sample
+	foreign := new(ed25519.Point).ScalarBaseMult(vss.ScalarFromIndex(7))
+	tests := []struct {
+		name    string
+		tamper  func(c *NonceCommitment) *NonceCommitment
+		wantErr error
+	}{
+		{
+			name:   "Test_Own_Commitment",
+			tamper: func(c *NonceCommitment) *NonceCommitment { return c },
+		},
+		{
+			name: "Test_Replaced_Hiding",
+			tamper: func(c *NonceCommitment) *NonceCommitment {
+				return &NonceCommitment{Index: c.Index, Hiding: foreign, Binding: c.Binding}
+			},
+			wantErr: ErrMissingNonceCommitment,
+		},
+		{
+			name: "Test_Replaced_Binding",
+			tamper: func(c *NonceCommitment) *NonceCommitment {
+				return &NonceCommitment{Index: c.Index, Hiding: c.Hiding, Binding: foreign}
+			},
+			wantErr: ErrMissingNonceCommitment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewSigner(shares[1])
+			c, err := signer.Commit()
+			if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+			_, err = signer.Sign([]byte("message"), []*NonceCommitment{tt.tamper(c), other})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Sign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroupCommitment_PublicKey(t *testing.T) {
+	shares := runDKG(t, 2, []uint32{1, 2})
+	var commitments []*NonceCommitment
+	for _, index := range []uint32{1, 2} {
+		c, err := NewSigner(shares[index]).Commit()
+		if err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		commitments = append(commitments, c)
+	}
+	message := []byte("message")
+	factors, _, err := groupCommitment(shares[1].PublicKey, message, commitments)
+	if err != nil {
+		t.Fatalf("groupCommitment() error = %v", err)
+	}
+	otherKey := new(ed25519.Point).Add(shares[1].PublicKey, ed25519.NewGeneratorPoint())
+	otherFactors, _, err := groupCommitment(otherKey, message, commitments)
+	if err != nil {
+		t.Fatalf("groupCommitment() error = %v", err)
+	}
+	if factors[1].Equal(otherFactors[1]) == 1 {
+		t.Errorf("groupCommitment() binding factors do not depend on the group public key")
+	}
+}
@@ -0,0 +1,90 @@
+package threshold
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Message is a protocol message between participants, To is zero for a message broadcast to all other participants
+type Message struct {
+	From    uint32
+	To      uint32
+	Round   int
+	Payload any
+}
+
+// Transport carries protocol messages between participants
+type Transport interface {
+	// Send sends the message to its recipient, or to all other participants if To is zero
+	Send(msg *Message) error
+	// Receive blocks until count messages of the round for the participant arrived, and returns them
+	Receive(index uint32, round, count int) ([]*Message, error)
+}
+
+type mailboxKey struct {
+	index uint32
+	round int
+}
+
+// LocalTransport is an in-process transport, for running the protocols in tests and simulations
+type LocalTransport struct {
+	mu           sync.Mutex
+	participants map[uint32]struct{}
+	mailboxes    map[mailboxKey]chan *Message
+}
+
+// NewLocalTransport creates a new in-process transport between the participants of the indices
+func NewLocalTransport(indices []uint32) *LocalTransport {
+	t := &LocalTransport{
+		participants: make(map[uint32]struct{}, len(indices)),
+		mailboxes:    make(map[mailboxKey]chan *Message),
+	}
+	for _, index := range indices {
+		t.participants[index] = struct{}{}
+	}
+	return t
+}
+
+// mailbox returns the mailbox of the participant for the round, every participant
+// receives at most one message of every other participant per round, so it never blocks
+func (t *LocalTransport) mailbox(index uint32, round int) chan *Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := mailboxKey{index: index, round: round}
+	mailbox, ok := t.mailboxes[key]
+	if !ok {
+		mailbox = make(chan *Message, len(t.participants))
+		t.mailboxes[key] = mailbox
+	}
+	return mailbox
+}
+
+// Send sends the message to its recipient, or to all other participants if To is zero
+func (t *LocalTransport) Send(msg *Message) error {
+	if msg.To != 0 {
+		if _, ok := t.participants[msg.To]; !ok {
+			return fmt.Errorf("threshold: unknown recipient %d", msg.To)
+		}
+		t.mailbox(msg.To, msg.Round) <- msg
+		return nil
+	}
+	for index := range t.participants {
+		if index != msg.From {
+			t.mailbox(index, msg.Round) <- msg
+		}
+	}
+	return nil
+}
+
+// Receive blocks until count messages of the round for the participant arrived, and returns them
+func (t *LocalTransport) Receive(index uint32, round, count int) ([]*Message, error) {
+	if _, ok := t.participants[index]; !ok {
+		return nil, fmt.Errorf("threshold: unknown participant %d", index)
+	}
+	mailbox := t.mailbox(index, round)
+	messages := make([]*Message, count)
+	for i := range messages {
+		messages[i] = <-mailbox
+	}
+	return messages, nil
+}
@@ -0,0 +1,106 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/auti-project/auti-core/commitment"
+)
+
+// ErrBatchSize is returned when a batch has a different number of transactions and counters
+var ErrBatchSize = errors.New("transaction: batch of transactions and counters of different sizes")
+
+// Batch hides slices of plaintext transactions on a pool of workers, with a commitment.Committer of the parameters.
+// Its hidden transactions are those of HideWith and HidePairWith, byte for byte. A Batch is safe for concurrent use.
+type Batch struct {
+	committer *commitment.Committer
+	workers   int
+}
+
+// NewBatch precomputes the tables of the generators of the parameters, for a pool of the number of workers,
+// or of runtime.GOMAXPROCS(0) workers if it is not positive
+func NewBatch(params *commitment.Params, workers int) *Batch {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &Batch{committer: params.Committer(), workers: workers}
+}
+
+// Hide hides the transactions with their counters, as HideWith
+func (b *Batch) Hide(plains []*Plain, counters []uint64, negateHash bool) ([]*Hidden, error) {
+	if len(plains) != len(counters) {
+		return nil, fmt.Errorf("%w: %d and %d", ErrBatchSize, len(plains), len(counters))
+	}
+	hiddens := make([]*Hidden, len(plains))
+	err := b.run(len(plains), func(i int) (err error) {
+		hiddens[i], err = plains[i].hide(counters[i], b.committer.Params(), b.committer.Commit, negateHash)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hiddens, nil
+}
+
+// HidePair creates the hidden transaction pairs of the transactions with their counters, as HidePairWith
+func (b *Batch) HidePair(plains []*Plain, counters []uint64) (h1s, h2s []*Hidden, err error) {
+	if len(plains) != len(counters) {
+		return nil, nil, fmt.Errorf("%w: %d and %d", ErrBatchSize, len(plains), len(counters))
+	}
+	h1s = make([]*Hidden, len(plains))
+	h2s = make([]*Hidden, len(plains))
+	err = b.run(len(plains), func(i int) (err error) {
+		h1s[i], h2s[i], err = plains[i].hidePair(counters[i], b.committer.Params(), b.committer.Commit)
+		return
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return h1s, h2s, nil
+}
+
+// HideNext hides the transactions as Hide, drawing their counters from the source in order.
+// The counters are returned, since they are needed to open the commitments.
+func (b *Batch) HideNext(plains []*Plain, source CounterSource, negateHash bool) ([]*Hidden, []uint64, error) {
+	counters := make([]uint64, len(plains))
+	for i := range counters {
+		var err error
+		if counters[i], err = source.Next(); err != nil {
+			return nil, nil, err
+		}
+	}
+	hiddens, err := b.Hide(plains, counters, negateHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hiddens, counters, nil
+}
+
+// run calls f for the indices below n on the workers, and returns the error of the lowest failed index
+func (b *Batch) run(n int, f func(i int) error) error {
+	errs := make([]error, n)
+	indices := make(chan int, b.workers)
+	var wg sync.WaitGroup
+	for w := 0; w < b.workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package transaction
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/counter"
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+func batchPlains(n int) []*Plain {
+	plains := make([]*Plain, n)
+	amounts := []int64{0, 1, -1, 1500, -42, math.MaxInt64, math.MinInt64 + 1}
+	timestamp := time.Now().UnixNano()
+	for i := range plains {
+		plains[i] = &Plain{
+			Sender:    "org1",
+			Receiver:  "org2",
+			Amount:    amounts[i%len(amounts)],
+			Timestamp: timestamp + int64(i),
+		}
+	}
+	plains[1].Auxiliary = []byte("invoice 7")
+	return plains
+}
+
+func batchCounters(n int) []uint64 {
+	counters := make([]uint64, n)
+	for i := range counters {
+		counters[i] = uint64(i) * 7919
+	}
+	return counters
+}
+
+func TestBatch_Hide(t *testing.T) {
+	g, h := paramSetup()
+	plains := batchPlains(50)
+	counters := batchCounters(len(plains))
+	tests := []struct {
+		name       string
+		params     *commitment.Params
+		workers    int
+		negateHash bool
+	}{
+		{"Test_Default", commitment.NewParams(g, h), 0, false},
+		{"Test_Negated", commitment.NewParams(g, h), 3, true},
+		{"Test_Single_Worker", commitment.NewParams(g, h), 1, false},
+		{"Test_More_Workers_Than_Transactions", commitment.NewParams(g, h), 64, false},
+		{"Test_Tagged_Suite", &commitment.Params{G: g, H: h, Suite: hashsuite.SHA512_256}, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := NewBatch(tt.params, tt.workers)
+			got, err := batch.Hide(plains, counters, tt.negateHash)
+			if err != nil {
+				t.Fatalf("Hide() error = %v", err)
+			}
+			got1, got2, err := batch.HidePair(plains, counters)
+			if err != nil {
+				t.Fatalf("HidePair() error = %v", err)
+			}
+			for i, p := range plains {
+				want, err := p.HideWith(counters[i], tt.params, tt.negateHash)
+				handleErr(err)
+				if !reflect.DeepEqual(got[i], want) {
+					t.Errorf("Hide() of transaction %d = %v, want %v", i, got[i], want)
+				}
+				want1, want2, err := p.HidePairWith(counters[i], tt.params)
+				handleErr(err)
+				if !reflect.DeepEqual(got1[i], want1) || !reflect.DeepEqual(got2[i], want2) {
+					t.Errorf("HidePair() of transaction %d = %v, %v, want %v, %v", i, got1[i], got2[i], want1, want2)
+				}
+			}
+		})
+	}
+}
+
+func TestBatch_Errors(t *testing.T) {
+	g, h := paramSetup()
+	batch := NewBatch(commitment.NewParams(g, h), 4)
+	plains := batchPlains(10)
+	if _, err := batch.Hide(plains, batchCounters(9), false); !errors.Is(err, ErrBatchSize) {
+		t.Errorf("Hide() error = %v, want %v", err, ErrBatchSize)
+	}
+	if _, _, err := batch.HidePair(plains, batchCounters(11)); !errors.Is(err, ErrBatchSize) {
+		t.Errorf("HidePair() error = %v, want %v", err, ErrBatchSize)
+	}
+	plains[3].Amount = math.MinInt64
+	plains[7].Amount = math.MinInt64
+	_, _, err := batch.HidePair(plains, batchCounters(len(plains)))
+	if err == nil || !strings.HasPrefix(err.Error(), "transaction 3:") {
+		t.Errorf("HidePair() error = %v, want the error of transaction 3", err)
+	}
+	if got, err := batch.Hide(nil, nil, false); err != nil || len(got) != 0 {
+		t.Errorf("Hide() of no transactions = %v, %v", got, err)
+	}
+}
+
+func TestBatch_HideNext(t *testing.T) {
+	g, h := paramSetup()
+	params := commitment.NewParams(g, h)
+	allocator, err := counter.NewAllocator(nil)
+	handleErr(err)
+	source := allocator.Source(counter.Key{OrgID: "org1", ChainID: "org2"})
+	plains := batchPlains(20)
+	got, counters, err := NewBatch(params, 0).HideNext(plains, source, false)
+	if err != nil {
+		t.Fatalf("HideNext() error = %v", err)
+	}
+	seen := make(map[uint64]bool)
+	for i, p := range plains {
+		if seen[counters[i]] {
+			t.Errorf("HideNext() reused counter %d", counters[i])
+		}
+		seen[counters[i]] = true
+		want, err := p.HideWith(counters[i], params, false)
+		handleErr(err)
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("HideNext() of transaction %d = %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+const benchmarkBatchSize = 1024
+
+func BenchmarkPlain_HideWith(b *testing.B) {
+	g, h := paramSetup()
+	params := commitment.NewParams(g, h)
+	plains := batchPlains(benchmarkBatchSize)
+	counters := batchCounters(benchmarkBatchSize)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		for j, p := range plains {
+			if _, err := p.HideWith(counters[j], params, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkBatchSize)/time.Since(start).Seconds(), "tx/s")
+}
+
+func BenchmarkBatch_Hide(b *testing.B) {
+	g, h := paramSetup()
+	batch := NewBatch(commitment.NewParams(g, h), 0)
+	plains := batchPlains(benchmarkBatchSize)
+	counters := batchCounters(benchmarkBatchSize)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := batch.Hide(plains, counters, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkBatchSize)/time.Since(start).Seconds(), "tx/s")
+}
+
+func BenchmarkBatch_HideSingleWorker(b *testing.B) {
+	g, h := paramSetup()
+	batch := NewBatch(commitment.NewParams(g, h), 1)
+	plains := batchPlains(benchmarkBatchSize)
+	counters := batchCounters(benchmarkBatchSize)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := batch.Hide(plains, counters, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkBatchSize)/time.Since(start).Seconds(), "tx/s")
+}
+
+func BenchmarkBatch_HidePair(b *testing.B) {
+	g, h := paramSetup()
+	batch := NewBatch(commitment.NewParams(g, h), 0)
+	plains := batchPlains(benchmarkBatchSize)
+	counters := batchCounters(benchmarkBatchSize)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := batch.HidePair(plains, counters); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkBatchSize)/time.Since(start).Seconds(), "tx/s")
+}
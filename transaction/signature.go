@@ -0,0 +1,123 @@
+package transaction
+
+import (
+	"encoding/binary"
+	"errors"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/schnorr"
+)
+
+// signatureDomain separates transaction signatures from other messages signed with the same key
+const signatureDomain = "auti-core/transaction/signature"
+
+var (
+	// ErrUnsigned is returned when verifying a transaction that carries no signature
+	ErrUnsigned = errors.New("transaction: unsigned transaction")
+	// ErrInvalidSignature is returned when the signature does not verify against the public key
+	ErrInvalidSignature = errors.New("transaction: invalid signature")
+)
+
+// KeyResolver looks up the public key of an organization by the pseudonym hash of its name,
+// such as an identity.Registry
+type KeyResolver interface {
+	PublicKeyByPseudonym(pseudonym []byte) (*ed25519.Point, error)
+}
+
+// HideSigned converts a plaintext transaction to a hidden transaction signed by the originating organization
+func (p *Plain) HideSigned(counter uint64, g, h *ed25519.Point, negateHash bool,
+	privateKey *ed25519.Scalar) (*Hidden, error) {
+	hidden, err := p.Hide(counter, g, h, negateHash)
+	if err != nil {
+		return nil, err
+	}
+	if err = hidden.Sign(privateKey); err != nil {
+		return nil, err
+	}
+	return hidden, nil
+}
+
+// HidePairSigned creates the hidden transaction pairs, the first signed by the originating organization.
+// A transaction is verified against the key of its sender, so the second one, whose sender is the receiver
+// of the plaintext transaction, is left unsigned: the receiver signs it with Sign when it records it.
+func (p *Plain) HidePairSigned(counter uint64, g, h *ed25519.Point,
+	privateKey *ed25519.Scalar) (h1, h2 *Hidden, err error) {
+	if h1, h2, err = p.HidePair(counter, g, h); err != nil {
+		return
+	}
+	err = h1.Sign(privateKey)
+	return
+}
+
+// Sign signs the hidden transaction with the private key of the originating organization, its sender
+func (h *Hidden) Sign(privateKey *ed25519.Scalar) error {
+	signature, err := schnorr.Sign(privateKey, h.signedBytes())
+	if err != nil {
+		return err
+	}
+	h.Signature = signature
+	return nil
+}
+
+// Verify verifies the signature of the hidden transaction against the public key of the originating organization
+func (h *Hidden) Verify(publicKey *ed25519.Point) error {
+	if len(h.Signature) == 0 {
+		return ErrUnsigned
+	}
+	if err := schnorr.Verify(publicKey, h.signedBytes(), h.Signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyWith verifies the signature of the hidden transaction against the public key of its sender looked up in keys.
+// The originating organization of a transaction is always its sender, so the key of the receiver is never accepted.
+func (h *Hidden) VerifyWith(keys KeyResolver) error {
+	if len(h.Signature) == 0 {
+		return ErrUnsigned
+	}
+	publicKey, err := keys.PublicKeyByPseudonym(h.Sender)
+	if err != nil {
+		return err
+	}
+	return h.Verify(publicKey)
+}
+
+// signedBytes returns the message covered by the signature, all fields except the signature itself,
+// and the version but in version 1 transactions
+func (h *Hidden) signedBytes() []byte {
+	var buf []byte
+	buf = append(buf, protocol.SignatureDomain(signatureDomain, h.Version)...)
+	for _, field := range [][]byte{h.Sender, h.Receiver, h.Commitment, h.Auxiliary} {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+	return binary.BigEndian.AppendUint64(buf, uint64(h.Timestamp))
+}
+
+// ToHideVerifiedWith converts an on-chain transaction to a hidden transaction,
+// rejecting it if it is unsigned or the signature does not verify against the key of its sender
+func (o *OnChain) ToHideVerifiedWith(keys KeyResolver) (*Hidden, error) {
+	hiddenTX, err := o.ToHide()
+	if err != nil {
+		return nil, err
+	}
+	if err = hiddenTX.VerifyWith(keys); err != nil {
+		return nil, err
+	}
+	return hiddenTX, nil
+}
+
+// ToHideVerified converts an on-chain transaction to a hidden transaction,
+// rejecting it if it is unsigned or the signature does not verify against the public key
+func (o *OnChain) ToHideVerified(publicKey *ed25519.Point) (*Hidden, error) {
+	hiddenTX, err := o.ToHide()
+	if err != nil {
+		return nil, err
+	}
+	if err = hiddenTX.Verify(publicKey); err != nil {
+		return nil, err
+	}
+	return hiddenTX, nil
+}
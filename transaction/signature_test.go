@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ed25519 "filippo.io/edwards25519"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+)
+
+func TestOnChain_ToHideVerified(t *testing.T) {
+	g, h := paramSetup()
+	publicKey, privateKey, err := autied25519.KeyGen()
+	handleErr(err)
+	otherPublicKey, otherPrivateKey, err := autied25519.KeyGen()
+	handleErr(err)
+	p := &Plain{
+		Sender:    "sender",
+		Receiver:  "receiver",
+		Amount:    100,
+		Auxiliary: []byte("aux"),
+		Timestamp: time.Now().UnixNano(),
+	}
+	h1, h2, err := p.HidePairSigned(100, g, h, privateKey)
+	handleErr(err)
+	unsignedPair := h2.ToOnChain()
+	// the receiver signs its own record of the pair
+	handleErr(h2.Sign(otherPrivateKey))
+	unsigned, err := p.Hide(100, g, h, false)
+	handleErr(err)
+	tampered := h1.ToOnChain()
+	tampered.Commitment = h2.ToOnChain().Commitment
+	tests := []struct {
+		name      string
+		onChain   *OnChain
+		publicKey *ed25519.Point
+		wantErr   error
+	}{
+		{
+			name:      "Test_Signed",
+			onChain:   h1.ToOnChain(),
+			publicKey: publicKey,
+		},
+		{
+			name:      "Test_Signed_Pair",
+			onChain:   h2.ToOnChain(),
+			publicKey: otherPublicKey,
+		},
+		{
+			name:      "Test_Unsigned_Pair",
+			onChain:   unsignedPair,
+			publicKey: otherPublicKey,
+			wantErr:   ErrUnsigned,
+		},
+		{
+			name:      "Test_Signed_Pair_Wrong_Key",
+			onChain:   h2.ToOnChain(),
+			publicKey: publicKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "Test_Unsigned",
+			onChain:   unsigned.ToOnChain(),
+			publicKey: publicKey,
+			wantErr:   ErrUnsigned,
+		},
+		{
+			name:      "Test_Wrong_Key",
+			onChain:   h1.ToOnChain(),
+			publicKey: otherPublicKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "Test_Tampered_Commitment",
+			onChain:   tampered,
+			publicKey: publicKey,
+			wantErr:   ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.onChain.ToHideVerified(tt.publicKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToHideVerified() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && string(got.Commitment) != string(h1.Commitment) && string(got.Commitment) != string(h2.Commitment) {
+				t.Errorf("ToHideVerified() got = %v, want the signed commitment", got.Commitment)
+			}
+		})
+	}
+}
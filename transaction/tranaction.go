@@ -1,13 +1,16 @@
 package transaction
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"math"
 	"strconv"
 
 	ed25519 "filippo.io/edwards25519"
 	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/protocol"
 )
 
 // Plain is the struct for plaintext transaction
@@ -30,17 +33,45 @@ func NewPlain(sender, receiver string, amount int64) *Plain {
 
 // Hide converts a plaintext transaction to a hidden transaction
 func (p *Plain) Hide(counter uint64, g, h *ed25519.Point, negateHash bool) (*Hidden, error) {
-	hashFunc := sha256.New()
-	hashFunc.Write([]byte(p.Sender))
-	senderHash := hashFunc.Sum(nil)
-	hashFunc.Reset()
-	hashFunc.Write([]byte(p.Receiver))
-	receiverHash := hashFunc.Sum(nil)
-	c, err := commitment.Commit(p.Amount, p.Timestamp, counter, g, h, negateHash)
+	return p.HideWith(counter, &commitment.Params{G: g, H: h}, negateHash)
+}
+
+// HideWith converts a plaintext transaction to a hidden transaction of the protocol version of the parameters,
+// hashing with their suite. It commits in constant time, as commitment.CommitWith.
+func (p *Plain) HideWith(counter uint64, params *commitment.Params, negateHash bool) (*Hidden, error) {
+	return p.hide(counter, params, commitWith(params), negateHash)
+}
+
+// HidePair creates the hidden transaction pairs
+func (p *Plain) HidePair(counter uint64, g, h *ed25519.Point) (h1, h2 *Hidden, err error) {
+	return p.HidePairWith(counter, &commitment.Params{G: g, H: h})
+}
+
+// HidePairWith creates the hidden transaction pairs of the protocol version of the parameters,
+// hashing with their suite. It commits in constant time, as commitment.CommitWith, after rejecting the amount math.MinInt64.
+func (p *Plain) HidePairWith(counter uint64, params *commitment.Params) (h1, h2 *Hidden, err error) {
+	return p.hidePair(counter, params, commitWith(params))
+}
+
+// commitFunc generates the commitment of a transaction, as commitment.CommitWith
+type commitFunc func(amount, timestamp int64, counter uint64, negateHash bool) ([]byte, error)
+
+func commitWith(params *commitment.Params) commitFunc {
+	return func(amount, timestamp int64, counter uint64, negateHash bool) ([]byte, error) {
+		return commitment.CommitWith(params, amount, timestamp, counter, negateHash)
+	}
+}
+
+// hide hides the transaction as a record of the protocol version of the parameters
+func (p *Plain) hide(counter uint64, params *commitment.Params, commit commitFunc, negateHash bool) (*Hidden, error) {
+	senderHash := params.Suite.Sum(hashsuite.Name, []byte(p.Sender))
+	receiverHash := params.Suite.Sum(hashsuite.Name, []byte(p.Receiver))
+	c, err := commit(p.Amount, p.Timestamp, counter, negateHash)
 	if err != nil {
 		return nil, err
 	}
 	return &Hidden{
+		Version:    protocol.Field(params.ProtocolVersion()),
 		Sender:     senderHash,
 		Receiver:   receiverHash,
 		Commitment: c,
@@ -49,22 +80,24 @@ func (p *Plain) Hide(counter uint64, g, h *ed25519.Point, negateHash bool) (*Hid
 	}, nil
 }
 
-// HidePair creates the hidden transaction pairs
-func (p *Plain) HidePair(counter uint64, g, h *ed25519.Point) (h1, h2 *Hidden, err error) {
-	hashFunc := sha256.New()
-	hashFunc.Write([]byte(p.Sender))
-	senderHash := hashFunc.Sum(nil)
-	hashFunc.Reset()
-	hashFunc.Write([]byte(p.Receiver))
-	receiverHash := hashFunc.Sum(nil)
+// hidePair hides the transaction pair as records of the protocol version of the parameters
+func (p *Plain) hidePair(counter uint64, params *commitment.Params, commit commitFunc) (h1, h2 *Hidden, err error) {
+	// the pair commits to -amount, which does not exist for math.MinInt64
+	if p.Amount == math.MinInt64 {
+		err = fmt.Errorf("amount %d cannot be negated for the hidden transaction pair", p.Amount)
+		return
+	}
+	senderHash := params.Suite.Sum(hashsuite.Name, []byte(p.Sender))
+	receiverHash := params.Suite.Sum(hashsuite.Name, []byte(p.Receiver))
 	var c1, c2 []byte
-	if c1, err = commitment.Commit(p.Amount, p.Timestamp, counter, g, h, false); err != nil {
+	if c1, err = commit(p.Amount, p.Timestamp, counter, false); err != nil {
 		return
 	}
-	if c2, err = commitment.Commit(-p.Amount, p.Timestamp, counter, g, h, true); err != nil {
+	if c2, err = commit(-p.Amount, p.Timestamp, counter, true); err != nil {
 		return
 	}
 	h1 = &Hidden{
+		Version:    protocol.Field(params.ProtocolVersion()),
 		Sender:     senderHash,
 		Receiver:   receiverHash,
 		Commitment: c1,
@@ -72,6 +105,7 @@ func (p *Plain) HidePair(counter uint64, g, h *ed25519.Point) (h1, h2 *Hidden, e
 		Timestamp:  p.Timestamp,
 	}
 	h2 = &Hidden{
+		Version:    protocol.Field(params.ProtocolVersion()),
 		Sender:     receiverHash,
 		Receiver:   senderHash,
 		Commitment: c2,
@@ -81,18 +115,50 @@ func (p *Plain) HidePair(counter uint64, g, h *ed25519.Point) (h1, h2 *Hidden, e
 	return
 }
 
+// CounterSource allocates commitment counters, such as the source of a counter.Allocator
+type CounterSource interface {
+	Next() (uint64, error)
+}
+
+// HideNext converts a plaintext transaction to a hidden transaction, drawing the counter from the source.
+// The counter is returned, since it is needed to open the commitment.
+func (p *Plain) HideNext(source CounterSource, g, h *ed25519.Point, negateHash bool) (*Hidden, uint64, error) {
+	counter, err := source.Next()
+	if err != nil {
+		return nil, 0, err
+	}
+	hidden, err := p.Hide(counter, g, h, negateHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hidden, counter, nil
+}
+
+// HidePairNext creates the hidden transaction pairs, drawing the counter from the source
+func (p *Plain) HidePairNext(source CounterSource, g, h *ed25519.Point) (h1, h2 *Hidden, counter uint64, err error) {
+	if counter, err = source.Next(); err != nil {
+		return
+	}
+	h1, h2, err = p.HidePair(counter, g, h)
+	return
+}
+
 // Hidden is the struct for hidden transaction
 type Hidden struct {
+	// Version is the protocol version, 0 in version 1 transactions
+	Version    int
 	Sender     []byte
 	Receiver   []byte
 	Commitment []byte
 	Auxiliary  []byte
 	Timestamp  int64
+	Signature  []byte
 }
 
 // NewHidden creates a new hidden transaction
 func NewHidden(sender, receiver, commitment, auxiliary []byte, timestamp int64) *Hidden {
 	return &Hidden{
+		Version:    protocol.CurrentVersion,
 		Sender:     sender,
 		Receiver:   receiver,
 		Commitment: commitment,
@@ -106,11 +172,13 @@ func (h *Hidden) ToOnChain() *OnChain {
 	// convert int64 timestamp to string
 	timestampStr := strconv.FormatInt(h.Timestamp, 10)
 	return &OnChain{
+		Version:    h.Version,
 		Sender:     hex.EncodeToString(h.Sender),
 		Receiver:   hex.EncodeToString(h.Receiver),
 		Commitment: hex.EncodeToString(h.Commitment),
 		Auxiliary:  hex.EncodeToString(h.Auxiliary),
 		Timestamp:  timestampStr,
+		Signature:  hex.EncodeToString(h.Signature),
 	}
 }
 
@@ -121,16 +189,20 @@ func (h *Hidden) Serialize() ([]byte, error) {
 
 // OnChain is the struct for on-chain transaction
 type OnChain struct {
+	// Version is the protocol version, absent in version 1 transactions
+	Version    int    `json:"Version,omitempty"`
 	Sender     string `json:"Sender"`
 	Receiver   string `json:"Receiver"`
 	Commitment string `json:"Commit"`
 	Auxiliary  string `json:"Aux"`
 	Timestamp  string `json:"Timestamp"`
+	Signature  string `json:"Sig,omitempty"`
 }
 
 // NewOnChain creates a new on-chain transaction
 func NewOnChain(sender, receiver, commitment, auxiliary, timestamp string) *OnChain {
 	return &OnChain{
+		Version:    protocol.CurrentVersion,
 		Sender:     sender,
 		Receiver:   receiver,
 		Commitment: commitment,
@@ -141,12 +213,15 @@ func NewOnChain(sender, receiver, commitment, auxiliary, timestamp string) *OnCh
 
 // ToHide converts an on-chain transaction to a hidden transaction
 func (o *OnChain) ToHide() (*Hidden, error) {
+	if _, err := protocol.Resolve(o.Version); err != nil {
+		return nil, err
+	}
 	// convert string timestamp to int64
 	timestampInt, err := strconv.ParseInt(o.Timestamp, 10, 64)
 	if err != nil {
 		return nil, err
 	}
-	hiddenTX := new(Hidden)
+	hiddenTX := &Hidden{Version: o.Version}
 	hiddenTX.Sender, err = hex.DecodeString(o.Sender)
 	if err != nil {
 		return nil, err
@@ -163,17 +238,26 @@ func (o *OnChain) ToHide() (*Hidden, error) {
 	if err != nil {
 		return nil, err
 	}
+	if o.Signature != "" {
+		hiddenTX.Signature, err = hex.DecodeString(o.Signature)
+		if err != nil {
+			return nil, err
+		}
+	}
 	hiddenTX.Timestamp = timestampInt
 	return hiddenTX, nil
 }
 
 // KeyVal composes the key value pair for the transaction to be stored on-chain
 func (o *OnChain) KeyVal() (string, []byte, error) {
+	return o.KeyValWith(hashsuite.Default())
+}
+
+// KeyValWith composes the key value pair for the transaction, hashing the key with the suite
+func (o *OnChain) KeyValWith(suite *hashsuite.Suite) (string, []byte, error) {
 	txJSON, err := json.Marshal(o)
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Hash := sha256.New()
-	sha256Hash.Write(txJSON)
-	return hex.EncodeToString(sha256Hash.Sum(nil)), txJSON, nil
+	return hex.EncodeToString(suite.Sum(hashsuite.Key, txJSON)), txJSON, nil
 }
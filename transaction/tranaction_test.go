@@ -3,10 +3,12 @@ package transaction
 import (
 	"crypto/rand"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
 	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/counter"
 )
 
 func TestPlain_HidePair(t *testing.T) {
@@ -78,6 +80,79 @@ func TestPlain_HidePair(t *testing.T) {
 	}
 }
 
+func TestPlain_HideNext(t *testing.T) {
+	g, h := paramSetup()
+	allocator, err := counter.NewAllocator(nil)
+	handleErr(err)
+	source := allocator.Source(counter.Key{OrgID: "sender", ChainID: "receiver"})
+	p := &Plain{
+		Sender:    "sender",
+		Receiver:  "receiver",
+		Amount:    100,
+		Timestamp: time.Now().UnixNano(),
+	}
+	got1, counter1, err := p.HideNext(source, g, h, false)
+	if err != nil {
+		t.Fatalf("HideNext() error = %v", err)
+	}
+	got2, counter2, err := p.HideNext(source, g, h, false)
+	if err != nil {
+		t.Fatalf("HideNext() error = %v", err)
+	}
+	if counter1 == counter2 {
+		t.Errorf("HideNext() reused counter %d", counter1)
+	}
+	if string(got1.Commitment) == string(got2.Commitment) {
+		t.Errorf("HideNext() repeated the commitment")
+	}
+	want, err := p.Hide(counter2, g, h, false)
+	handleErr(err)
+	if string(got2.Commitment) != string(want.Commitment) {
+		t.Errorf("HideNext() got = %v, want %v", got2.Commitment, want.Commitment)
+	}
+}
+
+func FuzzPlain_HidePair(f *testing.F) {
+	f.Add("sender", "receiver", int64(100), int64(100), uint64(100))
+	f.Add("", "", int64(0), int64(0), uint64(0))
+	f.Add("sender", "sender", int64(math.MaxInt64), int64(-1), uint64(math.MaxUint64))
+	f.Add("receiver", "sender", int64(math.MinInt64), int64(math.MinInt64), uint64(1))
+	g, h := paramSetup()
+	f.Fuzz(func(t *testing.T, sender, receiver string, amount, timestamp int64, counter uint64) {
+		p := &Plain{
+			Sender:    sender,
+			Receiver:  receiver,
+			Amount:    amount,
+			Timestamp: timestamp,
+		}
+		got1, got2, err := p.HidePair(counter, g, h)
+		if amount == math.MinInt64 {
+			if err == nil {
+				t.Errorf("HidePair() accepted amount %d", amount)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("HidePair() error = %v", err)
+		}
+		if string(got1.Sender) != string(got2.Receiver) || string(got1.Receiver) != string(got2.Sender) {
+			t.Errorf("HidePair() sender and receiver are not swapped")
+		}
+		point1, err := new(ed25519.Point).SetBytes(got1.Commitment)
+		if err != nil {
+			t.Fatalf("cannot convert to EC point, error = %v", err)
+		}
+		point2, err := new(ed25519.Point).SetBytes(got2.Commitment)
+		if err != nil {
+			t.Fatalf("cannot convert to EC point, error = %v", err)
+		}
+		point1.Add(point1, point2)
+		if point1.Equal(ed25519.NewIdentityPoint()) == 0 {
+			t.Errorf("HidePair() commitment not equal to zero")
+		}
+	})
+}
+
 func paramSetup() (g, h *ed25519.Point) {
 	randBytes := make([]byte, 64)
 	_, err := rand.Read(randBytes)
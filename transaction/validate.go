@@ -0,0 +1,137 @@
+package transaction
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/schnorr"
+)
+
+const (
+	// HashSize is the size of the sender and receiver name hashes of the default hash suite
+	HashSize = sha256.Size
+	// CommitmentSize is the size of an encoded commitment point
+	CommitmentSize = 32
+	// MaxAuxiliarySize is the maximum size of the auxiliary data
+	MaxAuxiliarySize = 4096
+	// MinTimestamp is the minimum timestamp in nanoseconds, the Unix epoch
+	MinTimestamp int64 = 0
+	// MaxTimestamp is the maximum timestamp in nanoseconds, 2200-01-01T00:00:00Z
+	MaxTimestamp int64 = 7258118400000000000
+)
+
+var (
+	// ErrInvalidEncoding is returned when an on-chain field is not valid hex or decimal
+	ErrInvalidEncoding = errors.New("invalid encoding")
+	// ErrInvalidLength is returned when a field has the wrong length
+	ErrInvalidLength = errors.New("invalid length")
+	// ErrInvalidPoint is returned when the commitment is not a valid curve point
+	ErrInvalidPoint = errors.New("invalid curve point")
+	// ErrNonCanonicalPoint is returned when the commitment is not the canonical encoding of its point
+	ErrNonCanonicalPoint = errors.New("non-canonical point encoding")
+	// ErrSmallOrderPoint is returned when the commitment is a point of small order
+	ErrSmallOrderPoint = errors.New("small-order point")
+	// ErrTimestampOutOfRange is returned when the timestamp is outside [MinTimestamp, MaxTimestamp]
+	ErrTimestampOutOfRange = errors.New("timestamp out of range")
+	// ErrAuxiliaryTooLarge is returned when the auxiliary data exceeds MaxAuxiliarySize
+	ErrAuxiliaryTooLarge = errors.New("auxiliary data too large")
+)
+
+// ValidationError is the error of a field that fails validation, Err is one of the Err* values above
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("transaction: invalid %s: %v", e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks the field lengths, the commitment point, the timestamp bounds and the auxiliary size,
+// with name hashes of the default hash suite
+func (h *Hidden) Validate() error {
+	return h.ValidateWith(hashsuite.Default())
+}
+
+// ValidateWith validates the transaction as Validate, with name hashes of the size of the hash suite
+func (h *Hidden) ValidateWith(suite *hashsuite.Suite) error {
+	hashSize := suite.Size()
+	if len(h.Sender) != hashSize {
+		return &ValidationError{Field: "sender", Err: ErrInvalidLength}
+	}
+	if len(h.Receiver) != hashSize {
+		return &ValidationError{Field: "receiver", Err: ErrInvalidLength}
+	}
+	if err := validatePoint(h.Commitment); err != nil {
+		return &ValidationError{Field: "commitment", Err: err}
+	}
+	if len(h.Auxiliary) > MaxAuxiliarySize {
+		return &ValidationError{Field: "auxiliary", Err: ErrAuxiliaryTooLarge}
+	}
+	if h.Timestamp < MinTimestamp || h.Timestamp > MaxTimestamp {
+		return &ValidationError{Field: "timestamp", Err: ErrTimestampOutOfRange}
+	}
+	if len(h.Signature) != 0 && len(h.Signature) != schnorr.SignatureSize {
+		return &ValidationError{Field: "signature", Err: ErrInvalidLength}
+	}
+	return nil
+}
+
+// Validate checks the encoding of the on-chain fields, and then validates the hidden transaction they decode to
+func (o *OnChain) Validate() error {
+	return o.ValidateWith(hashsuite.Default())
+}
+
+// ValidateWith validates the transaction as Validate, with name hashes of the size of the hash suite
+func (o *OnChain) ValidateWith(suite *hashsuite.Suite) error {
+	hiddenTX := new(Hidden)
+	fields := []struct {
+		name string
+		val  string
+		dst  *[]byte
+	}{
+		{"sender", o.Sender, &hiddenTX.Sender},
+		{"receiver", o.Receiver, &hiddenTX.Receiver},
+		{"commitment", o.Commitment, &hiddenTX.Commitment},
+		{"auxiliary", o.Auxiliary, &hiddenTX.Auxiliary},
+		{"signature", o.Signature, &hiddenTX.Signature},
+	}
+	var err error
+	for _, field := range fields {
+		if *field.dst, err = hex.DecodeString(field.val); err != nil {
+			return &ValidationError{Field: field.name, Err: ErrInvalidEncoding}
+		}
+	}
+	if hiddenTX.Timestamp, err = strconv.ParseInt(o.Timestamp, 10, 64); err != nil {
+		return &ValidationError{Field: "timestamp", Err: ErrInvalidEncoding}
+	}
+	return hiddenTX.ValidateWith(suite)
+}
+
+func validatePoint(b []byte) error {
+	if len(b) != CommitmentSize {
+		return ErrInvalidLength
+	}
+	point, err := new(ed25519.Point).SetBytes(b)
+	if err != nil {
+		return ErrInvalidPoint
+	}
+	// SetBytes accepts non-canonical encodings, e.g. y >= p
+	if !bytes.Equal(point.Bytes(), b) {
+		return ErrNonCanonicalPoint
+	}
+	if new(ed25519.Point).MultByCofactor(point).Equal(ed25519.NewIdentityPoint()) == 1 {
+		return ErrSmallOrderPoint
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/hashsuite"
+)
+
+func TestHidden_Validate(t *testing.T) {
+	g, h := paramSetup()
+	p := &Plain{
+		Sender:    "sender",
+		Receiver:  "receiver",
+		Amount:    100,
+		Timestamp: time.Now().UnixNano(),
+	}
+	valid, err := p.Hide(100, g, h, false)
+	handleErr(err)
+	// y = 2 is not the y-coordinate of any curve point
+	invalidPoint := make([]byte, CommitmentSize)
+	invalidPoint[0] = 2
+	// y = p + 1 is a non-canonical encoding of the identity
+	nonCanonical := make([]byte, CommitmentSize)
+	for i := range nonCanonical {
+		nonCanonical[i] = 0xff
+	}
+	nonCanonical[0] = 0xee
+	nonCanonical[31] = 0x7f
+	tests := []struct {
+		name      string
+		modify    func(h *Hidden)
+		wantField string
+		wantErr   error
+	}{
+		{
+			name:   "Test_Valid",
+			modify: func(h *Hidden) {},
+		},
+		{
+			name:      "Test_Short_Sender",
+			modify:    func(h *Hidden) { h.Sender = h.Sender[:5] },
+			wantField: "sender",
+			wantErr:   ErrInvalidLength,
+		},
+		{
+			name:      "Test_Empty_Receiver",
+			modify:    func(h *Hidden) { h.Receiver = nil },
+			wantField: "receiver",
+			wantErr:   ErrInvalidLength,
+		},
+		{
+			name:      "Test_Empty_Commitment",
+			modify:    func(h *Hidden) { h.Commitment = nil },
+			wantField: "commitment",
+			wantErr:   ErrInvalidLength,
+		},
+		{
+			name:      "Test_Invalid_Point",
+			modify:    func(h *Hidden) { h.Commitment = invalidPoint },
+			wantField: "commitment",
+			wantErr:   ErrInvalidPoint,
+		},
+		{
+			name:      "Test_Non_Canonical_Point",
+			modify:    func(h *Hidden) { h.Commitment = nonCanonical },
+			wantField: "commitment",
+			wantErr:   ErrNonCanonicalPoint,
+		},
+		{
+			name:      "Test_Identity_Point",
+			modify:    func(h *Hidden) { h.Commitment = ed25519.NewIdentityPoint().Bytes() },
+			wantField: "commitment",
+			wantErr:   ErrSmallOrderPoint,
+		},
+		{
+			name:      "Test_Negative_Timestamp",
+			modify:    func(h *Hidden) { h.Timestamp = -1 },
+			wantField: "timestamp",
+			wantErr:   ErrTimestampOutOfRange,
+		},
+		{
+			name:      "Test_Future_Timestamp",
+			modify:    func(h *Hidden) { h.Timestamp = MaxTimestamp + 1 },
+			wantField: "timestamp",
+			wantErr:   ErrTimestampOutOfRange,
+		},
+		{
+			name:      "Test_Large_Auxiliary",
+			modify:    func(h *Hidden) { h.Auxiliary = make([]byte, MaxAuxiliarySize+1) },
+			wantField: "auxiliary",
+			wantErr:   ErrAuxiliaryTooLarge,
+		},
+		{
+			name:      "Test_Short_Signature",
+			modify:    func(h *Hidden) { h.Signature = make([]byte, 63) },
+			wantField: "signature",
+			wantErr:   ErrInvalidLength,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hidden := *valid
+			tt.modify(&hidden)
+			err := hidden.Validate()
+			checkValidationError(t, err, tt.wantField, tt.wantErr)
+			// the on-chain form fails in the same way
+			err = hidden.ToOnChain().Validate()
+			checkValidationError(t, err, tt.wantField, tt.wantErr)
+		})
+	}
+}
+
+func TestOnChain_Validate(t *testing.T) {
+	g, h := paramSetup()
+	p := &Plain{
+		Sender:    "sender",
+		Receiver:  "receiver",
+		Amount:    100,
+		Timestamp: time.Now().UnixNano(),
+	}
+	valid, err := p.Hide(100, g, h, false)
+	handleErr(err)
+	tests := []struct {
+		name      string
+		modify    func(o *OnChain)
+		wantField string
+		wantErr   error
+	}{
+		{
+			name:   "Test_Valid",
+			modify: func(o *OnChain) {},
+		},
+		{
+			name:      "Test_Invalid_Hex",
+			modify:    func(o *OnChain) { o.Commitment = "zz" },
+			wantField: "commitment",
+			wantErr:   ErrInvalidEncoding,
+		},
+		{
+			name:      "Test_Invalid_Timestamp",
+			modify:    func(o *OnChain) { o.Timestamp = "yesterday" },
+			wantField: "timestamp",
+			wantErr:   ErrInvalidEncoding,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onChain := valid.ToOnChain()
+			tt.modify(onChain)
+			checkValidationError(t, onChain.Validate(), tt.wantField, tt.wantErr)
+		})
+	}
+}
+
+func TestHidden_ValidateWith(t *testing.T) {
+	g, h := paramSetup()
+	tests := []struct {
+		name  string
+		suite *hashsuite.Suite
+	}{
+		{"Test_Default", hashsuite.Default()},
+		{"Test_Sha512", hashsuite.SHA512},
+		{"Test_Sha3256", hashsuite.SHA3_256},
+		{"Test_Blake2b512", hashsuite.BLAKE2b_512},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlain("sender", "receiver", 100)
+			hidden, err := p.HideWith(100, &commitment.Params{G: g, H: h, Suite: tt.suite}, false)
+			if err != nil {
+				t.Fatalf("HideWith() error = %v", err)
+			}
+			checkValidationError(t, hidden.ValidateWith(tt.suite), "", nil)
+			checkValidationError(t, hidden.ToOnChain().ValidateWith(tt.suite), "", nil)
+			// the name hashes of another size are rejected
+			other := hashsuite.SHA512
+			if tt.suite.Size() == other.Size() {
+				other = hashsuite.Default()
+			}
+			checkValidationError(t, hidden.ValidateWith(other), "sender", ErrInvalidLength)
+		})
+	}
+}
+
+func checkValidationError(t *testing.T, err error, wantField string, wantErr error) {
+	t.Helper()
+	if wantErr == nil {
+		if err != nil {
+			t.Errorf("Validate() error = %v, want nil", err)
+		}
+		return
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+	}
+	if validationErr.Field != wantField || !errors.Is(err, wantErr) {
+		t.Errorf("Validate() error = %v, want field %s and %v", err, wantField, wantErr)
+	}
+}
@@ -0,0 +1,36 @@
+package transaction
+
+import (
+	"github.com/auti-project/auti-core/protocol"
+)
+
+// DecodeOnChain decodes an on-chain transaction of any supported protocol version. Versions 2 and 3 share the
+// layout, since version 3 only changed how the commitment encodes the amount.
+func DecodeOnChain(data []byte) (*OnChain, error) {
+	o := new(OnChain)
+	if _, err := protocol.Decode(data, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// ProtocolVersion returns the protocol version of the transaction
+func (o *OnChain) ProtocolVersion() (int, error) {
+	return protocol.Resolve(o.Version)
+}
+
+// Upgrade returns a copy of the transaction at the version it upgrades to. Its commitment keeps the amount
+// encoding it was made with, so a version 1 transaction becomes version 2, never version 3. The signature covers
+// the version, so it is dropped when the version changes, and the upgraded transaction must be signed again.
+func (o *OnChain) Upgrade() (*OnChain, error) {
+	field, changed, err := protocol.Upgrade(o.Version, true)
+	if err != nil {
+		return nil, err
+	}
+	upgraded := *o
+	if changed {
+		upgraded.Version = field
+		upgraded.Signature = ""
+	}
+	return &upgraded, nil
+}
@@ -0,0 +1,108 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/protocol"
+)
+
+func TestOnChain_Upgrade(t *testing.T) {
+	publicKey, privateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	g, h := paramSetup()
+	p := NewPlain("org1", "org2", 100)
+	hidden, err := p.HideSigned(0, g, h, false, privateKey)
+	if err != nil {
+		t.Fatalf("HideSigned() error = %v", err)
+	}
+	// a version 1 transaction, signed without its version
+	legacyHidden := *hidden
+	legacyHidden.Version = 0
+	if err = legacyHidden.Sign(privateKey); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	legacy := legacyHidden.ToOnChain()
+	_, legacyJSON, err := legacy.KeyVal()
+	if err != nil {
+		t.Fatalf("KeyVal() error = %v", err)
+	}
+	future := *hidden.ToOnChain()
+	future.Version = protocol.CurrentVersion + 1
+	futureJSON, err := json.Marshal(&future)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr error
+	}{
+		{"Test_Version_1", legacyJSON, protocol.Version1, nil},
+		{"Test_Future", futureJSON, 0, protocol.ErrUnsupportedVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeOnChain(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeOnChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, _ := decoded.ProtocolVersion(); got != tt.want {
+				t.Errorf("ProtocolVersion() got = %d, want %d", got, tt.want)
+			}
+			upgraded, err := decoded.Upgrade()
+			if err != nil {
+				t.Fatalf("Upgrade() error = %v", err)
+			}
+			// the commitment keeps its amount encoding, so the upgrade stops short of version 3
+			want := protocol.UpgradeVersion(tt.want, true)
+			if got, _ := upgraded.ProtocolVersion(); got != want {
+				t.Errorf("ProtocolVersion() of upgraded got = %d, want %d", got, want)
+			}
+			if _, err = decoded.ToHideVerified(publicKey); err != nil {
+				t.Errorf("ToHideVerified() error = %v", err)
+			}
+			// the signature of the version 1 transaction does not cover the new version, so the upgrade drops it
+			if _, err = upgraded.ToHideVerified(publicKey); !errors.Is(err, ErrUnsigned) {
+				t.Errorf("ToHideVerified() of upgraded error = %v, wantErr %v", err, ErrUnsigned)
+			}
+			resigned, err := upgraded.ToHide()
+			if err != nil {
+				t.Fatalf("ToHide() error = %v", err)
+			}
+			if err = resigned.Sign(privateKey); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if _, err = resigned.ToOnChain().ToHideVerified(publicKey); err != nil {
+				t.Errorf("ToHideVerified() of re-signed error = %v", err)
+			}
+		})
+	}
+	// the signature of a current version transaction covers its version
+	current, err := hidden.ToOnChain().Upgrade()
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if _, err = current.ToHideVerified(publicKey); err != nil {
+		t.Errorf("ToHideVerified() of a current version error = %v", err)
+	}
+	downgraded := hidden.ToOnChain()
+	downgraded.Version = 0
+	if _, err = downgraded.ToHideVerified(publicKey); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ToHideVerified() of a downgraded version error = %v, wantErr %v", err, ErrInvalidSignature)
+	}
+	if _, err = future.ToHide(); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+		t.Errorf("ToHide() of a future version error = %v, wantErr %v", err, protocol.ErrUnsupportedVersion)
+	}
+	if _, err = future.Upgrade(); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+		t.Errorf("Upgrade() of a future version error = %v, wantErr %v", err, protocol.ErrUnsupportedVersion)
+	}
+}
@@ -0,0 +1,143 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/wire"
+)
+
+// Field numbers of the binary encoding of transactions, the same in Hidden and OnChain
+const (
+	fieldVersion = iota + 1
+	fieldSender
+	fieldReceiver
+	fieldCommitment
+	fieldAuxiliary
+	fieldTimestamp
+	fieldSignature
+)
+
+// MarshalBinary encodes the hidden transaction in the binary wire format
+func (h *Hidden) MarshalBinary() ([]byte, error) {
+	buf := wire.AppendInt(nil, fieldVersion, int64(h.Version))
+	buf = wire.AppendBytes(buf, fieldSender, h.Sender)
+	buf = wire.AppendBytes(buf, fieldReceiver, h.Receiver)
+	buf = wire.AppendBytes(buf, fieldCommitment, h.Commitment)
+	buf = wire.AppendBytes(buf, fieldAuxiliary, h.Auxiliary)
+	buf = wire.AppendInt(buf, fieldTimestamp, h.Timestamp)
+	return wire.AppendBytes(buf, fieldSignature, h.Signature), nil
+}
+
+// UnmarshalBinary decodes a hidden transaction of MarshalBinary of a supported protocol version
+func (h *Hidden) UnmarshalBinary(data []byte) error {
+	*h = Hidden{}
+	d := wire.NewDecoder(data)
+	for {
+		f, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var v int64
+		switch f.Number {
+		case fieldVersion:
+			v, err = f.Int()
+			h.Version = int(v)
+		case fieldSender:
+			h.Sender, err = f.Bytes()
+		case fieldReceiver:
+			h.Receiver, err = f.Bytes()
+		case fieldCommitment:
+			h.Commitment, err = f.Bytes()
+		case fieldAuxiliary:
+			h.Auxiliary, err = f.Bytes()
+		case fieldTimestamp:
+			h.Timestamp, err = f.Int()
+		case fieldSignature:
+			h.Signature, err = f.Bytes()
+		default:
+			err = f.Unknown()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := protocol.Resolve(h.Version)
+	return err
+}
+
+// MarshalBinary encodes the on-chain transaction in the binary wire format, with the hex fields as bytes
+// and the timestamp as an integer. The fields must be as ToOnChain encodes them, so that they decode to themselves
+// and the key of KeyVal does not depend on the encoding.
+func (o *OnChain) MarshalBinary() ([]byte, error) {
+	timestamp, err := strconv.ParseInt(o.Timestamp, 10, 64)
+	var digits [20]byte
+	if err != nil || string(strconv.AppendInt(digits[:0], timestamp, 10)) != o.Timestamp {
+		return nil, fmt.Errorf("%w: timestamp %q", wire.ErrNonCanonical, o.Timestamp)
+	}
+	// the bytes of the hex fields, and room for the tags, the lengths and the integers
+	size := (len(o.Sender)+len(o.Receiver)+len(o.Commitment)+len(o.Auxiliary)+len(o.Signature))/2 + 64
+	buf := wire.AppendInt(make([]byte, 0, size), fieldVersion, int64(o.Version))
+	for _, field := range []struct {
+		number int
+		value  string
+	}{
+		{fieldSender, o.Sender},
+		{fieldReceiver, o.Receiver},
+		{fieldCommitment, o.Commitment},
+		{fieldAuxiliary, o.Auxiliary},
+	} {
+		if buf, err = wire.AppendHex(buf, field.number, field.value); err != nil {
+			return nil, err
+		}
+	}
+	buf = wire.AppendInt(buf, fieldTimestamp, timestamp)
+	return wire.AppendHex(buf, fieldSignature, o.Signature)
+}
+
+// UnmarshalBinary decodes an on-chain transaction of MarshalBinary of a supported protocol version
+func (o *OnChain) UnmarshalBinary(data []byte) error {
+	*o = OnChain{Timestamp: "0"}
+	d := wire.NewDecoder(data)
+	for {
+		f, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var v int64
+		switch f.Number {
+		case fieldVersion:
+			v, err = f.Int()
+			o.Version = int(v)
+		case fieldSender:
+			o.Sender, err = f.Hex()
+		case fieldReceiver:
+			o.Receiver, err = f.Hex()
+		case fieldCommitment:
+			o.Commitment, err = f.Hex()
+		case fieldAuxiliary:
+			o.Auxiliary, err = f.Hex()
+		case fieldTimestamp:
+			v, err = f.Int()
+			o.Timestamp = strconv.FormatInt(v, 10)
+		case fieldSignature:
+			o.Signature, err = f.Hex()
+		default:
+			err = f.Unknown()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := protocol.Resolve(o.Version)
+	return err
+}
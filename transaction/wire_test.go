@@ -0,0 +1,190 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/wire"
+)
+
+func testHidden(tb testing.TB) *Hidden {
+	tb.Helper()
+	g, h := paramSetup()
+	_, privateKey, err := autied25519.KeyGen()
+	if err != nil {
+		tb.Fatalf("KeyGen() error = %v", err)
+	}
+	p := NewPlain("org1", "org2", 1500)
+	p.Auxiliary = []byte("invoice 42")
+	p.Timestamp = 1672531200000000000
+	hidden, err := p.HideSigned(7, g, h, false, privateKey)
+	if err != nil {
+		tb.Fatalf("HideSigned() error = %v", err)
+	}
+	return hidden
+}
+
+func TestHidden_MarshalBinary(t *testing.T) {
+	hidden := testHidden(t)
+	negative := *hidden
+	negative.Timestamp = -1
+	tests := []struct {
+		name   string
+		hidden *Hidden
+	}{
+		{"Test_Signed", hidden},
+		{"Test_Negative_Timestamp", &negative},
+		{"Test_Empty", &Hidden{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.hidden.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+			got := new(Hidden)
+			if err = got.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			for i, field := range [][2][]byte{
+				{got.Sender, tt.hidden.Sender},
+				{got.Receiver, tt.hidden.Receiver},
+				{got.Commitment, tt.hidden.Commitment},
+				{got.Auxiliary, tt.hidden.Auxiliary},
+				{got.Signature, tt.hidden.Signature},
+			} {
+				if !bytes.Equal(field[0], field[1]) {
+					t.Errorf("UnmarshalBinary() field %d = %x, want %x", i, field[0], field[1])
+				}
+			}
+			if got.Timestamp != tt.hidden.Timestamp {
+				t.Errorf("UnmarshalBinary() timestamp = %d, want %d", got.Timestamp, tt.hidden.Timestamp)
+			}
+			if got.Version != tt.hidden.Version {
+				t.Errorf("UnmarshalBinary() version = %d, want %d", got.Version, tt.hidden.Version)
+			}
+		})
+	}
+}
+
+func TestOnChain_MarshalBinary(t *testing.T) {
+	tx := testHidden(t).ToOnChain()
+	legacy := *tx
+	legacy.Version = 0
+	upperCase := *tx
+	upperCase.Commitment = "AB" + tx.Commitment[2:]
+	paddedTimestamp := *tx
+	paddedTimestamp.Timestamp = "0" + tx.Timestamp
+	tests := []struct {
+		name    string
+		tx      *OnChain
+		wantErr error
+	}{
+		{"Test_Current", tx, nil},
+		{"Test_Version_1", &legacy, nil},
+		{"Test_Empty", NewOnChain("", "", "", "", "0"), nil},
+		{"Test_Upper_Case", &upperCase, wire.ErrNonCanonical},
+		{"Test_Padded_Timestamp", &paddedTimestamp, wire.ErrNonCanonical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.tx.MarshalBinary()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MarshalBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := new(OnChain)
+			if err = got.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.tx) {
+				t.Errorf("UnmarshalBinary() got = %v, want %v", got, tt.tx)
+			}
+			// the key does not depend on the encoding
+			gotKey, _, err := got.KeyVal()
+			if err != nil {
+				t.Fatalf("KeyVal() error = %v", err)
+			}
+			if wantKey, _, _ := tt.tx.KeyVal(); gotKey != wantKey {
+				t.Errorf("KeyVal() after UnmarshalBinary() = %s, want %s", gotKey, wantKey)
+			}
+		})
+	}
+
+	// a hidden transaction encodes as the on-chain transaction of the same version
+	hidden := testHidden(t)
+	hiddenData, err := hidden.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	got := new(OnChain)
+	if err = got.UnmarshalBinary(hiddenData); err != nil {
+		t.Fatalf("UnmarshalBinary() of a hidden transaction error = %v", err)
+	}
+	if want := hidden.ToOnChain(); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalBinary() of a hidden transaction got = %v, want %v", got, want)
+	}
+	future := append(wire.AppendInt(nil, fieldVersion, protocol.CurrentVersion+1), hiddenData[2:]...)
+	if err = new(Hidden).UnmarshalBinary(future); !errors.Is(err, protocol.ErrUnsupportedVersion) {
+		t.Errorf("UnmarshalBinary() of a future version error = %v, wantErr %v", err, protocol.ErrUnsupportedVersion)
+	}
+}
+
+func BenchmarkOnChain_MarshalBinary(b *testing.B) {
+	tx := testHidden(b).ToOnChain()
+	var data []byte
+	var err error
+	for i := 0; i < b.N; i++ {
+		if data, err = tx.MarshalBinary(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(data)), "bytes/record")
+}
+
+func BenchmarkOnChain_MarshalJSON(b *testing.B) {
+	tx := testHidden(b).ToOnChain()
+	var data []byte
+	var err error
+	for i := 0; i < b.N; i++ {
+		if data, err = json.Marshal(tx); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(data)), "bytes/record")
+}
+
+func BenchmarkOnChain_UnmarshalBinary(b *testing.B) {
+	data, err := testHidden(b).ToOnChain().MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = new(OnChain).UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(data)), "bytes/record")
+}
+
+func BenchmarkOnChain_UnmarshalJSON(b *testing.B) {
+	data, err := json.Marshal(testHidden(b).ToOnChain())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = json.Unmarshal(data, new(OnChain)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(data)), "bytes/record")
+}
@@ -0,0 +1,354 @@
+package txio
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auti-project/auti-core/protocol"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// Schema maps the columns of a CSV file to the fields of the records, by the names in the header row.
+// Plaintext transactions have the sender, receiver, amount, auxiliary and timestamp columns;
+// hidden and on-chain transactions have the version, sender, receiver, commitment, auxiliary, timestamp and
+// signature columns. The auxiliary, signature and version columns may be absent from a file. Without the
+// version column, hidden and on-chain transactions are of protocol version 1.
+type Schema struct {
+	Sender     string
+	Receiver   string
+	Amount     string
+	Commitment string
+	Auxiliary  string
+	Timestamp  string
+	Signature  string
+	Version    string
+	// AuxiliaryText has the auxiliary data as text instead of hex
+	AuxiliaryText bool
+	// TimestampLayout has the timestamps as times of the layout, such as time.RFC3339Nano,
+	// instead of Unix nanoseconds
+	TimestampLayout string
+}
+
+// DefaultSchema returns the schema of the lower-case field names, with hex auxiliary data
+// and timestamps in Unix nanoseconds
+func DefaultSchema() Schema {
+	return Schema{
+		Sender:     "sender",
+		Receiver:   "receiver",
+		Amount:     "amount",
+		Commitment: "commitment",
+		Auxiliary:  "auxiliary",
+		Timestamp:  "timestamp",
+		Signature:  "signature",
+		Version:    "version",
+	}
+}
+
+// column is a column of the records of a type
+type column struct {
+	name     string
+	optional bool
+}
+
+func columns[T Record](s *Schema) []column {
+	switch any(new(T)).(type) {
+	case *transaction.Plain:
+		return []column{
+			{name: s.Sender}, {name: s.Receiver}, {name: s.Amount},
+			{name: s.Auxiliary, optional: true}, {name: s.Timestamp},
+		}
+	default:
+		return []column{
+			{name: s.Version, optional: true}, {name: s.Sender}, {name: s.Receiver}, {name: s.Commitment},
+			{name: s.Auxiliary, optional: true}, {name: s.Timestamp}, {name: s.Signature, optional: true},
+		}
+	}
+}
+
+// row is a row of a CSV file, by the column names of its header
+type row struct {
+	index  map[string]int
+	fields []string
+}
+
+// text returns the field of a column as it is
+func (r *row) text(column string) string {
+	if i, ok := r.index[column]; ok {
+		return r.fields[i]
+	}
+	return ""
+}
+
+// get returns the field of a numeric, hex or timestamp column, without surrounding whitespace
+func (r *row) get(column string) string {
+	return strings.TrimSpace(r.text(column))
+}
+
+// name returns the field of a name column, which must not have surrounding whitespace,
+// since the pseudonym of a name hashes its exact bytes
+func (r *row) name(column string) (string, error) {
+	s := r.text(column)
+	if strings.TrimSpace(s) != s {
+		return "", fmt.Errorf("column %q: whitespace around name %q", column, s)
+	}
+	return s, nil
+}
+
+// CSVReader reads records from a CSV file with a header row
+type CSVReader[T Record] struct {
+	reader *csv.Reader
+	schema Schema
+	index  map[string]int
+	line   int
+}
+
+// NewCSVReader creates a new CSV reader of the schema. Whitespace around the numeric, hex and timestamp fields
+// is ignored, names with whitespace around them are rejected, and text auxiliary data is kept as it is.
+func NewCSVReader[T Record](r io.Reader, schema Schema) *CSVReader[T] {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.ReuseRecord = true
+	return &CSVReader[T]{reader: reader, schema: schema}
+}
+
+// Read returns the next record, or io.EOF after the last record
+func (r *CSVReader[T]) Read() (*T, error) {
+	if r.index == nil {
+		if err := r.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+	fields, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, r.lineError(err)
+	}
+	r.line, _ = r.reader.FieldPos(0)
+	record, err := r.parse(&row{index: r.index, fields: fields})
+	if err != nil {
+		return nil, &LineError{Line: r.line, Err: err}
+	}
+	return record, nil
+}
+
+// Line returns the line of the last record read
+func (r *CSVReader[T]) Line() int {
+	return r.line
+}
+
+func (r *CSVReader[T]) readHeader() error {
+	header, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &LineError{Line: 1, Err: errors.New("missing header row")}
+	}
+	if err != nil {
+		return r.lineError(err)
+	}
+	r.line, _ = r.reader.FieldPos(0)
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	for _, c := range columns[T](&r.schema) {
+		if _, ok := index[c.name]; !ok && !c.optional {
+			return &LineError{Line: r.line, Err: fmt.Errorf("missing column %q", c.name)}
+		}
+	}
+	r.index = index
+	return nil
+}
+
+// lineError returns the error of the CSV parser, which carries its own line
+func (r *CSVReader[T]) lineError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &LineError{Line: parseErr.Line, Err: parseErr.Err}
+	}
+	return &LineError{Line: r.line + 1, Err: err}
+}
+
+func (r *CSVReader[T]) parse(fields *row) (*T, error) {
+	record := new(T)
+	timestamp, err := r.parseTimestamp(fields.get(r.schema.Timestamp))
+	if err != nil {
+		return nil, err
+	}
+	auxiliary := fields.get(r.schema.Auxiliary)
+	if r.schema.AuxiliaryText {
+		auxiliary = fields.text(r.schema.Auxiliary)
+	}
+	switch v := any(record).(type) {
+	case *transaction.Plain:
+		amount, err := strconv.ParseInt(fields.get(r.schema.Amount), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", r.schema.Amount, err)
+		}
+		sender, err := fields.name(r.schema.Sender)
+		if err != nil {
+			return nil, err
+		}
+		receiver, err := fields.name(r.schema.Receiver)
+		if err != nil {
+			return nil, err
+		}
+		*v = transaction.Plain{
+			Sender:    sender,
+			Receiver:  receiver,
+			Amount:    amount,
+			Timestamp: timestamp,
+		}
+		if r.schema.AuxiliaryText {
+			v.Auxiliary = []byte(auxiliary)
+		} else if v.Auxiliary, err = hex.DecodeString(auxiliary); err != nil {
+			return nil, fmt.Errorf("column %q: %w", r.schema.Auxiliary, err)
+		}
+		if len(v.Auxiliary) == 0 {
+			v.Auxiliary = nil
+		}
+	default:
+		if r.schema.AuxiliaryText {
+			auxiliary = hex.EncodeToString([]byte(auxiliary))
+		}
+		tx := &transaction.OnChain{
+			Sender:     fields.get(r.schema.Sender),
+			Receiver:   fields.get(r.schema.Receiver),
+			Commitment: fields.get(r.schema.Commitment),
+			Auxiliary:  auxiliary,
+			Timestamp:  strconv.FormatInt(timestamp, 10),
+			Signature:  fields.get(r.schema.Signature),
+		}
+		if version := fields.get(r.schema.Version); version != "" {
+			if tx.Version, err = strconv.Atoi(version); err != nil {
+				return nil, fmt.Errorf("column %q: %w", r.schema.Version, err)
+			}
+		}
+		if _, err = protocol.Resolve(tx.Version); err != nil {
+			return nil, err
+		}
+		// ToHide checks the hex fields of both types
+		hidden, err := tx.ToHide()
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case *transaction.Hidden:
+			*v = *hidden
+		case *transaction.OnChain:
+			*v = *tx
+		}
+	}
+	return record, nil
+}
+
+func (r *CSVReader[T]) parseTimestamp(s string) (int64, error) {
+	if r.schema.TimestampLayout == "" {
+		timestamp, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("column %q: %w", r.schema.Timestamp, err)
+		}
+		return timestamp, nil
+	}
+	t, err := time.Parse(r.schema.TimestampLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("column %q: %w", r.schema.Timestamp, err)
+	}
+	return t.UnixNano(), nil
+}
+
+// CSVWriter writes records to a CSV file with a header row
+type CSVWriter[T Record] struct {
+	writer  *csv.Writer
+	schema  Schema
+	columns []column
+	header  bool
+}
+
+// NewCSVWriter creates a new CSV writer of the schema, which must be flushed after the last record.
+// It writes every column of the records, including the optional ones.
+func NewCSVWriter[T Record](w io.Writer, schema Schema) *CSVWriter[T] {
+	return &CSVWriter[T]{writer: csv.NewWriter(w), schema: schema, columns: columns[T](&schema)}
+}
+
+// Write writes the record as a row, after the header row for the first record
+func (w *CSVWriter[T]) Write(record *T) error {
+	if !w.header {
+		header := make([]string, len(w.columns))
+		for i, c := range w.columns {
+			header[i] = c.name
+		}
+		if err := w.writer.Write(header); err != nil {
+			return err
+		}
+		w.header = true
+	}
+	values := make(map[string]string, len(w.columns))
+	switch r := any(record).(type) {
+	case *transaction.Plain:
+		values[w.schema.Sender] = r.Sender
+		values[w.schema.Receiver] = r.Receiver
+		values[w.schema.Amount] = strconv.FormatInt(r.Amount, 10)
+		values[w.schema.Auxiliary] = w.formatAuxiliary(r.Auxiliary)
+		values[w.schema.Timestamp] = w.formatTimestamp(r.Timestamp)
+	case *transaction.Hidden:
+		if r.Version != 0 {
+			values[w.schema.Version] = strconv.Itoa(r.Version)
+		}
+		values[w.schema.Sender] = hex.EncodeToString(r.Sender)
+		values[w.schema.Receiver] = hex.EncodeToString(r.Receiver)
+		values[w.schema.Commitment] = hex.EncodeToString(r.Commitment)
+		values[w.schema.Auxiliary] = w.formatAuxiliary(r.Auxiliary)
+		values[w.schema.Timestamp] = w.formatTimestamp(r.Timestamp)
+		values[w.schema.Signature] = hex.EncodeToString(r.Signature)
+	case *transaction.OnChain:
+		auxiliary, err := hex.DecodeString(r.Auxiliary)
+		if err != nil {
+			return err
+		}
+		timestamp, err := strconv.ParseInt(r.Timestamp, 10, 64)
+		if err != nil {
+			return err
+		}
+		if r.Version != 0 {
+			values[w.schema.Version] = strconv.Itoa(r.Version)
+		}
+		values[w.schema.Sender] = r.Sender
+		values[w.schema.Receiver] = r.Receiver
+		values[w.schema.Commitment] = r.Commitment
+		values[w.schema.Auxiliary] = w.formatAuxiliary(auxiliary)
+		values[w.schema.Timestamp] = w.formatTimestamp(timestamp)
+		values[w.schema.Signature] = r.Signature
+	}
+	fields := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		fields[i] = values[c.name]
+	}
+	return w.writer.Write(fields)
+}
+
+func (w *CSVWriter[T]) formatAuxiliary(auxiliary []byte) string {
+	if w.schema.AuxiliaryText {
+		return string(auxiliary)
+	}
+	return hex.EncodeToString(auxiliary)
+}
+
+func (w *CSVWriter[T]) formatTimestamp(timestamp int64) string {
+	if w.schema.TimestampLayout == "" {
+		return strconv.FormatInt(timestamp, 10)
+	}
+	return time.Unix(0, timestamp).UTC().Format(w.schema.TimestampLayout)
+}
+
+// Flush writes any buffered records to the underlying writer
+func (w *CSVWriter[T]) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
@@ -0,0 +1,141 @@
+package txio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// maxLineSize is the maximum size of a line of JSON Lines, well above the largest transaction
+const maxLineSize = 1 << 20
+
+// plainJSON is the JSON form of a plaintext transaction, with the auxiliary data in hex as in OnChain
+type plainJSON struct {
+	Sender    string `json:"Sender"`
+	Receiver  string `json:"Receiver"`
+	Amount    int64  `json:"Amount"`
+	Auxiliary string `json:"Aux,omitempty"`
+	Timestamp int64  `json:"Timestamp"`
+}
+
+// JSONLReader reads records from JSON Lines, one JSON object per line. Hidden transactions are in the JSON of
+// their on-chain transactions. Blank lines are skipped.
+type JSONLReader[T Record] struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewJSONLReader creates a new JSON Lines reader
+func NewJSONLReader[T Record](r io.Reader) *JSONLReader[T] {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	return &JSONLReader[T]{scanner: scanner}
+}
+
+// Read returns the next record, or io.EOF after the last record
+func (r *JSONLReader[T]) Read() (*T, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record, err := decodeJSON[T](line)
+		if err != nil {
+			return nil, &LineError{Line: r.line, Err: err}
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, &LineError{Line: r.line + 1, Err: err}
+	}
+	return nil, io.EOF
+}
+
+// Line returns the line of the last record read
+func (r *JSONLReader[T]) Line() int {
+	return r.line
+}
+
+func decodeJSON[T Record](data []byte) (*T, error) {
+	record := new(T)
+	switch v := any(record).(type) {
+	case *transaction.Plain:
+		var p plainJSON
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		auxiliary, err := hex.DecodeString(p.Auxiliary)
+		if err != nil {
+			return nil, err
+		}
+		*v = transaction.Plain{Sender: p.Sender, Receiver: p.Receiver, Amount: p.Amount, Timestamp: p.Timestamp}
+		if len(auxiliary) > 0 {
+			v.Auxiliary = auxiliary
+		}
+	case *transaction.Hidden:
+		tx, err := transaction.DecodeOnChain(data)
+		if err != nil {
+			return nil, err
+		}
+		hidden, err := tx.ToHide()
+		if err != nil {
+			return nil, err
+		}
+		*v = *hidden
+	case *transaction.OnChain:
+		tx, err := transaction.DecodeOnChain(data)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = tx.ProtocolVersion(); err != nil {
+			return nil, err
+		}
+		*v = *tx
+	}
+	return record, nil
+}
+
+// JSONLWriter writes records as JSON Lines
+type JSONLWriter[T Record] struct {
+	w *bufio.Writer
+}
+
+// NewJSONLWriter creates a new JSON Lines writer, which must be flushed after the last record
+func NewJSONLWriter[T Record](w io.Writer) *JSONLWriter[T] {
+	return &JSONLWriter[T]{w: bufio.NewWriter(w)}
+}
+
+// Write writes the record as a line
+func (w *JSONLWriter[T]) Write(record *T) error {
+	var v any
+	switch r := any(record).(type) {
+	case *transaction.Plain:
+		v = plainJSON{
+			Sender:    r.Sender,
+			Receiver:  r.Receiver,
+			Amount:    r.Amount,
+			Auxiliary: hex.EncodeToString(r.Auxiliary),
+			Timestamp: r.Timestamp,
+		}
+	case *transaction.Hidden:
+		v = r.ToOnChain()
+	case *transaction.OnChain:
+		v = r
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(data, '\n'))
+	return err
+}
+
+// Flush writes any buffered records to the underlying writer
+func (w *JSONLWriter[T]) Flush() error {
+	return w.w.Flush()
+}
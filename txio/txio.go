@@ -0,0 +1,111 @@
+package txio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+// Record is a transaction that can be read and written
+type Record interface {
+	transaction.Plain | transaction.Hidden | transaction.OnChain
+}
+
+// LineError is the error of a line of a file, counted from 1
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("txio: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads records one at a time, such as a JSONLReader or a CSVReader
+type Reader[T Record] interface {
+	// Read returns the next record, or io.EOF after the last record
+	Read() (*T, error)
+	// Line returns the line of the last record read
+	Line() int
+}
+
+// Writer writes records one at a time, such as a JSONLWriter or a CSVWriter
+type Writer[T Record] interface {
+	Write(record *T) error
+	Flush() error
+}
+
+// Hide hides the plaintext transactions of the reader with counters drawn from the source,
+// and writes them as on-chain transactions, one at a time. It returns the number of transactions written.
+func Hide(r Reader[transaction.Plain], w Writer[transaction.OnChain], source transaction.CounterSource,
+	params *commitment.Params) (int, error) {
+	n := 0
+	for {
+		plain, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return n, w.Flush()
+		}
+		if err != nil {
+			return n, err
+		}
+		counter, err := source.Next()
+		if err != nil {
+			return n, &LineError{Line: r.Line(), Err: err}
+		}
+		hidden, err := plain.HideWith(counter, params, false)
+		if err != nil {
+			return n, &LineError{Line: r.Line(), Err: err}
+		}
+		if err = w.Write(hidden.ToOnChain()); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// Verify validates the on-chain transactions of the reader one at a time, and verifies their signatures
+// against the keys of their senders unless keys is nil. It returns the number of transactions verified.
+// The transactions are of the default hash suite.
+func Verify(r Reader[transaction.OnChain], keys transaction.KeyResolver) (int, error) {
+	return VerifyWith(hashsuite.Default(), r, keys)
+}
+
+// VerifyWith verifies the on-chain transactions of the reader as Verify, of transactions of the hash suite
+func VerifyWith(suite *hashsuite.Suite, r Reader[transaction.OnChain], keys transaction.KeyResolver) (int, error) {
+	n := 0
+	for {
+		tx, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err = verify(suite, tx, keys); err != nil {
+			return n, &LineError{Line: r.Line(), Err: err}
+		}
+		n++
+	}
+}
+
+func verify(suite *hashsuite.Suite, tx *transaction.OnChain, keys transaction.KeyResolver) error {
+	hidden, err := tx.ToHide()
+	if err != nil {
+		return err
+	}
+	if err = hidden.ValidateWith(suite); err != nil {
+		return err
+	}
+	if keys == nil {
+		return nil
+	}
+	return hidden.VerifyWith(keys)
+}
@@ -0,0 +1,297 @@
+package txio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ed25519 "filippo.io/edwards25519"
+	"github.com/auti-project/auti-core/commitment"
+	"github.com/auti-project/auti-core/counter"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+	"github.com/auti-project/auti-core/hashsuite"
+	"github.com/auti-project/auti-core/transaction"
+)
+
+const testTimestamp = 1672531200000000000
+
+// generators returns the generators of the commitments, of which the discrete log of h with respect to g is unknown
+func generators(t *testing.T) (g, h *ed25519.Point) {
+	t.Helper()
+	g, err := autied25519.HashToPoint([]byte("auti-core txio test g"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	h, err = autied25519.HashToPoint([]byte("auti-core txio test h"))
+	if err != nil {
+		t.Fatalf("HashToPoint() error = %v", err)
+	}
+	return g, h
+}
+
+func testPlains() []*transaction.Plain {
+	plains := []*transaction.Plain{
+		transaction.NewPlain("org1", "org2", 1500),
+		transaction.NewPlain("org2", "org1", -42),
+		transaction.NewPlain("org1, inc.", "org \"3\"", 0),
+	}
+	for i, p := range plains {
+		p.Timestamp = testTimestamp + int64(i)
+	}
+	plains[1].Auxiliary = []byte("invoice 7")
+	return plains
+}
+
+func testParams(t *testing.T) *commitment.Params {
+	t.Helper()
+	g, h := generators(t)
+	return commitment.NewParams(g, h)
+}
+
+func testOnChains(t *testing.T) []*transaction.OnChain {
+	t.Helper()
+	params := testParams(t)
+	var txs []*transaction.OnChain
+	for i, p := range testPlains() {
+		hidden, err := p.HideWith(uint64(i), params, false)
+		if err != nil {
+			t.Fatalf("HideWith() error = %v", err)
+		}
+		txs = append(txs, hidden.ToOnChain())
+	}
+	// a transaction of protocol version 1
+	txs[2].Version = 0
+	return txs
+}
+
+func readAll[T Record](t *testing.T, r Reader[T]) []*T {
+	t.Helper()
+	var records []*T
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+func writeAll[T Record](t *testing.T, w Writer[T], records []*T) {
+	t.Helper()
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	plains := testPlains()
+	txs := testOnChains(t)
+	hiddens := make([]*transaction.Hidden, len(txs))
+	for i, tx := range txs {
+		var err error
+		if hiddens[i], err = tx.ToHide(); err != nil {
+			t.Fatalf("ToHide() error = %v", err)
+		}
+	}
+	custom := Schema{
+		Sender:          "From",
+		Receiver:        "To",
+		Amount:          "Value",
+		Commitment:      "C",
+		Auxiliary:       "Memo",
+		Timestamp:       "Time",
+		Signature:       "Sig",
+		Version:         "V",
+		AuxiliaryText:   true,
+		TimestampLayout: time.RFC3339Nano,
+	}
+	for _, schema := range []Schema{DefaultSchema(), custom} {
+		var buf bytes.Buffer
+		writeAll[transaction.Plain](t, NewCSVWriter[transaction.Plain](&buf, schema), plains)
+		gotPlains := readAll[transaction.Plain](t, NewCSVReader[transaction.Plain](&buf, schema))
+		if !reflect.DeepEqual(gotPlains, plains) {
+			t.Errorf("CSV Plain got = %v, want %v", gotPlains, plains)
+		}
+		writeAll[transaction.Hidden](t, NewCSVWriter[transaction.Hidden](&buf, schema), hiddens)
+		gotHiddens := readAll[transaction.Hidden](t, NewCSVReader[transaction.Hidden](&buf, schema))
+		if !reflect.DeepEqual(gotHiddens, hiddens) {
+			t.Errorf("CSV Hidden got = %v, want %v", gotHiddens, hiddens)
+		}
+		writeAll[transaction.OnChain](t, NewCSVWriter[transaction.OnChain](&buf, schema), txs)
+		gotTxs := readAll[transaction.OnChain](t, NewCSVReader[transaction.OnChain](&buf, schema))
+		if !reflect.DeepEqual(gotTxs, txs) {
+			t.Errorf("CSV OnChain got = %v, want %v", gotTxs, txs)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeAll[transaction.Plain](t, NewJSONLWriter[transaction.Plain](&buf), plains)
+	if got := readAll[transaction.Plain](t, NewJSONLReader[transaction.Plain](&buf)); !reflect.DeepEqual(got, plains) {
+		t.Errorf("JSONL Plain got = %v, want %v", got, plains)
+	}
+	writeAll[transaction.Hidden](t, NewJSONLWriter[transaction.Hidden](&buf), hiddens)
+	if got := readAll[transaction.Hidden](t, NewJSONLReader[transaction.Hidden](&buf)); !reflect.DeepEqual(got, hiddens) {
+		t.Errorf("JSONL Hidden got = %v, want %v", got, hiddens)
+	}
+	writeAll[transaction.OnChain](t, NewJSONLWriter[transaction.OnChain](&buf), txs)
+	got := readAll[transaction.OnChain](t, NewJSONLReader[transaction.OnChain](&buf))
+	if !reflect.DeepEqual(got, txs) {
+		t.Errorf("JSONL OnChain got = %v, want %v", got, txs)
+	}
+	for i := range got {
+		gotKey, _, _ := got[i].KeyVal()
+		wantKey, _, _ := txs[i].KeyVal()
+		if gotKey != wantKey {
+			t.Errorf("KeyVal() of transaction %d = %s, want %s", i, gotKey, wantKey)
+		}
+	}
+}
+
+func TestReader_LineError(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   Reader[transaction.Plain]
+		wantLine int
+	}{
+		{"Test_Jsonl", NewJSONLReader[transaction.Plain](strings.NewReader(
+			`{"Sender":"a","Receiver":"b","Amount":1,"Timestamp":1}` + "\n\n" + `{"Sender":"a","Amount":"x"}`)), 3},
+		{"Test_Jsonl_Hex", NewJSONLReader[transaction.Plain](strings.NewReader(
+			`{"Sender":"a","Receiver":"b","Amount":1,"Aux":"zz","Timestamp":1}`)), 1},
+		{"Test_Csv_Amount", NewCSVReader[transaction.Plain](strings.NewReader(
+			"sender,receiver,amount,timestamp\n# comment\na,b,1,1\na,b,x,1\n"), DefaultSchema()), 4},
+		{"Test_Csv_Field_Count", NewCSVReader[transaction.Plain](strings.NewReader(
+			"sender,receiver,amount,timestamp\na,b,1,1\na,b,1\n"), DefaultSchema()), 3},
+		{"Test_Csv_Missing_Column", NewCSVReader[transaction.Plain](strings.NewReader(
+			"sender,receiver,timestamp\na,b,1\n"), DefaultSchema()), 1},
+		{"Test_Csv_Timestamp_Layout", NewCSVReader[transaction.Plain](strings.NewReader(
+			"sender,receiver,amount,timestamp\na,b,1,1\n"), Schema{
+			Sender: "sender", Receiver: "receiver", Amount: "amount", Timestamp: "timestamp",
+			TimestampLayout: time.RFC3339,
+		}), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lineErr *LineError
+			for {
+				_, err := tt.reader.Read()
+				if errors.Is(err, io.EOF) {
+					t.Fatalf("Read() error = EOF, want a line error")
+				}
+				if err != nil {
+					if !errors.As(err, &lineErr) {
+						t.Fatalf("Read() error = %v, want a line error", err)
+					}
+					break
+				}
+			}
+			if lineErr.Line != tt.wantLine {
+				t.Errorf("Read() error = %v, want line %d", lineErr, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestCSVReader_Whitespace(t *testing.T) {
+	textSchema := DefaultSchema()
+	textSchema.AuxiliaryText = true
+	tests := []struct {
+		name    string
+		schema  Schema
+		row     string
+		want    *transaction.Plain
+		wantErr bool
+	}{
+		{"Test_Padded_Numbers", DefaultSchema(), "a,b, 1500 , 0a0b ,\t7 ",
+			&transaction.Plain{Sender: "a", Receiver: "b", Amount: 1500, Auxiliary: []byte{10, 11}, Timestamp: 7}, false},
+		{"Test_Padded_Text_Auxiliary", textSchema, "a,b,1, memo ,1",
+			&transaction.Plain{Sender: "a", Receiver: "b", Amount: 1, Auxiliary: []byte(" memo "), Timestamp: 1}, false},
+		{"Test_Leading_Space_Sender", DefaultSchema(), " a,b,1,,1", nil, true},
+		{"Test_Trailing_Space_Receiver", DefaultSchema(), "a,b ,1,,1", nil, true},
+		{"Test_Quoted_Padded_Sender", DefaultSchema(), `"a ",b,1,,1`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewCSVReader[transaction.Plain](strings.NewReader("sender,receiver,amount,auxiliary,timestamp\n"+tt.row), tt.schema)
+			got, err := r.Read()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHide(t *testing.T) {
+	params := testParams(t)
+	var plainBuf bytes.Buffer
+	writeAll[transaction.Plain](t, NewCSVWriter[transaction.Plain](&plainBuf, DefaultSchema()), testPlains())
+	allocator, err := counter.NewAllocator(nil)
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+	source := allocator.Source(counter.Key{OrgID: "org1", ChainID: "file"})
+	var txBuf bytes.Buffer
+	n, err := Hide(NewCSVReader[transaction.Plain](&plainBuf, DefaultSchema()),
+		NewJSONLWriter[transaction.OnChain](&txBuf), source, params)
+	if err != nil || n != len(testPlains()) {
+		t.Fatalf("Hide() = %d, %v, want %d", n, err, len(testPlains()))
+	}
+	hidden := txBuf.String()
+	if n, err = Verify(NewJSONLReader[transaction.OnChain](strings.NewReader(hidden)), nil); err != nil || n != 3 {
+		t.Errorf("Verify() = %d, %v, want 3", n, err)
+	}
+
+	// a commitment that is not a canonical point encoding on the second line
+	lines := strings.SplitAfter(hidden, "\n")
+	tx, err := transaction.DecodeOnChain([]byte(lines[1]))
+	if err != nil {
+		t.Fatalf("DecodeOnChain() error = %v", err)
+	}
+	tx.Commitment = strings.Repeat("ff", 32)
+	var tampered bytes.Buffer
+	writeAll[transaction.OnChain](t, NewJSONLWriter[transaction.OnChain](&tampered), []*transaction.OnChain{tx})
+	lines[1] = tampered.String()
+	n, err = Verify(NewJSONLReader[transaction.OnChain](strings.NewReader(strings.Join(lines, ""))), nil)
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || n != 1 || !errors.Is(err, transaction.ErrNonCanonicalPoint) {
+		t.Errorf("Verify() of a tampered file = %d, %v, want 1 and a non-canonical point on line 2", n, err)
+	}
+}
+
+func TestVerifyWith_Suite(t *testing.T) {
+	params := testParams(t)
+	params.Suite = hashsuite.SHA3_512
+	var plainBuf bytes.Buffer
+	writeAll[transaction.Plain](t, NewJSONLWriter[transaction.Plain](&plainBuf), testPlains())
+	allocator, err := counter.NewAllocator(nil)
+	if err != nil {
+		t.Fatalf("NewAllocator() error = %v", err)
+	}
+	var txBuf bytes.Buffer
+	if _, err = Hide(NewJSONLReader[transaction.Plain](&plainBuf), NewJSONLWriter[transaction.OnChain](&txBuf),
+		allocator.Source(counter.Key{OrgID: "org1", ChainID: "file"}), params); err != nil {
+		t.Fatalf("Hide() error = %v", err)
+	}
+	hidden := txBuf.String()
+	n, err := VerifyWith(params.Suite, NewJSONLReader[transaction.OnChain](strings.NewReader(hidden)), nil)
+	if err != nil || n != len(testPlains()) {
+		t.Errorf("VerifyWith() = %d, %v, want %d", n, err, len(testPlains()))
+	}
+	if _, err = Verify(NewJSONLReader[transaction.OnChain](strings.NewReader(hidden)), nil); !errors.Is(err, transaction.ErrInvalidLength) {
+		t.Errorf("Verify() error = %v, wantErr %v", err, transaction.ErrInvalidLength)
+	}
+}
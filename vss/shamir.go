@@ -0,0 +1,169 @@
+package vss
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+// shareFormatVersion is the version of the serialized share format
+const shareFormatVersion = 1
+
+// Split splits a secret, such as a private key of ed25519.KeyGen, into n shares, any threshold of which recover it.
+// The shares are returned with the Feldman commitments to verify them, the first commitment is secret * B.
+func Split(secret *ed25519.Scalar, threshold, n int) ([]*VerifiableShare, error) {
+	if threshold < 1 || threshold > n {
+		return nil, fmt.Errorf("vss: threshold %d out of range [1, %d]", threshold, n)
+	}
+	if uint64(n) > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("vss: %d shares exceed the share indices", n)
+	}
+	p, err := NewPolynomial(secret, threshold)
+	if err != nil {
+		return nil, err
+	}
+	commitments := p.Commitments()
+	shares := make([]*VerifiableShare, n)
+	for i := range shares {
+		share, err := p.Share(uint32(i + 1))
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = &VerifiableShare{Share: share, Commitments: commitments}
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from at least threshold shares, verifying every share
+// against the commitments and the recovered secret against the first commitment
+func Combine(shares []*VerifiableShare) (*ed25519.Scalar, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("vss: no shares")
+	}
+	commitments := shares[0].Commitments
+	if len(shares) < len(commitments) {
+		return nil, fmt.Errorf("vss: got %d shares, want at least %d", len(shares), len(commitments))
+	}
+	indices := make([]uint32, len(shares))
+	for i, share := range shares {
+		if !equalCommitments(share.Commitments, commitments) {
+			return nil, fmt.Errorf("vss: share %d belongs to another secret", share.Share.Index)
+		}
+		if err := share.Verify(); err != nil {
+			return nil, fmt.Errorf("%w: share %d", err, share.Share.Index)
+		}
+		indices[i] = share.Share.Index
+	}
+	secret := ed25519.NewScalar()
+	for _, share := range shares {
+		lambda, err := LagrangeCoefficient(share.Share.Index, indices)
+		if err != nil {
+			return nil, err
+		}
+		secret.MultiplyAdd(lambda, share.Share.Value, secret)
+	}
+	if new(ed25519.Point).ScalarBaseMult(secret).Equal(commitments[0]) != 1 {
+		return nil, errors.New("vss: the recovered secret does not match the commitments")
+	}
+	return secret, nil
+}
+
+func equalCommitments(a, b []*ed25519.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Equal(b[i]) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifiableShare is a share together with the Feldman commitments of its polynomial,
+// the form held by a key custodian
+type VerifiableShare struct {
+	Share       *Share
+	Commitments []*ed25519.Point
+}
+
+// Verify verifies the share against the commitments
+func (v *VerifiableShare) Verify() error {
+	return v.Share.Verify(v.Commitments)
+}
+
+// Threshold returns the number of shares needed to recover the secret
+func (v *VerifiableShare) Threshold() int {
+	return len(v.Commitments)
+}
+
+// PublicKey returns the public key of the shared secret, secret * B
+func (v *VerifiableShare) PublicKey() *ed25519.Point {
+	return new(ed25519.Point).Set(v.Commitments[0])
+}
+
+// verifiableShareJSON is the serialized form of a verifiable share
+type verifiableShareJSON struct {
+	Version     int      `json:"version"`
+	Index       uint32   `json:"index"`
+	Value       string   `json:"value"`
+	Commitments []string `json:"commitments"`
+}
+
+// MarshalJSON serializes the share
+func (v *VerifiableShare) MarshalJSON() ([]byte, error) {
+	s := verifiableShareJSON{
+		Version:     shareFormatVersion,
+		Index:       v.Share.Index,
+		Value:       hex.EncodeToString(v.Share.Value.Bytes()),
+		Commitments: make([]string, len(v.Commitments)),
+	}
+	for i, commitment := range v.Commitments {
+		s.Commitments[i] = hex.EncodeToString(commitment.Bytes())
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON deserializes and verifies the share
+func (v *VerifiableShare) UnmarshalJSON(data []byte) error {
+	var s verifiableShareJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s.Version != shareFormatVersion {
+		return fmt.Errorf("vss: unsupported share format version %d", s.Version)
+	}
+	if len(s.Commitments) == 0 {
+		return errors.New("vss: share without commitments")
+	}
+	valueBytes, err := hex.DecodeString(s.Value)
+	if err != nil {
+		return err
+	}
+	value, err := ed25519.NewScalar().SetCanonicalBytes(valueBytes)
+	if err != nil {
+		return err
+	}
+	commitments := make([]*ed25519.Point, len(s.Commitments))
+	for i, c := range s.Commitments {
+		commitmentBytes, err := hex.DecodeString(c)
+		if err != nil {
+			return err
+		}
+		if commitments[i], err = new(ed25519.Point).SetBytes(commitmentBytes); err != nil {
+			return err
+		}
+	}
+	share := &VerifiableShare{
+		Share:       &Share{Index: s.Index, Value: value},
+		Commitments: commitments,
+	}
+	if err = share.Verify(); err != nil {
+		return err
+	}
+	*v = *share
+	return nil
+}
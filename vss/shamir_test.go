@@ -0,0 +1,164 @@
+package vss
+
+import (
+	"encoding/json"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+	autied25519 "github.com/auti-project/auti-core/ed25519"
+)
+
+func TestCombine(t *testing.T) {
+	publicKey, privateKey, err := autied25519.KeyGen()
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	shares, err := Split(privateKey, 3, 5)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if shares[0].PublicKey().Equal(publicKey) != 1 {
+		t.Fatalf("PublicKey() does not match the public key of the secret")
+	}
+	otherSecret, err := RandomScalar()
+	if err != nil {
+		t.Fatalf("RandomScalar() error = %v", err)
+	}
+	others, err := Split(otherSecret, 3, 5)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	tampered := &VerifiableShare{
+		Share:       &Share{Index: shares[1].Share.Index, Value: ed25519.NewScalar().Add(shares[1].Share.Value, shares[1].Share.Value)},
+		Commitments: shares[1].Commitments,
+	}
+	tests := []struct {
+		name    string
+		shares  []*VerifiableShare
+		wantErr bool
+	}{
+		{"Test_Threshold", []*VerifiableShare{shares[0], shares[2], shares[4]}, false},
+		{"Test_All", shares, false},
+		{"Test_Unordered", []*VerifiableShare{shares[3], shares[1], shares[0]}, false},
+		{"Test_No_Shares", nil, true},
+		{"Test_Below_Threshold", shares[:2], true},
+		{"Test_Duplicate", []*VerifiableShare{shares[0], shares[0], shares[1]}, true},
+		{"Test_Tampered", []*VerifiableShare{shares[0], tampered, shares[2]}, true},
+		{"Test_Mixed_Secrets", []*VerifiableShare{shares[0], shares[1], others[2]}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Combine(tt.shares)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Combine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Equal(privateKey) != 1 {
+				t.Errorf("Combine() did not recover the secret")
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	secret, err := RandomScalar()
+	if err != nil {
+		t.Fatalf("RandomScalar() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		threshold int
+		n         int
+		wantErr   bool
+	}{
+		{"Test_1_Of_1", 1, 1, false},
+		{"Test_2_Of_3", 2, 3, false},
+		{"Test_5_Of_5", 5, 5, false},
+		{"Test_Zero_Threshold", 0, 3, true},
+		{"Test_Threshold_Above_N", 4, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := Split(secret, tt.threshold, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Split() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(shares) != tt.n {
+				t.Fatalf("Split() got %d shares, want %d", len(shares), tt.n)
+			}
+			for _, share := range shares {
+				if share.Threshold() != tt.threshold {
+					t.Errorf("Threshold() = %d, want %d", share.Threshold(), tt.threshold)
+				}
+				if err = share.Verify(); err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifiableShare_UnmarshalJSON(t *testing.T) {
+	secret, err := RandomScalar()
+	if err != nil {
+		t.Fatalf("RandomScalar() error = %v", err)
+	}
+	shares, err := Split(secret, 2, 3)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	valid, err := json.Marshal(shares[1])
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	var s verifiableShareJSON
+	if err = json.Unmarshal(valid, &s); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	mutate := func(f func(s *verifiableShareJSON)) []byte {
+		c := s
+		c.Commitments = append([]string{}, s.Commitments...)
+		f(&c)
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return data
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"Test_Valid", valid, false},
+		{"Test_Malformed", []byte("{"), true},
+		{"Test_Unknown_Version", mutate(func(s *verifiableShareJSON) { s.Version = 2 }), true},
+		{"Test_Wrong_Index", mutate(func(s *verifiableShareJSON) { s.Index = 3 }), true},
+		{"Test_No_Commitments", mutate(func(s *verifiableShareJSON) { s.Commitments = nil }), true},
+		{"Test_Invalid_Value_Hex", mutate(func(s *verifiableShareJSON) { s.Value = "zz" }), true},
+		{"Test_Non_Canonical_Value", mutate(func(s *verifiableShareJSON) {
+			s.Value = "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+		}), true},
+		{"Test_Invalid_Commitment", mutate(func(s *verifiableShareJSON) {
+			s.Commitments[1] = "0200000000000000000000000000000000000000000000000000000000000000"
+		}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got VerifiableShare
+			err := json.Unmarshal(tt.data, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Share.Index != shares[1].Share.Index || got.Share.Value.Equal(shares[1].Share.Value) != 1 ||
+				!equalCommitments(got.Commitments, shares[1].Commitments) {
+				t.Errorf("UnmarshalJSON() = %v, want %v", got, shares[1])
+			}
+		})
+	}
+}
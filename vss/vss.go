@@ -0,0 +1,147 @@
+package vss
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+// ErrInvalidShare is returned when a share does not match the commitments of its polynomial
+var ErrInvalidShare = errors.New("vss: invalid share")
+
+// Polynomial is a secret polynomial over the scalar field, its constant term is the secret
+type Polynomial struct {
+	coefficients []*ed25519.Scalar
+}
+
+// NewPolynomial creates a random polynomial of degree threshold - 1 with the secret as the constant term,
+// so that any threshold of its evaluations determine the secret
+func NewPolynomial(secret *ed25519.Scalar, threshold int) (*Polynomial, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("vss: threshold %d is less than 1", threshold)
+	}
+	p := &Polynomial{coefficients: make([]*ed25519.Scalar, threshold)}
+	p.coefficients[0] = ed25519.NewScalar().Set(secret)
+	for i := 1; i < threshold; i++ {
+		coefficient, err := RandomScalar()
+		if err != nil {
+			return nil, err
+		}
+		p.coefficients[i] = coefficient
+	}
+	return p, nil
+}
+
+// Secret returns the constant term of the polynomial
+func (p *Polynomial) Secret() *ed25519.Scalar {
+	return ed25519.NewScalar().Set(p.coefficients[0])
+}
+
+// Evaluate evaluates the polynomial at the index, in constant time
+func (p *Polynomial) Evaluate(index uint32) *ed25519.Scalar {
+	x := ScalarFromIndex(index)
+	// Horner's method
+	result := ed25519.NewScalar()
+	for i := len(p.coefficients) - 1; i >= 0; i-- {
+		result.MultiplyAdd(result, x, p.coefficients[i])
+	}
+	return result
+}
+
+// Share returns the share of the index
+func (p *Polynomial) Share(index uint32) (*Share, error) {
+	if index == 0 {
+		return nil, errors.New("vss: share index 0 is the secret")
+	}
+	return &Share{Index: index, Value: p.Evaluate(index)}, nil
+}
+
+// Commitments returns the Feldman commitments of the coefficients, coefficient * B
+func (p *Polynomial) Commitments() []*ed25519.Point {
+	commitments := make([]*ed25519.Point, len(p.coefficients))
+	for i, coefficient := range p.coefficients {
+		commitments[i] = new(ed25519.Point).ScalarBaseMult(coefficient)
+	}
+	return commitments
+}
+
+// Share is the evaluation of a polynomial at a non-zero index
+type Share struct {
+	Index uint32
+	Value *ed25519.Scalar
+}
+
+// Verify verifies the share against the Feldman commitments of its polynomial
+func (s *Share) Verify(commitments []*ed25519.Point) error {
+	if s.Index == 0 || len(commitments) == 0 {
+		return ErrInvalidShare
+	}
+	expected := EvaluateCommitments(commitments, s.Index)
+	if new(ed25519.Point).ScalarBaseMult(s.Value).Equal(expected) != 1 {
+		return ErrInvalidShare
+	}
+	return nil
+}
+
+// EvaluateCommitments evaluates the polynomial in the exponent at the index,
+// which is the public key of the share of the index
+func EvaluateCommitments(commitments []*ed25519.Point, index uint32) *ed25519.Point {
+	x := ScalarFromIndex(index)
+	power := ed25519.NewScalar().Set(x)
+	scalars := make([]*ed25519.Scalar, len(commitments))
+	scalars[0] = ScalarFromIndex(1)
+	for i := 1; i < len(commitments); i++ {
+		scalars[i] = ed25519.NewScalar().Set(power)
+		power.Multiply(power, x)
+	}
+	// the commitments and the index are public
+	return new(ed25519.Point).VarTimeMultiScalarMult(scalars, commitments)
+}
+
+// LagrangeCoefficient returns the Lagrange coefficient at zero of the index within the indices
+func LagrangeCoefficient(index uint32, indices []uint32) (*ed25519.Scalar, error) {
+	numerator, denominator := ScalarFromIndex(1), ScalarFromIndex(1)
+	found := false
+	seen := make(map[uint32]struct{}, len(indices))
+	for _, j := range indices {
+		if j == 0 {
+			return nil, errors.New("vss: share index 0 is the secret")
+		}
+		if _, ok := seen[j]; ok {
+			return nil, fmt.Errorf("vss: duplicate share index %d", j)
+		}
+		seen[j] = struct{}{}
+		if j == index {
+			found = true
+			continue
+		}
+		xj := ScalarFromIndex(j)
+		numerator.Multiply(numerator, xj)
+		denominator.Multiply(denominator, ed25519.NewScalar().Subtract(xj, ScalarFromIndex(index)))
+	}
+	if !found {
+		return nil, fmt.Errorf("vss: share index %d is not in the indices", index)
+	}
+	return numerator.Multiply(numerator, ed25519.NewScalar().Invert(denominator)), nil
+}
+
+// ScalarFromIndex returns the scalar of a share index
+func ScalarFromIndex(index uint32) *ed25519.Scalar {
+	buf := make([]byte, 32)
+	binary.LittleEndian.PutUint32(buf, index)
+	// an index is always canonical
+	scalar, _ := ed25519.NewScalar().SetCanonicalBytes(buf)
+	return scalar
+}
+
+// RandomScalar returns a uniformly random scalar
+func RandomScalar() (*ed25519.Scalar, error) {
+	randBytes := make([]byte, 64)
+	if _, err := rand.Read(randBytes); err != nil {
+		return nil, err
+	}
+	return ed25519.NewScalar().SetUniformBytes(randBytes)
+}
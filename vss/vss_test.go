@@ -0,0 +1,100 @@
+package vss
+
+import (
+	"errors"
+	"testing"
+
+	ed25519 "filippo.io/edwards25519"
+)
+
+func TestShare_Verify(t *testing.T) {
+	secret, err := RandomScalar()
+	if err != nil {
+		t.Fatalf("RandomScalar() error = %v", err)
+	}
+	p, err := NewPolynomial(secret, 3)
+	if err != nil {
+		t.Fatalf("NewPolynomial() error = %v", err)
+	}
+	commitments := p.Commitments()
+	valid, err := p.Share(2)
+	if err != nil {
+		t.Fatalf("Share() error = %v", err)
+	}
+	other, err := NewPolynomial(secret, 3)
+	if err != nil {
+		t.Fatalf("NewPolynomial() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		share   *Share
+		wantErr error
+	}{
+		{
+			name:  "Test_Valid_Share",
+			share: valid,
+		},
+		{
+			name:    "Test_Wrong_Index",
+			share:   &Share{Index: 3, Value: valid.Value},
+			wantErr: ErrInvalidShare,
+		},
+		{
+			name:    "Test_Other_Polynomial",
+			share:   &Share{Index: 2, Value: other.Evaluate(2)},
+			wantErr: ErrInvalidShare,
+		},
+		{
+			name:    "Test_Index_Zero",
+			share:   &Share{Index: 0, Value: secret},
+			wantErr: ErrInvalidShare,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.share.Verify(commitments); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLagrangeCoefficient(t *testing.T) {
+	secret, err := RandomScalar()
+	if err != nil {
+		t.Fatalf("RandomScalar() error = %v", err)
+	}
+	p, err := NewPolynomial(secret, 3)
+	if err != nil {
+		t.Fatalf("NewPolynomial() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		indices []uint32
+		want    bool
+		wantErr bool
+	}{
+		{name: "Test_Threshold_Shares", indices: []uint32{1, 2, 3}, want: true},
+		{name: "Test_More_Shares", indices: []uint32{2, 5, 7, 9}, want: true},
+		{name: "Test_Too_Few_Shares", indices: []uint32{4, 6}, want: false},
+		{name: "Test_Duplicate_Index", indices: []uint32{1, 2, 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ed25519.NewScalar()
+			for _, index := range tt.indices {
+				lambda, err := LagrangeCoefficient(index, tt.indices)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("LagrangeCoefficient() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				got.MultiplyAdd(lambda, p.Evaluate(index), got)
+			}
+			if (got.Equal(secret) == 1) != tt.want {
+				t.Errorf("interpolated secret matches = %v, want %v", !tt.want, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package wire
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Type is the wire type of a field, as in Protocol Buffers
+type Type uint8
+
+const (
+	// TypeVarint is the wire type of integers
+	TypeVarint Type = 0
+	// TypeBytes is the wire type of length-delimited byte strings
+	TypeBytes Type = 2
+)
+
+var (
+	// ErrMalformed is returned when decoding data that is not a valid encoding
+	ErrMalformed = errors.New("wire: malformed data")
+	// ErrUnknownField is returned when decoding a field that the record does not have
+	ErrUnknownField = errors.New("wire: unknown field")
+	// ErrNonCanonical is returned when encoding a hex or decimal string field that would not decode to itself,
+	// such as upper-case hex, since the key of a record is derived from its strings
+	ErrNonCanonical = errors.New("wire: non-canonical field")
+)
+
+// appendTag appends the tag of the field number and the wire type
+func appendTag(buf []byte, number int, t Type) []byte {
+	return binary.AppendUvarint(buf, uint64(number)<<3|uint64(t))
+}
+
+// AppendBytes appends a byte string field, omitted when empty
+func AppendBytes(buf []byte, number int, b []byte) []byte {
+	if len(b) == 0 {
+		return buf
+	}
+	buf = appendTag(buf, number, TypeBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// AppendString appends a string field, omitted when empty
+func AppendString(buf []byte, number int, s string) []byte {
+	return AppendBytes(buf, number, []byte(s))
+}
+
+// AppendHex appends a hex string field as its bytes, omitted when empty.
+// The string must be lower-case hex, the encoding of hex.EncodeToString.
+// It decodes the string into buf, without allocating other than to grow buf.
+func AppendHex(buf []byte, number int, s string) ([]byte, error) {
+	if len(s) == 0 {
+		return buf, nil
+	}
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("%w: field %d has odd length hex", ErrNonCanonical, number)
+	}
+	buf = appendTag(buf, number, TypeBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(s)/2))
+	n := len(buf)
+	buf = append(buf, make([]byte, len(s)/2)...)
+	out := buf[n:]
+	for i := range out {
+		hi, lo := lowerHexDigits[s[2*i]], lowerHexDigits[s[2*i+1]]
+		if hi|lo > 0xf {
+			return nil, fmt.Errorf("%w: field %d is not lower-case hex", ErrNonCanonical, number)
+		}
+		out[i] = hi<<4 | lo
+	}
+	return buf, nil
+}
+
+// lowerHexDigits is the value of every lower-case hex digit, and 0xff of every other byte
+var lowerHexDigits = func() (digits [256]byte) {
+	for i := range digits {
+		digits[i] = 0xff
+	}
+	for i, c := range "0123456789abcdef" {
+		digits[c] = byte(i)
+	}
+	return
+}()
+
+// AppendInt appends a signed integer field in zig-zag encoding, the sint64 of Protocol Buffers, omitted when zero
+func AppendInt(buf []byte, number int, v int64) []byte {
+	if v == 0 {
+		return buf
+	}
+	buf = appendTag(buf, number, TypeVarint)
+	return binary.AppendUvarint(buf, uint64(v<<1)^uint64(v>>63))
+}
+
+// Field is a field of a record being decoded
+type Field struct {
+	Number int
+	Type   Type
+	varint uint64
+	bytes  []byte
+}
+
+// Int returns the signed integer of a field of AppendInt
+func (f *Field) Int() (int64, error) {
+	if f.Type != TypeVarint {
+		return 0, fmt.Errorf("%w: field %d is not an integer", ErrMalformed, f.Number)
+	}
+	return int64(f.varint>>1) ^ -int64(f.varint&1), nil
+}
+
+// Bytes returns a copy of the byte string of a field of AppendBytes
+func (f *Field) Bytes() ([]byte, error) {
+	if f.Type != TypeBytes {
+		return nil, fmt.Errorf("%w: field %d is not a byte string", ErrMalformed, f.Number)
+	}
+	return append([]byte{}, f.bytes...), nil
+}
+
+// Text returns the string of a field of AppendString
+func (f *Field) Text() (string, error) {
+	if f.Type != TypeBytes {
+		return "", fmt.Errorf("%w: field %d is not a string", ErrMalformed, f.Number)
+	}
+	return string(f.bytes), nil
+}
+
+// Hex returns the hex string of a field of AppendHex
+func (f *Field) Hex() (string, error) {
+	if f.Type != TypeBytes {
+		return "", fmt.Errorf("%w: field %d is not a byte string", ErrMalformed, f.Number)
+	}
+	return hex.EncodeToString(f.bytes), nil
+}
+
+// Unknown returns the error of a field that the record does not have
+func (f *Field) Unknown() error {
+	return fmt.Errorf("%w %d", ErrUnknownField, f.Number)
+}
+
+// Decoder decodes the fields of a record, which are in increasing order of their numbers and never empty
+type Decoder struct {
+	data []byte
+	last int
+}
+
+// NewDecoder creates a new decoder of the encoded record
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// Next returns the next field, or io.EOF after the last field
+func (d *Decoder) Next() (*Field, error) {
+	if len(d.data) == 0 {
+		return nil, io.EOF
+	}
+	tag, n := uvarint(d.data)
+	if n <= 0 || tag>>3 == 0 || tag>>3 > 1<<29 {
+		return nil, fmt.Errorf("%w: invalid tag", ErrMalformed)
+	}
+	d.data = d.data[n:]
+	f := &Field{Number: int(tag >> 3), Type: Type(tag & 7)}
+	// each field is encoded once, in order, so that every record has a single encoding
+	if f.Number <= d.last {
+		return nil, fmt.Errorf("%w: field %d after field %d", ErrMalformed, f.Number, d.last)
+	}
+	d.last = f.Number
+	value, n := uvarint(d.data)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: field %d: invalid varint", ErrMalformed, f.Number)
+	}
+	d.data = d.data[n:]
+	// zero integers and empty byte strings are omitted
+	if value == 0 {
+		return nil, fmt.Errorf("%w: field %d is empty", ErrMalformed, f.Number)
+	}
+	switch f.Type {
+	case TypeVarint:
+		f.varint = value
+	case TypeBytes:
+		if value > uint64(len(d.data)) {
+			return nil, fmt.Errorf("%w: field %d: %d bytes past the end", ErrMalformed, f.Number, value)
+		}
+		f.bytes, d.data = d.data[:value], d.data[value:]
+	default:
+		return nil, fmt.Errorf("%w: field %d: unsupported wire type %d", ErrMalformed, f.Number, f.Type)
+	}
+	return f, nil
+}
+
+// uvarint decodes a varint as binary.Uvarint, rejecting with n = 0 the varints longer than the encoding
+// of their value, such as 0x88 0x00 for 0x08, so that every record has a single encoding
+func uvarint(data []byte) (uint64, int) {
+	v, n := binary.Uvarint(data)
+	var minimal [binary.MaxVarintLen64]byte
+	if n > 0 && n != binary.PutUvarint(minimal[:], v) {
+		return 0, 0
+	}
+	return v, n
+}
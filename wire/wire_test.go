@@ -0,0 +1,143 @@
+package wire
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestAppendInt(t *testing.T) {
+	tests := []struct {
+		name  string
+		value int64
+		want  []byte
+	}{
+		{"Test_Zero", 0, nil},
+		{"Test_One", 1, []byte{0x08, 0x02}},
+		{"Test_Minus_One", -1, []byte{0x08, 0x01}},
+		{"Test_Max", math.MaxInt64, []byte{0x08, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"Test_Min", math.MinInt64, []byte{0x08, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AppendInt(nil, 1, tt.value)
+			if string(got) != string(tt.want) {
+				t.Fatalf("AppendInt() = %x, want %x", got, tt.want)
+			}
+			if tt.value == 0 {
+				return
+			}
+			f, err := NewDecoder(got).Next()
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			if v, err := f.Int(); err != nil || v != tt.value {
+				t.Errorf("Int() = %d, %v, want %d", v, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestAppendHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []byte
+		wantErr error
+	}{
+		{"Test_Empty", "", nil, nil},
+		{"Test_Lower_Case", "00ff", []byte{0x12, 0x02, 0x00, 0xff}, nil},
+		{"Test_Upper_Case", "00FF", nil, ErrNonCanonical},
+		{"Test_Odd_Length", "0", nil, ErrNonCanonical},
+		{"Test_Not_Hex", "zz", nil, ErrNonCanonical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AppendHex(nil, 2, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AppendHex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != string(tt.want) {
+				t.Errorf("AppendHex() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+	// the string is decoded in place
+	buf := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(100, func() { buf, _ = AppendHex(buf[:0], 2, "00ff") }); allocs != 0 {
+		t.Errorf("AppendHex() allocations = %v, want 0", allocs)
+	}
+}
+
+func TestDecoder_Next(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr error
+	}{
+		{"Test_Empty_Record", nil, 0, nil},
+		{"Test_Fields", []byte{0x08, 0x02, 0x12, 0x01, 0xaa, 0x1a, 0x01, 0xbb}, 3, nil},
+		{"Test_Field_Zero", []byte{0x00, 0x02}, 0, ErrMalformed},
+		{"Test_Out_Of_Order", []byte{0x12, 0x01, 0xaa, 0x08, 0x02}, 1, ErrMalformed},
+		{"Test_Repeated", []byte{0x08, 0x02, 0x08, 0x04}, 1, ErrMalformed},
+		{"Test_Empty_Integer", []byte{0x08, 0x00}, 0, ErrMalformed},
+		{"Test_Empty_Bytes", []byte{0x12, 0x00}, 0, ErrMalformed},
+		{"Test_Truncated_Varint", []byte{0x08, 0x80}, 0, ErrMalformed},
+		{"Test_Overlong_Tag", []byte{0x88, 0x00, 0x02}, 0, ErrMalformed},
+		{"Test_Overlong_Integer", []byte{0x08, 0x82, 0x00}, 0, ErrMalformed},
+		{"Test_Overlong_Length", []byte{0x12, 0x81, 0x00, 0xaa}, 0, ErrMalformed},
+		{"Test_Overlong_Later_Field", []byte{0x08, 0x02, 0x92, 0x00, 0x01, 0xaa}, 1, ErrMalformed},
+		{"Test_Truncated_Bytes", []byte{0x12, 0x02, 0xaa}, 0, ErrMalformed},
+		{"Test_Fixed64", []byte{0x09, 0x01, 0, 0, 0, 0, 0, 0, 0}, 0, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(tt.data)
+			got := 0
+			for {
+				_, err := d.Next()
+				if errors.Is(err, io.EOF) {
+					if tt.wantErr != nil {
+						t.Fatalf("Next() error = nil, wantErr %v", tt.wantErr)
+					}
+					break
+				}
+				if err != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Next() error = %v, wantErr %v", err, tt.wantErr)
+					}
+					break
+				}
+				got++
+			}
+			if got != tt.want {
+				t.Errorf("Next() decoded %d fields, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestField_Type(t *testing.T) {
+	f, err := NewDecoder([]byte{0x08, 0x02}).Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if _, err = f.Bytes(); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Bytes() of an integer error = %v, wantErr %v", err, ErrMalformed)
+	}
+	if err = f.Unknown(); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Unknown() error = %v, wantErr %v", err, ErrUnknownField)
+	}
+	f, err = NewDecoder([]byte{0x12, 0x01, 0x61}).Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if _, err = f.Int(); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Int() of bytes error = %v, wantErr %v", err, ErrMalformed)
+	}
+	if s, err := f.Text(); err != nil || s != "a" {
+		t.Errorf("Text() = %q, %v, want %q", s, err, "a")
+	}
+}